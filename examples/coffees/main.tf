@@ -7,7 +7,8 @@ terraform {
 }
 
 provider "provs" {
-  path = "/var/tmp/custom_tf_provider"
+  path         = "/var/tmp/custom_tf_provider"
+  seed_catalog = true
 }
 
 data "provs_coffees" "test_coffees" {}
@@ -16,3 +17,23 @@ output "coffees" {
   value = data.provs_coffees.test_coffees
 }
 
+
+data "provs_coffees" "with_ingredient_8" {
+  filter {
+    ingredient_id = "8"
+  }
+  sort_by = "name"
+  limit   = 1
+}
+
+data "provs_coffee" "first" {
+  name = "Name 1"
+}
+
+output "coffee_with_ingredient_8" {
+  value = data.provs_coffees.with_ingredient_8.coffees
+}
+
+output "first_coffee" {
+  value = data.provs_coffee.first
+}
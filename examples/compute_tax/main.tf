@@ -15,3 +15,27 @@ output "total_price" {
   value = provider::provs::compute_tax(5.00, 0.085)
 }
 
+
+output "discounted_price" {
+  value = provider::provs::apply_discount(5.00, "percentage", 10)
+}
+
+output "order_total" {
+  value = provider::provs::order_total(
+    [{ coffee_id = "1", quantity = 2 }, { coffee_id = "2", quantity = 1 }],
+    { "1" = 4.00, "2" = 2.50 },
+    0.085,
+  )
+}
+
+output "price_in_yen" {
+  value = provider::provs::convert_currency(5.00, "EUR", "JPY", { EUR = 0.92, JPY = 151.2 })
+}
+
+output "rounded_price" {
+  value = provider::provs::round_money(5.4251, "KWD")
+}
+
+output "shares" {
+  value = provider::provs::split_bill(10.00, 3)
+}
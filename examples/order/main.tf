@@ -8,7 +8,8 @@ terraform {
 }
 
 provider "provs" {
-  path = "/var/tmp/custom_tf_provider"
+  path         = "/var/tmp/custom_tf_provider"
+  seed_catalog = true
 }
 
 resource "provs_order" "new_order" {
@@ -7,7 +7,8 @@ terraform {
 }
 
 provider "provs" {
-  path = "/var/tmp/custom_tf_provider"
+  path         = "/var/tmp/custom_tf_provider"
+  seed_catalog = true
 }
 
 data "provs_coffees" "example" {}
@@ -0,0 +1,244 @@
+// Package audit keeps an append-only log of the accesses to secrets.
+// The entries are hash-chained so that changing, removing or reordering any of them is detected by Verify.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+)
+
+const (
+	// resTypeEntries and resTypeHead are the storage types of the audit log
+	resTypeEntries = "audit_log"
+	resTypeHead    = "audit_log_head"
+
+	// headID is the head updated to point to the newest entry. Written after the entries, it may lag behind them: it
+	// only saves following the chain from its start. The heads appended with the entries are kept under their IDs.
+	headID = "head"
+
+	// maxAppendAttempts bounds the attempts of an append losing the races with the appends of other processes
+	maxAppendAttempts = 10
+)
+
+// ErrNoLog is returned by the appends to a nil *Log, so that the accesses to a store without audit log fail instead
+// of going unrecorded.
+var ErrNoLog = errors.New("the store has no audit log")
+
+// Log appends entries to the audit log of a store.
+// Every entry and its head are created exclusively under the ID of their position in the chain, so that the appends
+// of concurrent processes never fork the chain: the append losing the race for a position follows the chain to its
+// new end and tries again.
+// A nil *Log refuses the appends with ErrNoLog.
+type Log struct {
+	mu      sync.Mutex
+	entries client.Client[*model.AuditEntry]
+	heads   client.Client[*model.AuditHead]
+	now     func() time.Time
+}
+
+func NewLog(backend client.BackendClient, now func() time.Time) *Log {
+	return &Log{
+		entries: client.NewClient[*model.AuditEntry](backend, resTypeEntries),
+		heads:   client.NewClient[*model.AuditHead](backend, resTypeHead),
+		now:     now,
+	}
+}
+
+// Record appends an entry for the given access to the secret, performed successfully.
+func (l *Log) Record(principal string, secretManagerID string, secretName string, op model.AuditOperation) error {
+	return l.append(principal, secretManagerID, secretName, op, "")
+}
+
+// RecordDenial appends an entry for the given access to the secret, refused by the policy of the secret manager.
+func (l *Log) RecordDenial(principal string, secretManagerID string, secretName string, op model.AuditOperation) error {
+	return l.append(principal, secretManagerID, secretName, op, model.AuditOutcomeDenied)
+}
+
+// RecordFailure appends an entry for the given access to the secret, allowed by the policy but failed.
+func (l *Log) RecordFailure(principal string, secretManagerID string, secretName string, op model.AuditOperation) error {
+	return l.append(principal, secretManagerID, secretName, op, model.AuditOutcomeFailed)
+}
+
+func (l *Log) append(principal string, secretManagerID string, secretName string, op model.AuditOperation, outcome model.AuditOutcome) error {
+	if l == nil {
+		return ErrNoLog
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		last, err := l.last()
+		if err != nil {
+			return err
+		}
+		entry := &model.AuditEntry{
+			Seq:             last.Seq + 1,
+			Timestamp:       l.now().UTC(),
+			Principal:       principal,
+			SecretManagerID: secretManagerID,
+			SecretName:      secretName,
+			Operation:       op,
+			Outcome:         outcome,
+			PrevHash:        last.Hash,
+		}
+		entry.ID = entryID(entry.Seq)
+		if entry.Hash, err = Hash(entry); err != nil {
+			return err
+		}
+		// the exclusive creation never overwrites an entry, the position being taken by another append otherwise
+		_, err = l.entries.Create(entry)
+		if client.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not append the audit log entry %d: %w", entry.Seq, err)
+		}
+
+		head := &model.AuditHead{ID: entry.ID, Seq: entry.Seq, Hash: entry.Hash}
+		if _, err := l.heads.Create(head); err != nil {
+			return fmt.Errorf("could not append the head of the audit log: %w", err)
+		}
+		// the head of headID only saves following the chain: when it cannot be written, the next appends catch up
+		head.ID = headID
+		if err := l.heads.Update(head); client.IsNotFound(err) {
+			_, _ = l.heads.Create(head)
+		}
+		return nil
+	}
+	return fmt.Errorf("could not append the audit log entry: the log kept being appended to by other processes")
+}
+
+// last returns the head of the newest entry of the chain, with a zero Seq when the log is empty. It follows the
+// entries appended after the one of headID.
+func (l *Log) last() (*model.AuditHead, error) {
+	last, err := l.heads.GetByID(headID)
+	if client.IsNotFound(err) {
+		last, err = &model.AuditHead{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the head of the audit log: %w", err)
+	}
+	for {
+		next, err := l.entries.GetByID(entryID(last.Seq + 1))
+		if client.IsNotFound(err) {
+			return last, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read the audit log entry %d: %w", last.Seq+1, err)
+		}
+		last = &model.AuditHead{Seq: next.Seq, Hash: next.Hash}
+	}
+}
+
+// Filter selects the entries returned by Recent. The empty fields match everything.
+type Filter struct {
+	SecretManagerID string
+	SecretName      string
+	Principal       string
+}
+
+func (f Filter) matches(e *model.AuditEntry) bool {
+	return (f.SecretManagerID == "" || f.SecretManagerID == e.SecretManagerID) &&
+		(f.SecretName == "" || f.SecretName == e.SecretName) &&
+		(f.Principal == "" || f.Principal == e.Principal)
+}
+
+// Recent returns at most limit entries matching the filter, from the newest to the oldest.
+func Recent(backend client.BackendClient, filter Filter, limit int) ([]*model.AuditEntry, error) {
+	all, err := readAll(backend)
+	if err != nil {
+		return nil, err
+	}
+	res := []*model.AuditEntry{}
+	for i := len(all) - 1; i >= 0 && len(res) < limit; i-- {
+		if filter.matches(all[i]) {
+			res = append(res, all[i])
+		}
+	}
+	return res, nil
+}
+
+// Verify checks the chain of the audit log and returns the number of entries verified.
+// The returned error describes the first inconsistency found.
+func Verify(backend client.BackendClient) (int, error) {
+	all, err := readAll(backend)
+	if err != nil {
+		return 0, err
+	}
+	prevHash := ""
+	for i, e := range all {
+		if e.Seq != int64(i+1) {
+			return i, fmt.Errorf("expected the entry %d, found the entry %d: entries are missing", i+1, e.Seq)
+		}
+		if e.PrevHash != prevHash {
+			return i, fmt.Errorf("the entry %d does not point to the hash of the entry before it", e.Seq)
+		}
+		hash, err := Hash(e)
+		if err != nil {
+			return i, err
+		}
+		if hash != e.Hash {
+			return i, fmt.Errorf("the hash of the entry %d does not match its content", e.Seq)
+		}
+		prevHash = e.Hash
+	}
+
+	// the newest head, the one of headID for the logs appended before the heads were
+	heads, err := client.NewClient[*model.AuditHead](backend, resTypeHead).GetAll()
+	if client.IsNotFound(err) {
+		if len(all) > 0 {
+			return len(all), fmt.Errorf("the head of the audit log is missing")
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return len(all), fmt.Errorf("could not read the head of the audit log: %w", err)
+	}
+	head := heads[0]
+	for _, h := range heads[1:] {
+		if h.Seq > head.Seq {
+			head = h
+		}
+	}
+	if head.Seq != int64(len(all)) || head.Hash != prevHash {
+		return len(all), fmt.Errorf("the head of the audit log points to the entry %d but the last entry is %d: entries are missing", head.Seq, len(all))
+	}
+	return len(all), nil
+}
+
+// Hash returns the hash of the entry, computed over all of its fields but the hash itself.
+func Hash(e *model.AuditEntry) (string, error) {
+	c := *e
+	c.Hash = ""
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("could not hash the audit log entry %d: %w", e.Seq, err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// readAll returns all the entries ordered by their sequence number
+func readAll(backend client.BackendClient) ([]*model.AuditEntry, error) {
+	all, err := client.NewClient[*model.AuditEntry](backend, resTypeEntries).GetAll()
+	if client.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the audit log: %w", err)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Seq < all[j].Seq })
+	return all, nil
+}
+
+func entryID(seq int64) string {
+	return fmt.Sprintf("%020d", seq)
+}
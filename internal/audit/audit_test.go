@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	for name, tc := range map[string]struct {
+		tamper  func(t *testing.T, backend client.BackendClient)
+		wantErr string
+	}{
+		"valid": {
+			tamper: func(*testing.T, client.BackendClient) {},
+		},
+		"changed entry": {
+			tamper: func(t *testing.T, backend client.BackendClient) {
+				entries := client.NewClient[*model.AuditEntry](backend, resTypeEntries)
+				e, err := entries.GetByID(entryID(2))
+				if err != nil {
+					t.Fatal(err)
+				}
+				e.Principal = "someone-else"
+				if err := entries.Update(e); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "the hash of the entry 2 does not match its content",
+		},
+		"removed entry": {
+			tamper: func(t *testing.T, backend client.BackendClient) {
+				if err := backend.Destroy(resTypeEntries, entryID(2)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "expected the entry 2, found the entry 3",
+		},
+		"removed last entry": {
+			tamper: func(t *testing.T, backend client.BackendClient) {
+				if err := backend.Destroy(resTypeEntries, entryID(3)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "the head of the audit log points to the entry 3 but the last entry is 2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend, err := filesystem.NewFsClient(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			log := NewLog(backend, func() time.Time { return now })
+			for _, op := range []model.AuditOperation{model.AuditOperationCreate, model.AuditOperationRead, model.AuditOperationDelete} {
+				if err := log.Record("ci", "mgr", "db_password", op); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.tamper(t, backend)
+			n, err := Verify(backend)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if n != 3 {
+					t.Fatalf("expected 3 entries verified, got %d", n)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLog_concurrent(t *testing.T) {
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the logs of different processes do not share their lock
+	errs := make(chan error)
+	for range 4 {
+		go func() {
+			log := NewLog(backend, time.Now)
+			for range 5 {
+				if err := log.Record("ci", "mgr", "db_password", model.AuditOperationRead); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range 4 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := Verify(backend); err != nil || n != 20 {
+		t.Fatalf("expected 20 entries verified, got %d (%v)", n, err)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewLog(backend, time.Now)
+	for _, name := range []string{"a", "b", "a", "a"} {
+		if err := log.Record("ci", "mgr", name, model.AuditOperationRead); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Recent(backend, Filter{SecretName: "a"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Seq != 4 || got[1].Seq != 3 {
+		t.Fatalf("expected the entries 4 and 3, got %+v", got)
+	}
+}
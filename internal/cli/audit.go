@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"terraform-provider-provs/internal/audit"
+)
+
+func runAudit(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "verify" {
+		_, _ = fmt.Fprintln(stderr, "usage: audit verify [-path <store> | -backend http -url <url>] [-encryption-key-file <file>]")
+		return 1
+	}
+	return runAuditVerify(args[1:], stdout, stderr)
+}
+
+// runAuditVerify checks the hash chain of the audit log and exits with 1 when it is broken.
+func runAuditVerify(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	store := addStoreFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	backend, err := store.open()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	n, err := audit.Verify(backend)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "the audit log is not valid after %d entries: %s\n", n, err)
+		return 1
+	}
+	_, _ = fmt.Fprintf(stdout, "the audit log is valid: %d entries verified\n", n)
+	return 0
+}
@@ -0,0 +1,62 @@
+// Package cli contains the commands that can be run with the provider binary, next to serving the provider.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// command is a subcommand of the provider binary.
+type command struct {
+	synopsis string
+	run      func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"audit": {
+		synopsis: "Inspect the audit log of the secret accesses. Subcommands: verify",
+		run:      runAudit,
+	},
+	"generate-imports": {
+		synopsis: "Generate import blocks and resource stubs for the objects existing in a store",
+		run:      runGenerateImports,
+	},
+	"serve-store": {
+		synopsis: "Serve a local store over HTTP, to share it as an http store of the provider",
+		run:      runServeStore,
+	},
+}
+
+// IsCommand reports whether the given argument names one of the commands of the binary.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run executes the command named by the first argument and returns the exit code of it.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 1
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		usage(stderr)
+		return 1
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Available commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "  %-20s %s\n", name, commands[name].synopsis)
+	}
+}
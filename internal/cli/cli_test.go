@@ -0,0 +1,171 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-provs/internal/cli"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestRun_generateImports(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := filesystem.NewFsClient(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCreateOrder(t, backend)
+	key := bytes.Repeat([]byte{7}, client.EncryptionKeySize)
+	encryptedDir := t.TempDir()
+	encrypted, err := filesystem.NewFsClient(encryptedDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedBackend, err := client.NewEncryptedClient(encrypted, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCreateOrder(t, encryptedBackend)
+	keyFile := filepath.Join(t.TempDir(), "store.key")
+	if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(remote.NewHandler(backend, "t0ken"))
+	defer server.Close()
+
+	for name, tc := range map[string]struct {
+		args []string
+		env  map[string]string
+		// want is in the output on success, in the errors otherwise
+		want string
+		code int
+	}{
+		"fs store": {
+			args: []string{"-path", dir},
+			want: `id = "o1"`,
+		},
+		"fs store of the environment": {
+			env:  map[string]string{"PROVS_PATH": dir},
+			want: `id = "o1"`,
+		},
+		"named store": {
+			args: []string{"-path", dir, "-store", "local"},
+			want: `id = "local/o1"`,
+		},
+		"http store": {
+			args: []string{"-backend", "http", "-url", server.URL},
+			env:  map[string]string{"PROVS_TOKEN": "t0ken"},
+			want: `id = "o1"`,
+		},
+		"encrypted store": {
+			args: []string{"-path", encryptedDir, "-encryption-key-file", keyFile},
+			want: `id = "o1"`,
+		},
+		"encrypted store without key": {
+			args: []string{"-path", encryptedDir},
+			want: `failed to generate the import blocks for "order"`,
+			code: 1,
+		},
+		"http store without token": {
+			args: []string{"-backend", "http", "-url", server.URL},
+			want: "PROVS_TOKEN",
+			code: 1,
+		},
+		"http store with another token": {
+			args: []string{"-backend", "http", "-url", server.URL},
+			env:  map[string]string{"PROVS_TOKEN": "other"},
+			want: "401 Unauthorized",
+			code: 1,
+		},
+		"missing path": {
+			want: "the path of the store is missing",
+			code: 1,
+		},
+		"unknown backend": {
+			args: []string{"-backend", "s3", "-path", dir},
+			want: `the backend must be fs or http, got "s3"`,
+			code: 1,
+		},
+		"unsupported type": {
+			args: []string{"-path", dir, "-type", "order,issue2372"},
+			want: `unsupported kind "issue2372"`,
+			code: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testResetEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			out := filepath.Join(t.TempDir(), "imports.tf")
+			var stdout, stderr bytes.Buffer
+			code := cli.Run(append([]string{"generate-imports", "-out", out}, tc.args...), &stdout, &stderr)
+			if code != tc.code {
+				t.Fatalf("expected the exit code %d, got %d: %s", tc.code, code, stderr.String())
+			}
+			if tc.code != 0 {
+				if !strings.Contains(stderr.String(), tc.want) {
+					t.Fatalf("expected the error %q, got %q", tc.want, stderr.String())
+				}
+				// a failure leaves no partial output
+				if _, err := os.Stat(out); !os.IsNotExist(err) {
+					t.Fatalf("expected no output file, got %v", err)
+				}
+				return
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(got), tc.want) {
+				t.Fatalf("expected %q in the output, got:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRun_auditVerify(t *testing.T) {
+	testResetEnv(t)
+	var stdout, stderr bytes.Buffer
+	if code := cli.Run([]string{"audit", "verify", "-path", t.TempDir()}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected the empty audit log valid, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "the audit log is valid: 0 entries verified\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestRun_unknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := cli.Run([]string{"generate"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected the exit code 1, got %d", code)
+	}
+	if got := stderr.String(); !strings.Contains(got, `unknown command "generate"`) || !strings.Contains(got, "generate-imports") {
+		t.Fatalf("expected the commands listed, got %q", got)
+	}
+}
+
+// testResetEnv unsets the environment variables selecting the store for the test
+func testResetEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"PROVS_BACKEND", "PROVS_PATH", "PROVS_URL", "PROVS_TOKEN", "PROVS_ENCRYPTION_KEY_FILE"} {
+		t.Setenv(env, "")
+	}
+}
+
+func testCreateOrder(t *testing.T, backend client.BackendClient) {
+	t.Helper()
+	if _, err := client.NewClient[*model.Order](backend, "order").Create(&model.Order{
+		ID:    "o1",
+		Items: []model.OrderItem{{Coffee: model.Coffee{ID: "1"}, Quantity: 1}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"terraform-provider-provs/internal/importgen"
+)
+
+// runGenerateImports writes the import blocks and the resource stubs of the objects of a store.
+func runGenerateImports(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("generate-imports", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	store := addStoreFlags(flags)
+	storeName := flags.String("store", "", "the name of the store in the stores of the provider. Defaults to the default store")
+	kinds := flags.String("type", strings.Join(importgen.Kinds(), ","), "comma separated list of the object types to generate the import blocks for")
+	out := flags.String("out", "", "the file to write the generated configuration into. Defaults to stdout")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	backend, err := store.open()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	// generated in full first, so that a failure leaves no partial output file
+	var buf bytes.Buffer
+	if err := importgen.NewGenerator(backend, *storeName).Generate(&buf, strings.Split(*kinds, ",")); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *out == "" {
+		_, _ = stdout.Write(buf.Bytes())
+		return 0
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to write the output file: %s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+)
+
+// runServeStore serves a local store over HTTP, for the providers configured with an http store.
+// The clients authenticate with the token of the PROVS_TOKEN environment variable, kept out of the flags so that it
+// does not show in the process list. The store has no other access control: it is not meant to be exposed beyond the
+// network of the Terraform runs, and is served over https with -tls-cert and -tls-key when reached through a network.
+func runServeStore(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve-store", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	storagePath := flags.String("path", os.Getenv("PROVS_PATH"), "the path of the store to serve. Defaults to PROVS_PATH")
+	addr := flags.String("addr", "localhost:8080", "the address to listen on")
+	tlsCert := flags.String("tls-cert", "", "the certificate file to serve https with, together with -tls-key")
+	tlsKey := flags.String("tls-key", "", "the private key file of -tls-cert")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	if *storagePath == "" {
+		_, _ = fmt.Fprintln(stderr, "the path of the store is missing. Set it with -path or the PROVS_PATH environment variable")
+		return 1
+	}
+	token := os.Getenv("PROVS_TOKEN")
+	if token == "" {
+		_, _ = fmt.Fprintln(stderr, "the token of the store is missing. Set it with the PROVS_TOKEN environment variable")
+		return 1
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		_, _ = fmt.Fprintln(stderr, "-tls-cert and -tls-key must be set together")
+		return 1
+	}
+
+	backend, err := filesystem.NewFsClient(*storagePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to create the storage client: %s\n", err)
+		return 1
+	}
+
+	handler := remote.NewHandler(backend, token)
+	if *tlsCert != "" {
+		_, _ = fmt.Fprintf(stdout, "serving the store %s on https://%s\n", *storagePath, *addr)
+		err = http.ListenAndServeTLS(*addr, *tlsCert, *tlsKey, handler)
+	} else {
+		_, _ = fmt.Fprintf(stdout, "serving the store %s on http://%s\n", *storagePath, *addr)
+		err = http.ListenAndServe(*addr, handler)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed to serve the store: %s\n", err)
+		return 1
+	}
+	return 0
+}
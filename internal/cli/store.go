@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+)
+
+// storeFlags select the store read by a command, as the default store of the provider is configured: a fs store by
+// its path or an http store by its url, optionally encrypted. The flags default to the environment variables of the
+// provider, and the token of an http store is read from PROVS_TOKEN only, so that it does not show in the process list.
+type storeFlags struct {
+	backend           *string
+	path              *string
+	url               *string
+	encryptionKeyFile *string
+}
+
+// addStoreFlags adds the flags selecting the store to flags.
+func addStoreFlags(flags *flag.FlagSet) *storeFlags {
+	return &storeFlags{
+		backend:           flags.String("backend", os.Getenv("PROVS_BACKEND"), "the backend of the store, fs or http. Defaults to PROVS_BACKEND, or fs"),
+		path:              flags.String("path", os.Getenv("PROVS_PATH"), "the path of a fs store. Defaults to PROVS_PATH"),
+		url:               flags.String("url", os.Getenv("PROVS_URL"), "the url of an http store, authenticated with PROVS_TOKEN. Defaults to PROVS_URL"),
+		encryptionKeyFile: flags.String("encryption-key-file", os.Getenv("PROVS_ENCRYPTION_KEY_FILE"), "the key file of an encrypted store. Defaults to PROVS_ENCRYPTION_KEY_FILE"),
+	}
+}
+
+// open returns the client of the store selected by the flags.
+func (s *storeFlags) open() (client.BackendClient, error) {
+	var c client.BackendClient
+	var err error
+	switch *s.backend {
+	case "", "fs":
+		if *s.path == "" {
+			return nil, errors.New("the path of the store is missing. Set it with -path or the PROVS_PATH environment variable")
+		}
+		c, err = filesystem.NewFsClient(*s.path)
+	case "http":
+		if *s.url == "" {
+			return nil, errors.New("the url of the store is missing. Set it with -url or the PROVS_URL environment variable")
+		}
+		token := os.Getenv("PROVS_TOKEN")
+		if token == "" {
+			return nil, errors.New("the token of the store is missing. Set it with the PROVS_TOKEN environment variable")
+		}
+		c, err = remote.NewHttpClient(*s.url, token, remote.DefaultTimeout)
+	default:
+		return nil, fmt.Errorf("the backend must be fs or http, got %q", *s.backend)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the storage client: %w", err)
+	}
+
+	if *s.encryptionKeyFile == "" {
+		return c, nil
+	}
+	content, err := os.ReadFile(*s.encryptionKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the encryption key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err == nil {
+		c, err = client.NewEncryptedClient(c, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return c, nil
+}
@@ -1,7 +1,14 @@
 package client
 
-import "io"
+import (
+	"errors"
+	"io"
+	"os"
+)
 
+// BackendClient is the storage used by the provider.
+// Implementations must return errors wrapping os.ErrNotExist when the requested resource does not exist, and
+// CreateWithId errors wrapping os.ErrExist when the resource already exists.
 type BackendClient interface {
 	CreateWithId(resType string, id string, body io.Reader) error
 	Read(resType string, resId string) (io.Reader, error)
@@ -9,3 +16,13 @@ type BackendClient interface {
 	Destroy(resType string, resId string) error
 	Update(resType string, resId string, newContent io.Reader) error
 }
+
+// IsExist reports whether the error returned by a BackendClient is caused by a resource already existing.
+func IsExist(err error) bool {
+	return errors.Is(err, os.ErrExist)
+}
+
+// IsNotFound reports whether the error returned by a BackendClient is caused by a missing resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
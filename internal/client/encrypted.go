@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncryptionKeySize is the size of the keys of the encrypted backends, in bytes: they use AES-256.
+const EncryptionKeySize = 32
+
+// encryptedClient is a BackendClient encrypting the content of the objects before handing them to the wrapped backend.
+// Types and IDs are stored in clear, as the backends address the objects by them.
+type encryptedClient struct {
+	backend BackendClient
+	aead    cipher.AEAD
+}
+
+// NewEncryptedClient returns a BackendClient storing the objects in the given backend encrypted with AES-256-GCM.
+// The objects written without encryption, or with another key, cannot be read back.
+func NewEncryptedClient(backend BackendClient, key []byte) (BackendClient, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("the encryption key must be %d bytes long, got %d", EncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedClient{backend: backend, aead: aead}, nil
+}
+
+func (c *encryptedClient) CreateWithId(resType string, id string, body io.Reader) error {
+	sealed, err := c.seal(resType, body)
+	if err != nil {
+		return err
+	}
+	return c.backend.CreateWithId(resType, id, sealed)
+}
+
+func (c *encryptedClient) Read(resType string, resId string) (io.Reader, error) {
+	sealed, err := c.backend.Read(resType, resId)
+	if err != nil {
+		return nil, err
+	}
+	return c.open(resType, resId, sealed)
+}
+
+func (c *encryptedClient) ReadAll(resType string) ([]io.Reader, error) {
+	all, err := c.backend.ReadAll(resType)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]io.Reader, len(all))
+	for i, sealed := range all {
+		if res[i], err = c.open(resType, "", sealed); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (c *encryptedClient) Destroy(resType string, resId string) error {
+	return c.backend.Destroy(resType, resId)
+}
+
+func (c *encryptedClient) Update(resType string, resId string, newContent io.Reader) error {
+	sealed, err := c.seal(resType, newContent)
+	if err != nil {
+		return err
+	}
+	return c.backend.Update(resType, resId, sealed)
+}
+
+// seal encrypts the content, prefixed by a random nonce. The type authenticates the content, so that an object
+// cannot be passed for an object of another type.
+func (c *encryptedClient) seal(resType string, content io.Reader) (io.Reader, error) {
+	plain, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(c.aead.Seal(nonce, nonce, plain, []byte(resType))), nil
+}
+
+func (c *encryptedClient) open(resType string, resId string, content io.Reader) (io.Reader, error) {
+	sealed, err := io.ReadAll(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("the object is not encrypted")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(resType))
+	if err != nil {
+		if resId == "" {
+			return nil, fmt.Errorf("cannot decrypt an object of type %s: %w", resType, err)
+		}
+		return nil, fmt.Errorf("cannot decrypt %s %s: %w", resType, resId, err)
+	}
+	return bytes.NewReader(plain), nil
+}
@@ -0,0 +1,68 @@
+package client_test
+
+import (
+	"bytes"
+	"io"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestEncryptedClient(t *testing.T) {
+	fs, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{1}, client.EncryptionKeySize)
+	backend, err := client.NewEncryptedClient(fs, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, "ingredient")
+	if _, err := ingredients.Create(&model.Ingredient{ID: "milk", Name: "Milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ingredients.Update(&model.Ingredient{ID: "milk", Name: "Oat milk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if i, err := ingredients.GetByID("milk"); err != nil || i.Name != "Oat milk" {
+		t.Fatalf("expected the ingredient decrypted, got %v (%v)", i, err)
+	}
+	if all, err := ingredients.GetAll(); err != nil || len(all) != 1 || all[0].Name != "Oat milk" {
+		t.Fatalf("expected the ingredients decrypted, got %v (%v)", all, err)
+	}
+
+	// the backend only sees the encrypted content
+	r, err := fs.Read("ingredient", "milk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("Oat milk")) {
+		t.Fatalf("expected the content encrypted, got %s", stored)
+	}
+
+	// another key or another type cannot read it
+	other, err := client.NewEncryptedClient(fs, bytes.Repeat([]byte{2}, client.EncryptionKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Ingredient](other, "ingredient").GetByID("milk"); err == nil {
+		t.Fatal("expected decrypting with another key to fail")
+	}
+	if err := fs.CreateWithId("coffee", "milk", bytes.NewReader(stored)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Read("coffee", "milk"); err == nil {
+		t.Fatal("expected decrypting an object moved to another type to fail")
+	}
+
+	if _, err := client.NewEncryptedClient(fs, key[:16]); err == nil {
+		t.Fatal("expected a short key to be refused")
+	}
+}
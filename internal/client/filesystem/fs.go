@@ -6,21 +6,29 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
+	"path/filepath"
 	"terraform-provider-provs/internal/client"
 
 	"github.com/spf13/afero"
-	_ "github.com/spf13/afero"
 )
 
-// fsClient implements BackendClient to provide a local storage solution for resources management
+// fsClient implements BackendClient to provide a local storage solution for resources management.
+// The objects are written to temporary files first, and then moved in place at once, so that the readers, maybe of
+// other processes, never see an object partly written.
 type fsClient struct {
-	fs afero.Fs
+	fs       afero.Fs
+	basePath string
 }
 
+// tmpDir is the directory of the temporary files, next to the directories of the types
+const tmpDir = ".tmp"
+
+// NewFsClient returns a BackendClient storing the objects in the files of the given directory, created when missing.
+// A relative path is resolved against the working directory.
 func NewFsClient(basePath string) (client.BackendClient, error) {
-	if !path.IsAbs(basePath) {
-		return nil, fmt.Errorf("only absolute paths allowed")
+	basePath, err := filepath.Abs(basePath)
+	if err != nil {
+		return nil, err
 	}
 	if err := os.MkdirAll(basePath, 0744); err != nil {
 		return nil, err
@@ -30,6 +38,7 @@ func NewFsClient(basePath string) (client.BackendClient, error) {
 			afero.NewOsFs(),
 			basePath,
 		),
+		basePath: basePath,
 	}, nil
 }
 
@@ -55,10 +64,17 @@ func (c *fsClient) Destroy(resType string, resId string) error {
 }
 
 func (c *fsClient) Update(resType string, resId string, newContent io.Reader) error {
-	if err := c.Destroy(resType, resId); err != nil {
+	fileName := fmt.Sprintf("%s%s%s", resType, string(os.PathSeparator), resId)
+	if _, err := c.fs.Stat(fileName); err != nil {
 		return err
 	}
-	if err := c.CreateWithId(resType, resId, newContent); err != nil {
+	tmp, err := c.writeTemp(newContent)
+	if err != nil {
+		return err
+	}
+	// the rename replaces the file at once
+	if err := c.fs.Rename(tmp, fileName); err != nil {
+		_ = c.fs.Remove(tmp)
 		return err
 	}
 	return nil
@@ -69,32 +85,36 @@ func (c *fsClient) CreateWithId(resType string, resId string, body io.Reader) er
 		return err
 	}
 	fileName := fmt.Sprintf("%s%s%s", resType, string(os.PathSeparator), resId)
-	f, err := c.fs.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, os.FileMode(0644))
+	tmp, err := c.writeTemp(body)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		_ = f.Close()
+		_ = c.fs.Remove(tmp)
 	}()
+	// the hard link publishes the file at once, and fails with os.ErrExist when the object exists, so that the
+	// concurrent creations of the same object do not overwrite each other
+	return os.Link(filepath.Join(c.basePath, tmp), filepath.Join(c.basePath, fileName))
+}
 
-	b := make([]byte, 1024)
-	for {
-		n, err := body.Read(b)
-		if err != nil && err != io.EOF {
-			return err
-		}
-		if n == 0 {
-			break
-		}
-
-		// write a chunk
-		if _, err := f.Write(b[:n]); err != nil {
-			return err
-		}
-
+// writeTemp writes the content to a new temporary file and returns its name
+func (c *fsClient) writeTemp(content io.Reader) (string, error) {
+	if err := c.fs.Mkdir(tmpDir, 0744); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
 	}
-	return nil
-
+	f, err := afero.TempFile(c.fs, tmpDir, "object-")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = c.fs.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
 }
 
 func (c *fsClient) ReadAll(resType string) ([]io.Reader, error) {
@@ -110,6 +130,10 @@ func (c *fsClient) ReadAll(resType string) ([]io.Reader, error) {
 	}
 	for _, fi := range fileInfo {
 		content, err := c.Read(resType, fi.Name())
+		if os.IsNotExist(err) {
+			// deleted since listed
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
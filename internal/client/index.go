@@ -0,0 +1,117 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"terraform-provider-provs/internal/model"
+)
+
+// ErrNameConflict is returned when a name is already reserved by a different object.
+var ErrNameConflict = errors.New("name already in use")
+
+// NameIndex keeps a unique mapping between a human-readable name and the ID of the object owning it.
+type NameIndex interface {
+	// Lookup returns the ID of the object owning the given name.
+	Lookup(name string) (string, error)
+	// Reserve assigns the name to the object with the given ID. It returns an error wrapping ErrNameConflict
+	// if the name is already owned by another object, indexed or not.
+	Reserve(name string, id string) error
+	// Release frees the name if it is owned by the object with the given ID.
+	Release(name string, id string) error
+}
+
+type nameIndex struct {
+	c Client[*model.IndexEntry]
+	// owners returns the IDs of the stored objects with the given name, indexed or not
+	owners func(name string) ([]string, error)
+}
+
+// maxReserveAttempts bounds the attempts of Reserve, that starts over when the entry it found is released meanwhile
+const maxReserveAttempts = 3
+
+// NewNameIndex returns a NameIndex for the objects of type T stored under resType, named by nameOf.
+// The index itself is stored next to the objects, under resType with the "_names" suffix. The objects stored before
+// the index existed are searched for when their name is reserved, so that they keep it.
+func NewNameIndex[T model.IDer](backend BackendClient, resType string, nameOf func(T) string) NameIndex {
+	objects := NewClient[T](backend, resType)
+	return &nameIndex{
+		c: NewClient[*model.IndexEntry](backend, resType+"_names"),
+		owners: func(name string) ([]string, error) {
+			all, err := objects.GetAll()
+			if err != nil && !IsNotFound(err) {
+				return nil, err
+			}
+			var res []string
+			for _, obj := range all {
+				if nameOf(obj) == name {
+					res = append(res, obj.GetID())
+				}
+			}
+			return res, nil
+		},
+	}
+}
+
+func (i *nameIndex) Lookup(name string) (string, error) {
+	entry, err := i.c.GetByID(indexKey(name))
+	if err != nil {
+		return "", err
+	}
+	return entry.OwnerID, nil
+}
+
+// Reserve creates the entry of the name exclusively, so that of two concurrent reservations of the same name only
+// one succeeds.
+func (i *nameIndex) Reserve(name string, id string) error {
+	for range maxReserveAttempts {
+		entry, err := i.c.GetByID(indexKey(name))
+		if err == nil {
+			if entry.OwnerID == id {
+				return nil
+			}
+			return fmt.Errorf("%w: %q is used by %q", ErrNameConflict, name, entry.OwnerID)
+		}
+		if !IsNotFound(err) {
+			return err
+		}
+		owners, err := i.owners(name)
+		if err != nil {
+			return err
+		}
+		for _, owner := range owners {
+			if owner != id {
+				return fmt.Errorf("%w: %q is used by %q", ErrNameConflict, name, owner)
+			}
+		}
+		_, err = i.c.Create(&model.IndexEntry{
+			ID:      indexKey(name),
+			Name:    name,
+			OwnerID: id,
+		})
+		if !IsExist(err) {
+			return err
+		}
+		// reserved concurrently, the entry tells by whom
+	}
+	return fmt.Errorf("could not reserve %q: its entry in the index keeps changing", name)
+}
+
+func (i *nameIndex) Release(name string, id string) error {
+	entry, err := i.c.GetByID(indexKey(name))
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if entry.OwnerID != id {
+		return nil
+	}
+	return i.c.Delete(entry.ID)
+}
+
+// indexKey encodes the name so it can be used as a storage key regardless of the characters it contains.
+func indexKey(name string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(name))
+}
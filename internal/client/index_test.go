@@ -0,0 +1,94 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func newTestNameIndex(t *testing.T) (client.BackendClient, client.NameIndex) {
+	t.Helper()
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend, client.NewNameIndex(backend, "secret_manager", func(m *model.SecretManager) string { return m.Name })
+}
+
+func TestNameIndex_reserve(t *testing.T) {
+	_, index := newTestNameIndex(t)
+	if err := index.Reserve("prod", "1"); err != nil {
+		t.Fatal(err)
+	}
+	// again by its owner
+	if err := index.Reserve("prod", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Reserve("prod", "2"); !errors.Is(err, client.ErrNameConflict) {
+		t.Fatalf("expected a name conflict, got %v", err)
+	}
+	if err := index.Release("prod", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := index.Lookup("prod"); err != nil || id != "1" {
+		t.Fatalf("expected the name kept by 1, got %q (%v)", id, err)
+	}
+	if err := index.Release("prod", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Reserve("prod", "2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNameIndex_reserveConcurrently(t *testing.T) {
+	_, index := newTestNameIndex(t)
+	const n = 8
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = index.Reserve("prod", fmt.Sprint(i))
+		}()
+	}
+	wg.Wait()
+
+	var reserved int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			reserved++
+		case !errors.Is(err, client.ErrNameConflict):
+			t.Fatalf("expected a name conflict, got %v", err)
+		}
+	}
+	if reserved != 1 {
+		t.Fatalf("expected a single reservation, got %d", reserved)
+	}
+}
+
+// TestNameIndex_reserveUnindexed reserves the names of the objects stored before the index existed.
+func TestNameIndex_reserveUnindexed(t *testing.T) {
+	backend, index := newTestNameIndex(t)
+	if _, err := client.NewClient[*model.SecretManager](backend, "secret_manager").Create(&model.SecretManager{ID: "1", Name: "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Reserve("prod", "2"); !errors.Is(err, client.ErrNameConflict) {
+		t.Fatalf("expected a name conflict, got %v", err)
+	}
+	if _, err := index.Lookup("prod"); !client.IsNotFound(err) {
+		t.Fatalf("expected the name not indexed, got %v", err)
+	}
+	if err := index.Reserve("prod", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := index.Lookup("prod"); err != nil || id != "1" {
+		t.Fatalf("expected the name indexed for 1, got %q (%v)", id, err)
+	}
+}
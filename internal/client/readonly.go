@@ -0,0 +1,59 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ErrReadOnly is wrapped by the errors of the writes refused by a read-only BackendClient.
+var ErrReadOnly = errors.New("the store is read-only")
+
+// readOnlyClient is a BackendClient refusing the writes to the wrapped backend, but for the writable types.
+type readOnlyClient struct {
+	backend  BackendClient
+	writable []string
+}
+
+// NewReadOnlyClient returns a BackendClient reading from the given backend and refusing the writes with errors
+// wrapping ErrReadOnly. The objects of the writable types, the bookkeeping of the reads, can still be written.
+func NewReadOnlyClient(backend BackendClient, writable ...string) BackendClient {
+	return &readOnlyClient{backend: backend, writable: writable}
+}
+
+func (c *readOnlyClient) CreateWithId(resType string, id string, body io.Reader) error {
+	if err := c.check("create", resType, id); err != nil {
+		return err
+	}
+	return c.backend.CreateWithId(resType, id, body)
+}
+
+func (c *readOnlyClient) Read(resType string, resId string) (io.Reader, error) {
+	return c.backend.Read(resType, resId)
+}
+
+func (c *readOnlyClient) ReadAll(resType string) ([]io.Reader, error) {
+	return c.backend.ReadAll(resType)
+}
+
+func (c *readOnlyClient) Destroy(resType string, resId string) error {
+	if err := c.check("delete", resType, resId); err != nil {
+		return err
+	}
+	return c.backend.Destroy(resType, resId)
+}
+
+func (c *readOnlyClient) Update(resType string, resId string, newContent io.Reader) error {
+	if err := c.check("update", resType, resId); err != nil {
+		return err
+	}
+	return c.backend.Update(resType, resId, newContent)
+}
+
+func (c *readOnlyClient) check(op string, resType string, id string) error {
+	if slices.Contains(c.writable, resType) {
+		return nil
+	}
+	return fmt.Errorf("cannot %s %s %s: %w", op, resType, id, ErrReadOnly)
+}
@@ -0,0 +1,49 @@
+package client_test
+
+import (
+	"errors"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestReadOnlyClient(t *testing.T) {
+	fs, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Ingredient](fs, "ingredient").Create(&model.Ingredient{ID: "milk", Name: "Milk"}); err != nil {
+		t.Fatal(err)
+	}
+	backend := client.NewReadOnlyClient(fs, "lease")
+
+	ingredients := client.NewClient[*model.Ingredient](backend, "ingredient")
+	if i, err := ingredients.GetByID("milk"); err != nil || i.Name != "Milk" {
+		t.Fatalf("expected the ingredient read, got %v (%v)", i, err)
+	}
+	if all, err := ingredients.GetAll(); err != nil || len(all) != 1 {
+		t.Fatalf("expected the ingredients read, got %v (%v)", all, err)
+	}
+	if _, err := ingredients.Create(&model.Ingredient{ID: "water", Name: "Water"}); !errors.Is(err, client.ErrReadOnly) {
+		t.Fatalf("expected the creation refused, got %v", err)
+	}
+	if err := ingredients.Update(&model.Ingredient{ID: "milk", Name: "Oat milk"}); !errors.Is(err, client.ErrReadOnly) {
+		t.Fatalf("expected the update refused, got %v", err)
+	}
+	if err := ingredients.Delete("milk"); !errors.Is(err, client.ErrReadOnly) {
+		t.Fatalf("expected the deletion refused, got %v", err)
+	}
+	if i, err := client.NewClient[*model.Ingredient](fs, "ingredient").GetByID("milk"); err != nil || i.Name != "Milk" {
+		t.Fatalf("expected the ingredient unchanged, got %v (%v)", i, err)
+	}
+
+	// the writable types can still be written
+	leases := client.NewClient[*model.Ingredient](backend, "lease")
+	if _, err := leases.Create(&model.Ingredient{ID: "l1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := leases.Delete("l1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package remote stores the provider data behind an HTTP API, so that several Terraform configurations can share it.
+//
+// The API addresses the objects as {base URL}/{type}/{id}:
+//   - GET {type}/{id} returns the content of the object, 404 when it does not exist
+//   - GET {type} returns the contents of all the objects of the type, as a JSON array of base64 strings, 404 when there are none
+//   - PUT {type}/{id} creates the object, 412 when it already exists, or replaces it when the request has the
+//     "If-Match: *" header, 404 when it does not exist
+//   - DELETE {type}/{id} deletes the object, 404 when it does not exist
+//
+// Every request carries the token shared by the clients and the server in the "Authorization: Bearer" header, 401
+// otherwise. The token is sent in clear over http: URLs, so that the stores reached through a network are served
+// over https.
+//
+// NewHandler serves this API for any BackendClient.
+package remote
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"time"
+)
+
+// httpClient implements BackendClient on top of the HTTP API of the package.
+type httpClient struct {
+	baseURL *url.URL
+	token   string
+	http    *http.Client
+}
+
+// DefaultTimeout is the timeout of the requests to the HTTP API when none is set.
+const DefaultTimeout = 30 * time.Second
+
+// NewHttpClient returns a BackendClient storing the objects behind the HTTP API at the given base URL, authenticated
+// with the given token. Each request, including the reading of the response, fails after the given timeout.
+func NewHttpClient(baseURL string, token string, timeout time.Duration) (client.BackendClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https URLs allowed")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the token of the store is missing")
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("the timeout must be positive, got %s", timeout)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &httpClient{
+		baseURL: u,
+		token:   token,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *httpClient) CreateWithId(resType string, id string, body io.Reader) error {
+	_, err := c.do(http.MethodPut, c.objectURL(resType, id), body, nil)
+	return err
+}
+
+func (c *httpClient) Read(resType string, resId string) (io.Reader, error) {
+	content, err := c.do(http.MethodGet, c.objectURL(resType, resId), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(content), nil
+}
+
+func (c *httpClient) ReadAll(resType string) ([]io.Reader, error) {
+	content, err := c.do(http.MethodGet, c.typeURL(resType), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var contents [][]byte
+	if err := json.Unmarshal(content, &contents); err != nil {
+		return nil, fmt.Errorf("unexpected response listing %s: %w", resType, err)
+	}
+	res := make([]io.Reader, len(contents))
+	for i, content := range contents {
+		res[i] = bytes.NewReader(content)
+	}
+	return res, nil
+}
+
+func (c *httpClient) Destroy(resType string, resId string) error {
+	_, err := c.do(http.MethodDelete, c.objectURL(resType, resId), nil, nil)
+	return err
+}
+
+func (c *httpClient) Update(resType string, resId string, newContent io.Reader) error {
+	_, err := c.do(http.MethodPut, c.objectURL(resType, resId), newContent, http.Header{"If-Match": []string{"*"}})
+	return err
+}
+
+func (c *httpClient) typeURL(resType string) string {
+	return c.baseURL.JoinPath(resType).String()
+}
+
+func (c *httpClient) objectURL(resType string, id string) string {
+	return c.baseURL.JoinPath(resType, id).String()
+}
+
+// do sends the request and returns the body of the response. The 404 and 412 responses are reported as errors
+// wrapping os.ErrNotExist and os.ErrExist, like the BackendClient implementations must.
+func (c *httpClient) do(method string, u string, body io.Reader, header http.Header) ([]byte, error) {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s %s: %w", method, u, os.ErrNotExist)
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return nil, fmt.Errorf("%s %s: %w", method, u, os.ErrExist)
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("%s %s: %s: %s", method, u, resp.Status, strings.TrimSpace(string(content)))
+	}
+	return content, nil
+}
+
+// NewHandler serves the HTTP API of the package, storing the objects in the given backend, to the clients
+// authenticated with the given token. An empty token refuses all the requests.
+func NewHandler(backend client.BackendClient, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{type}", func(w http.ResponseWriter, r *http.Request) {
+		all, err := backend.ReadAll(r.PathValue("type"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		contents := make([][]byte, len(all))
+		for i, content := range all {
+			if contents[i], err = io.ReadAll(content); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(contents)
+	})
+	mux.HandleFunc("GET /{type}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		content, err := backend.Read(r.PathValue("type"), r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		_, _ = io.Copy(w, content)
+	})
+	mux.HandleFunc("PUT /{type}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if r.Header.Get("If-Match") == "*" {
+			err = backend.Update(r.PathValue("type"), r.PathValue("id"), r.Body)
+		} else {
+			err = backend.CreateWithId(r.PathValue("type"), r.PathValue("id"), r.Body)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /{type}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := backend.Destroy(r.PathValue("type"), r.PathValue("id")); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if client.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, os.ErrExist) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
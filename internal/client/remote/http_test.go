@@ -0,0 +1,117 @@
+package remote_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+)
+
+func TestHttpClient(t *testing.T) {
+	fs, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.StripPrefix("/store", remote.NewHandler(fs, "t0ken")))
+	defer server.Close()
+	backend, err := remote.NewHttpClient(server.URL+"/store", "t0ken", remote.DefaultTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, "ingredient")
+
+	if _, err := ingredients.GetAll(); !client.IsNotFound(err) {
+		t.Fatalf("expected listing a missing type to be not found, got %v", err)
+	}
+	if _, err := ingredients.Create(&model.Ingredient{ID: "milk", Name: "Milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ingredients.Create(&model.Ingredient{ID: "water", Name: "Water"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ingredients.Create(&model.Ingredient{ID: "water", Name: "Sparkling water"}); !client.IsExist(err) {
+		t.Fatalf("expected creating an existing object to fail, got %v", err)
+	}
+	if err := ingredients.Update(&model.Ingredient{ID: "milk", Name: "Oat milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ingredients.Update(&model.Ingredient{ID: "sugar", Name: "Sugar"}); !client.IsNotFound(err) {
+		t.Fatalf("expected updating a missing object to be not found, got %v", err)
+	}
+
+	if i, err := ingredients.GetByID("milk"); err != nil || i.Name != "Oat milk" {
+		t.Fatalf("expected the updated ingredient, got %v (%v)", i, err)
+	}
+	if all, err := ingredients.GetAll(); err != nil || len(all) != 2 {
+		t.Fatalf("expected 2 ingredients, got %v (%v)", all, err)
+	}
+	// the objects are stored in the backend of the server
+	if i, err := client.NewClient[*model.Ingredient](fs, "ingredient").GetByID("water"); err != nil || i.Name != "Water" {
+		t.Fatalf("expected the ingredient in the server backend, got %v (%v)", i, err)
+	}
+
+	if err := ingredients.Delete("milk"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ingredients.GetByID("milk"); !client.IsNotFound(err) {
+		t.Fatalf("expected the deleted ingredient to be not found, got %v", err)
+	}
+	if err := ingredients.Delete("milk"); !client.IsNotFound(err) {
+		t.Fatalf("expected deleting a missing object to be not found, got %v", err)
+	}
+
+	if _, err := remote.NewHttpClient("ftp://example.com", "t0ken", remote.DefaultTimeout); err == nil {
+		t.Fatal("expected a non HTTP URL to be refused")
+	}
+	if _, err := remote.NewHttpClient(server.URL, "", remote.DefaultTimeout); err == nil {
+		t.Fatal("expected a missing token to be refused")
+	}
+	if _, err := remote.NewHttpClient(server.URL, "t0ken", 0); err == nil {
+		t.Fatal("expected a zero timeout to be refused")
+	}
+}
+
+func TestHttpClient_unauthorized(t *testing.T) {
+	fs, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, serverToken := range map[string]string{"wrong token": "t0ken", "no server token": ""} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(remote.NewHandler(fs, serverToken))
+			defer server.Close()
+			backend, err := remote.NewHttpClient(server.URL, "other", remote.DefaultTimeout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := backend.ReadAll("ingredient"); err == nil || !strings.Contains(err.Error(), "401") {
+				t.Fatalf("expected the request refused, got %v", err)
+			}
+			if _, err := client.NewClient[*model.Ingredient](backend, "ingredient").Create(&model.Ingredient{ID: "milk"}); err == nil {
+				t.Fatal("expected the creation refused")
+			}
+		})
+	}
+	if _, err := fs.ReadAll("ingredient"); !client.IsNotFound(err) {
+		t.Fatalf("expected nothing written, got %v", err)
+	}
+}
+
+func TestHttpClient_timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+	backend, err := remote.NewHttpClient(server.URL, "t0ken", 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Read("ingredient", "milk"); err == nil || client.IsNotFound(err) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+}
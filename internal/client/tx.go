@@ -0,0 +1,158 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Tx is a BackendClient buffering the writes until Commit applies them to the wrapped backend, so that the
+// objects changed together are either all written or none of them are.
+// Reads see the writes buffered so far. ReadAll is refused for the types written in the transaction,
+// as the readers it returns do not carry the IDs the buffered writes would be merged by.
+type Tx struct {
+	backend BackendClient
+	writes  []txWrite
+	// pending is the latest buffered content of every object written, nil once destroyed
+	pending map[txKey][]byte
+}
+
+type txKey struct {
+	resType string
+	id      string
+}
+
+type txOp int
+
+const (
+	txCreate txOp = iota
+	txUpdate
+	txDestroy
+)
+
+type txWrite struct {
+	op      txOp
+	key     txKey
+	content []byte
+}
+
+// NewTx starts a transaction on the backend. Nothing is written until Commit is called.
+func NewTx(backend BackendClient) *Tx {
+	return &Tx{
+		backend: backend,
+		pending: map[txKey][]byte{},
+	}
+}
+
+func (tx *Tx) CreateWithId(resType string, id string, body io.Reader) error {
+	return tx.write(txCreate, resType, id, body)
+}
+
+func (tx *Tx) Update(resType string, resId string, newContent io.Reader) error {
+	return tx.write(txUpdate, resType, resId, newContent)
+}
+
+func (tx *Tx) Destroy(resType string, resId string) error {
+	return tx.write(txDestroy, resType, resId, nil)
+}
+
+func (tx *Tx) Read(resType string, resId string) (io.Reader, error) {
+	if content, ok := tx.pending[txKey{resType, resId}]; ok {
+		if content == nil {
+			return nil, fmt.Errorf("%s %s destroyed in the transaction: %w", resType, resId, os.ErrNotExist)
+		}
+		return bytes.NewReader(content), nil
+	}
+	return tx.backend.Read(resType, resId)
+}
+
+func (tx *Tx) ReadAll(resType string) ([]io.Reader, error) {
+	for key := range tx.pending {
+		if key.resType == resType {
+			return nil, fmt.Errorf("cannot read all the %s written in the transaction", resType)
+		}
+	}
+	return tx.backend.ReadAll(resType)
+}
+
+// Commit applies the buffered writes in order. If one of them fails, the writes already applied are reverted
+// and the error is returned, joined with the errors met while reverting.
+func (tx *Tx) Commit() error {
+	var applied []txWrite
+	for _, w := range tx.writes {
+		prev, err := tx.snapshot(w.key)
+		if err != nil {
+			return errors.Join(err, tx.revert(applied))
+		}
+		if err := tx.apply(w); err != nil {
+			return errors.Join(err, tx.revert(applied))
+		}
+		// keep the previous content to restore it on revert
+		applied = append(applied, txWrite{op: w.op, key: w.key, content: prev})
+	}
+	tx.writes = nil
+	tx.pending = map[txKey][]byte{}
+	return nil
+}
+
+func (tx *Tx) write(op txOp, resType string, id string, body io.Reader) error {
+	var content []byte
+	if body != nil {
+		var err error
+		if content, err = io.ReadAll(body); err != nil {
+			return err
+		}
+	}
+	key := txKey{resType, id}
+	tx.writes = append(tx.writes, txWrite{op: op, key: key, content: content})
+	tx.pending[key] = content
+	return nil
+}
+
+func (tx *Tx) apply(w txWrite) error {
+	switch w.op {
+	case txCreate:
+		return tx.backend.CreateWithId(w.key.resType, w.key.id, bytes.NewReader(w.content))
+	case txUpdate:
+		return tx.backend.Update(w.key.resType, w.key.id, bytes.NewReader(w.content))
+	default:
+		return tx.backend.Destroy(w.key.resType, w.key.id)
+	}
+}
+
+// snapshot returns the content of the object in the backend, nil when it does not exist
+func (tx *Tx) snapshot(key txKey) ([]byte, error) {
+	r, err := tx.backend.Read(key.resType, key.id)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// revert restores the previous content of the objects written, the last write first
+func (tx *Tx) revert(applied []txWrite) error {
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		w := applied[i]
+		var err error
+		switch {
+		case w.content == nil && w.op != txDestroy:
+			err = tx.backend.Destroy(w.key.resType, w.key.id)
+		case w.content == nil:
+			// destroying a missing object changed nothing
+		case w.op == txDestroy:
+			err = tx.backend.CreateWithId(w.key.resType, w.key.id, bytes.NewReader(w.content))
+		default:
+			err = tx.backend.Update(w.key.resType, w.key.id, bytes.NewReader(w.content))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reverting %s %s: %w", w.key.resType, w.key.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+// failingBackend fails the writes of the object with the given ID
+type failingBackend struct {
+	client.BackendClient
+	failID string
+}
+
+var errWrite = errors.New("write failed")
+
+func (b *failingBackend) CreateWithId(resType string, id string, body io.Reader) error {
+	if id == b.failID {
+		return errWrite
+	}
+	return b.BackendClient.CreateWithId(resType, id, body)
+}
+
+func (b *failingBackend) Update(resType string, id string, body io.Reader) error {
+	if id == b.failID {
+		return errWrite
+	}
+	return b.BackendClient.Update(resType, id, body)
+}
+
+func TestTx_commit(t *testing.T) {
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, "ingredient")
+	if _, err := ingredients.Create(&model.Ingredient{ID: "milk", Name: "Milk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := client.NewTx(backend)
+	txIngredients := client.NewClient[*model.Ingredient](tx, "ingredient")
+	if err := txIngredients.Update(&model.Ingredient{ID: "milk", Name: "Oat milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txIngredients.Create(&model.Ingredient{ID: "beans", Name: "Coffee beans"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the writes are seen in the transaction only
+	if i, err := txIngredients.GetByID("milk"); err != nil || i.Name != "Oat milk" {
+		t.Fatalf("expected the pending update in the transaction, got %v (%v)", i, err)
+	}
+	if i, err := ingredients.GetByID("milk"); err != nil || i.Name != "Milk" {
+		t.Fatalf("expected the backend unchanged before commit, got %v (%v)", i, err)
+	}
+	if _, err := txIngredients.GetAll(); err == nil {
+		t.Fatal("expected reading all the objects written in the transaction to fail")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	all, err := ingredients.GetAll()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected 2 ingredients, got %d (%v)", len(all), err)
+	}
+	if i, err := ingredients.GetByID("milk"); err != nil || i.Name != "Oat milk" {
+		t.Fatalf("expected the update committed, got %v (%v)", i, err)
+	}
+}
+
+func TestTx_commitReverts(t *testing.T) {
+	fs, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &failingBackend{BackendClient: fs, failID: "sugar"}
+	ingredients := client.NewClient[*model.Ingredient](backend, "ingredient")
+	for _, i := range []*model.Ingredient{{ID: "milk", Name: "Milk"}, {ID: "water", Name: "Water"}} {
+		if _, err := ingredients.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx := client.NewTx(backend)
+	txIngredients := client.NewClient[*model.Ingredient](tx, "ingredient")
+	if err := txIngredients.Update(&model.Ingredient{ID: "milk", Name: "Oat milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txIngredients.Create(&model.Ingredient{ID: "beans", Name: "Coffee beans"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := txIngredients.Delete("water"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txIngredients.Create(&model.Ingredient{ID: "sugar", Name: "Sugar"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, errWrite) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	all, err := ingredients.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, i := range all {
+		names = append(names, i.ID+"="+i.Name)
+	}
+	if got := strings.Join(names, ","); got != "milk=Milk,water=Water" {
+		t.Fatalf("expected the backend as before the commit, got %s", got)
+	}
+}
@@ -0,0 +1,244 @@
+// Package generator creates random secret values.
+// All the values are generated using crypto/rand.
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the type of value a Spec generates
+type Kind string
+
+const (
+	KindRandomBytes Kind = "random_bytes"
+	KindPassword    Kind = "password"
+	KindUUID        Kind = "uuid"
+)
+
+// Kinds returns all the supported kinds
+func Kinds() []string {
+	return []string{string(KindRandomBytes), string(KindPassword), string(KindUUID)}
+}
+
+const (
+	// DefaultRandomBytesLength is the number of bytes generated when the Spec does not configure it
+	DefaultRandomBytesLength = 32
+
+	CharsetUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsetLower   = "abcdefghijklmnopqrstuvwxyz"
+	CharsetNumeric = "0123456789"
+	CharsetSpecial = "!@#$%&*()-_=+[]{}<>:?"
+)
+
+// Spec describes how a value is generated
+type Spec struct {
+	Kind Kind `json:"type"`
+	// Length is the number of bytes for KindRandomBytes. For KindPassword the Password.Length is used.
+	Length int `json:"length,omitempty"`
+	// Prefix is prepended to the generated value, followed by "-"
+	Prefix   string         `json:"prefix,omitempty"`
+	Password PasswordPolicy `json:"password,omitempty"`
+}
+
+// PasswordPolicy describes the characters a password is made of.
+type PasswordPolicy struct {
+	Length int `json:"length"`
+	// Charset, when set, replaces the upper, lower and numeric characters sets
+	Charset string `json:"charset,omitempty"`
+	Upper   bool   `json:"upper"`
+	Lower   bool   `json:"lower"`
+	Numeric bool   `json:"numeric"`
+	Special bool   `json:"special"`
+	// OverrideSpecial replaces the default special characters
+	OverrideSpecial string `json:"override_special,omitempty"`
+	MinUpper        int    `json:"min_upper,omitempty"`
+	MinLower        int    `json:"min_lower,omitempty"`
+	MinNumeric      int    `json:"min_numeric,omitempty"`
+	MinSpecial      int    `json:"min_special,omitempty"`
+	// Exclude contains the characters that must never be part of the password
+	Exclude string `json:"exclude,omitempty"`
+}
+
+// DefaultPasswordPolicy returns a policy generating passwords of the given length from all the character sets
+func DefaultPasswordPolicy(length int) PasswordPolicy {
+	return PasswordPolicy{
+		Length:  length,
+		Upper:   true,
+		Lower:   true,
+		Numeric: true,
+		Special: true,
+	}
+}
+
+// Generate returns a new value as described by the spec
+func Generate(spec Spec) (string, error) {
+	var (
+		res string
+		err error
+	)
+	switch spec.Kind {
+	case KindRandomBytes:
+		length := spec.Length
+		if length == 0 {
+			length = DefaultRandomBytesLength
+		}
+		res, err = RandomBytes(length)
+	case KindPassword:
+		res, err = Password(spec.Password)
+	case KindUUID:
+		res = uuid.NewString()
+	default:
+		return "", fmt.Errorf("unknown generator type %q, expected one of: %s", spec.Kind, strings.Join(Kinds(), ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+	return withPrefix(spec.Prefix, res), nil
+}
+
+// UUID returns a random UUID, prefixed by "<prefix>-" when the prefix is not empty
+func UUID(prefix string) string {
+	return withPrefix(prefix, uuid.NewString())
+}
+
+// withPrefix returns the value prefixed by "<prefix>-", or the value alone when the prefix is empty
+func withPrefix(prefix string, value string) string {
+	if prefix == "" {
+		return value
+	}
+	return prefix + "-" + value
+}
+
+// RandomBytes returns n random bytes, base64 encoded
+func RandomBytes(n int) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("the number of bytes must be positive, got %d", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// Password returns a password following the given policy
+func Password(p PasswordPolicy) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	// the characters are runes, since the user given sets may not be ASCII
+	var (
+		res []rune
+		all []rune
+	)
+	for _, set := range p.sets() {
+		for i := 0; i < set.min; i++ {
+			c, err := randomChar(set.chars)
+			if err != nil {
+				return "", err
+			}
+			res = append(res, c)
+		}
+		all = append(all, set.chars...)
+	}
+	for len(res) < p.Length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		res = append(res, c)
+	}
+	// the characters added for the minimums are at the beginning, so shuffle everything
+	for i := len(res) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		res[i], res[j.Int64()] = res[j.Int64()], res[i]
+	}
+	return string(res), nil
+}
+
+// Validate returns an error if no password can be generated with the policy
+func (p PasswordPolicy) Validate() error {
+	if p.Length < 1 {
+		return fmt.Errorf("the length of the password must be positive, got %d", p.Length)
+	}
+	sets := p.sets()
+	if len(sets) == 0 {
+		return fmt.Errorf("no characters left to generate the password from")
+	}
+	required := 0
+	for _, set := range sets {
+		if len(set.chars) == 0 && set.min > 0 {
+			return fmt.Errorf("the password requires %d %s characters but all of them are excluded", set.min, set.name)
+		}
+		required += set.min
+	}
+	if required > p.Length {
+		return fmt.Errorf("the minimum number of characters required (%d) exceeds the length of the password (%d)", required, p.Length)
+	}
+	return nil
+}
+
+type charset struct {
+	name  string
+	chars []rune
+	min   int
+}
+
+// sets returns the character sets enabled by the policy, without the excluded characters
+func (p PasswordPolicy) sets() []charset {
+	var res []charset
+	add := func(enabled bool, name string, chars string, min int) {
+		if !enabled {
+			return
+		}
+		res = append(res, charset{
+			name: name,
+			chars: []rune(strings.Map(func(r rune) rune {
+				if strings.ContainsRune(p.Exclude, r) {
+					return -1
+				}
+				return r
+			}, chars)),
+			min: min,
+		})
+	}
+	if p.Charset != "" {
+		add(true, "charset", p.Charset, 0)
+	} else {
+		add(p.Upper, "upper", CharsetUpper, p.MinUpper)
+		add(p.Lower, "lower", CharsetLower, p.MinLower)
+		add(p.Numeric, "numeric", CharsetNumeric, p.MinNumeric)
+	}
+	special := CharsetSpecial
+	if p.OverrideSpecial != "" {
+		special = p.OverrideSpecial
+	}
+	add(p.Special, "special", special, p.MinSpecial)
+
+	// drop the sets that ended up empty and are not required, to not fail on them
+	filtered := res[:0]
+	for _, set := range res {
+		if len(set.chars) > 0 || set.min > 0 {
+			filtered = append(filtered, set)
+		}
+	}
+	return filtered
+}
+
+func randomChar(chars []rune) (rune, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i.Int64()], nil
+}
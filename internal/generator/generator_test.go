@@ -0,0 +1,189 @@
+package generator_test
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/generator"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		spec generator.Spec
+		// want matches the generated value
+		want string
+		err  string
+	}{
+		"random bytes": {
+			spec: generator.Spec{Kind: generator.KindRandomBytes, Length: 3},
+			want: `^[A-Za-z0-9+/]{4}$`,
+		},
+		"random bytes of the default length": {
+			spec: generator.Spec{Kind: generator.KindRandomBytes},
+			want: `^[A-Za-z0-9+/]{43}=$`,
+		},
+		"prefixed random bytes": {
+			spec: generator.Spec{Kind: generator.KindRandomBytes, Length: 3, Prefix: "key"},
+			want: `^key-[A-Za-z0-9+/]{4}$`,
+		},
+		"password": {
+			spec: generator.Spec{Kind: generator.KindPassword, Password: generator.PasswordPolicy{Length: 8, Numeric: true}},
+			want: `^[0-9]{8}$`,
+		},
+		"prefixed password": {
+			spec: generator.Spec{Kind: generator.KindPassword, Prefix: "pw", Password: generator.PasswordPolicy{Length: 4, Lower: true}},
+			want: `^pw-[a-z]{4}$`,
+		},
+		"uuid": {
+			spec: generator.Spec{Kind: generator.KindUUID},
+			want: `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`,
+		},
+		"prefixed uuid": {
+			spec: generator.Spec{Kind: generator.KindUUID, Prefix: "id"},
+			want: `^id-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`,
+		},
+		"invalid password policy": {
+			spec: generator.Spec{Kind: generator.KindPassword},
+			err:  "the length of the password must be positive",
+		},
+		"unknown kind": {
+			spec: generator.Spec{Kind: "pin"},
+			err:  `unknown generator type "pin"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := generator.Generate(tc.spec)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected the error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !regexp.MustCompile(tc.want).MatchString(got) {
+				t.Fatalf("expected a value matching %s, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	if got := generator.UUID(""); strings.Count(got, "-") != 4 {
+		t.Fatalf("expected a bare UUID, got %q", got)
+	}
+	if got := generator.UUID("order"); !strings.HasPrefix(got, "order-") || strings.Count(got, "-") != 5 {
+		t.Fatalf("expected a UUID prefixed by order-, got %q", got)
+	}
+}
+
+func TestRandomBytes(t *testing.T) {
+	got, err := generator.RandomBytes(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := base64.StdEncoding.DecodeString(got); err != nil || len(b) != 16 {
+		t.Fatalf("expected 16 bytes base64 encoded, got %q (%v)", got, err)
+	}
+	if _, err := generator.RandomBytes(0); err == nil {
+		t.Fatal("expected an error for 0 bytes")
+	}
+}
+
+func TestPassword(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy generator.PasswordPolicy
+		// allowed are the characters the password can be made of
+		allowed string
+		// check checks the password further
+		check func(t *testing.T, password string)
+		err   string
+	}{
+		"default policy": {
+			policy:  generator.DefaultPasswordPolicy(64),
+			allowed: generator.CharsetUpper + generator.CharsetLower + generator.CharsetNumeric + generator.CharsetSpecial,
+		},
+		"charset": {
+			policy:  generator.PasswordPolicy{Length: 32, Charset: "ab", Upper: true, Lower: true, Numeric: true},
+			allowed: "ab",
+		},
+		"charset and special": {
+			policy:  generator.PasswordPolicy{Length: 32, Charset: "ab", Special: true, MinSpecial: 2, OverrideSpecial: "!"},
+			allowed: "ab!",
+			check: func(t *testing.T, password string) {
+				if strings.Count(password, "!") < 2 {
+					t.Fatalf("expected at least 2 special characters, got %q", password)
+				}
+			},
+		},
+		"override special": {
+			policy:  generator.PasswordPolicy{Length: 32, Special: true, OverrideSpecial: "#~"},
+			allowed: "#~",
+		},
+		"non-ASCII characters": {
+			policy:  generator.PasswordPolicy{Length: 32, Charset: "äöü", Special: true, OverrideSpecial: "€", MinSpecial: 1, Exclude: "ö"},
+			allowed: "äü€",
+		},
+		"exclude": {
+			policy:  generator.PasswordPolicy{Length: 64, Upper: true, Numeric: true, Exclude: "0O1I"},
+			allowed: strings.NewReplacer("0", "", "O", "", "1", "", "I", "").Replace(generator.CharsetUpper + generator.CharsetNumeric),
+		},
+		"minimums": {
+			policy:  generator.PasswordPolicy{Length: 4, Upper: true, Lower: true, Numeric: true, Special: true, MinUpper: 1, MinLower: 1, MinNumeric: 1, MinSpecial: 1},
+			allowed: generator.CharsetUpper + generator.CharsetLower + generator.CharsetNumeric + generator.CharsetSpecial,
+			check: func(t *testing.T, password string) {
+				for _, set := range []string{generator.CharsetUpper, generator.CharsetLower, generator.CharsetNumeric, generator.CharsetSpecial} {
+					if !strings.ContainsAny(password, set) {
+						t.Fatalf("expected a character of %s, got %q", set, password)
+					}
+				}
+			},
+		},
+		"excluded set not required": {
+			policy:  generator.PasswordPolicy{Length: 8, Lower: true, Numeric: true, Exclude: generator.CharsetNumeric},
+			allowed: generator.CharsetLower,
+		},
+		"zero length": {
+			policy: generator.PasswordPolicy{Upper: true},
+			err:    "the length of the password must be positive",
+		},
+		"no character set": {
+			policy: generator.PasswordPolicy{Length: 8},
+			err:    "no characters left",
+		},
+		"required set excluded": {
+			policy: generator.PasswordPolicy{Length: 8, Lower: true, Numeric: true, MinNumeric: 1, Exclude: generator.CharsetNumeric},
+			err:    "requires 1 numeric characters but all of them are excluded",
+		},
+		"minimums over the length": {
+			policy: generator.PasswordPolicy{Length: 2, Upper: true, Lower: true, MinUpper: 2, MinLower: 1},
+			err:    "exceeds the length of the password",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := generator.Password(tc.policy)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected the error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !utf8.ValidString(got) || utf8.RuneCountInString(got) != tc.policy.Length {
+				t.Fatalf("expected %d characters, got %q", tc.policy.Length, got)
+			}
+			if i := strings.IndexFunc(got, func(r rune) bool { return !strings.ContainsRune(tc.allowed, r) }); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(got[i:])
+				t.Fatalf("unexpected character %q in %q", r, got)
+			}
+			if tc.check != nil {
+				tc.check(t, got)
+			}
+		})
+	}
+}
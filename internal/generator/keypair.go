@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyAlgorithm is the type of key pair generated by GenerateKeyPair
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmED25519 KeyAlgorithm = "ed25519"
+	KeyAlgorithmRSA     KeyAlgorithm = "rsa"
+)
+
+// KeyAlgorithms returns all the supported key algorithms
+func KeyAlgorithms() []string {
+	return []string{string(KeyAlgorithmED25519), string(KeyAlgorithmRSA)}
+}
+
+const (
+	// DefaultRSABits is the size of the RSA keys when not configured
+	DefaultRSABits = 4096
+	// MinRSABits is the smallest RSA key size considered safe
+	MinRSABits = 2048
+)
+
+// KeyPair holds a private key and its public key in the PEM and OpenSSH formats.
+// The PEM private key is PKCS #8 and the PEM public key is PKIX.
+type KeyPair struct {
+	PrivateKeyPEM     string
+	PublicKeyPEM      string
+	PrivateKeyOpenSSH string
+	// PublicKeyOpenSSH is in the authorized_keys format, without the trailing new line
+	PublicKeyOpenSSH string
+	// FingerprintSHA256 is the fingerprint of the public key as shown by ssh-keygen
+	FingerprintSHA256 string
+}
+
+// GenerateKeyPair returns a new key pair. The rsaBits are used only for KeyAlgorithmRSA, 0 meaning DefaultRSABits.
+func GenerateKeyPair(alg KeyAlgorithm, rsaBits int) (*KeyPair, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch alg {
+	case KeyAlgorithmED25519:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case KeyAlgorithmRSA:
+		if rsaBits == 0 {
+			rsaBits = DefaultRSABits
+		}
+		if rsaBits < MinRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits, got %d", MinRSABits, rsaBits)
+		}
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	default:
+		return nil, fmt.Errorf("unknown key algorithm %q, expected one of: %s", alg, strings.Join(KeyAlgorithms(), ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not generate the %s key: %w", alg, err)
+	}
+	return encodeKeyPair(private)
+}
+
+func encodeKeyPair(private crypto.Signer) (*KeyPair, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the public key: %w", err)
+	}
+	opensshBlock, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the private key in the OpenSSH format: %w", err)
+	}
+	sshPublic, err := ssh.NewPublicKey(private.Public())
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the public key in the OpenSSH format: %w", err)
+	}
+	return &KeyPair{
+		PrivateKeyPEM:     string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		PublicKeyPEM:      string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		PrivateKeyOpenSSH: string(pem.EncodeToMemory(opensshBlock)),
+		PublicKeyOpenSSH:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublic))),
+		FingerprintSHA256: ssh.FingerprintSHA256(sshPublic),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// TokenAlgorithm is the HMAC algorithm signing the JWTs
+type TokenAlgorithm string
+
+const (
+	TokenAlgorithmHS256 TokenAlgorithm = "HS256"
+	TokenAlgorithmHS384 TokenAlgorithm = "HS384"
+	TokenAlgorithmHS512 TokenAlgorithm = "HS512"
+)
+
+// TokenAlgorithms returns all the supported token algorithms
+func TokenAlgorithms() []string {
+	return []string{string(TokenAlgorithmHS256), string(TokenAlgorithmHS384), string(TokenAlgorithmHS512)}
+}
+
+// MinTokenKeyLength is the smallest signing key accepted, in bytes, as required by RFC 7518 for HS256
+const MinTokenKeyLength = 32
+
+// SignToken returns the JWT made of the claims, signed with the key
+func SignToken(alg TokenAlgorithm, key []byte, claims map[string]any) (string, error) {
+	var h func() hash.Hash
+	switch alg {
+	case TokenAlgorithmHS256:
+		h = sha256.New
+	case TokenAlgorithmHS384:
+		h = sha512.New384
+	case TokenAlgorithmHS512:
+		h = sha512.New
+	default:
+		return "", fmt.Errorf("unknown token algorithm %q, expected one of: %s", alg, strings.Join(TokenAlgorithms(), ", "))
+	}
+	if len(key) < MinTokenKeyLength {
+		return "", fmt.Errorf("the signing key must have at least %d bytes, got %d", MinTokenKeyLength, len(key))
+	}
+	header, err := json.Marshal(map[string]string{"alg": string(alg), "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("could not encode the claims: %w", err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(h, key)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,53 @@
+package generator_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"terraform-provider-provs/internal/generator"
+	"testing"
+)
+
+func TestSignToken(t *testing.T) {
+	key := []byte(strings.Repeat("k", generator.MinTokenKeyLength))
+	token, err := generator.SignToken(generator.TokenAlgorithmHS256, key, map[string]any{"sub": "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %q", token)
+	}
+	var header map[string]string
+	if b, err := base64.RawURLEncoding.DecodeString(parts[0]); err != nil || json.Unmarshal(b, &header) != nil || header["alg"] != "HS256" {
+		t.Fatalf("unexpected header %q", parts[0])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if parts[2] != base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature %q", parts[2])
+	}
+
+	for name, tc := range map[string]struct {
+		alg generator.TokenAlgorithm
+		key []byte
+		err string
+	}{
+		"HS384":       {alg: generator.TokenAlgorithmHS384, key: key},
+		"HS512":       {alg: generator.TokenAlgorithmHS512, key: key},
+		"short key":   {alg: generator.TokenAlgorithmHS256, key: key[1:], err: "at least 32 bytes"},
+		"unknown alg": {alg: "RS256", key: key, err: `unknown token algorithm "RS256"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := generator.SignToken(tc.alg, tc.key, map[string]any{"sub": "ci"})
+			if tc.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("expected the error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,496 @@
+// Package importgen renders Terraform import blocks and resource stubs for the objects already existing in a store
+// so that they can be brought under management in one pass.
+package importgen
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// Kinds of objects that can be imported. These are the resource types of the provider, without its prefix.
+	KindCoffee              = "coffee"
+	KindCustomer            = "customer"
+	KindIngredient          = "ingredient"
+	KindInventory           = "inventory"
+	KindOrder               = "order"
+	KindPromotion           = "promotion"
+	KindSecret              = "secret"
+	KindSecretManager       = "secret_manager"
+	KindSecretManagerPolicy = "secret_manager_policy"
+	KindTaxRule             = "tax_rule"
+
+	// resTypeCoffees is the storage type of the coffees, the other kinds being stored under their own name or, for
+	// the secrets and the policies, in their secret manager
+	resTypeCoffees = "coffees"
+
+	providerTypeName = "provs"
+)
+
+// Kinds returns all the kinds supported by the Generator, in the order they are generated.
+func Kinds() []string {
+	return []string{
+		KindIngredient, KindCoffee, KindInventory, KindCustomer, KindPromotion, KindTaxRule,
+		KindSecretManager, KindSecretManagerPolicy, KindSecret, KindOrder,
+	}
+}
+
+// Generator builds the import configuration from the objects in a store.
+type Generator struct {
+	coffees        client.Client[*model.Coffee]
+	customers      client.Client[*model.Customer]
+	ingredients    client.Client[*model.Ingredient]
+	inventories    client.Client[*model.Inventory]
+	orders         client.Client[*model.Order]
+	promotions     client.Client[*model.Promotion]
+	secretManagers client.Client[*model.SecretManager]
+	taxRules       client.Client[*model.TaxRule]
+
+	// store is the name of the store in the provider configuration, empty for the default store
+	store string
+	// names keeps track of the resource names already used, per resource type
+	names map[string]map[string]bool
+	// addrs maps the key of an object, its ID but for the tax rules keyed by region, to the address of its generated
+	// resource, per kind
+	addrs map[string]map[string]hcl.Traversal
+}
+
+// NewGenerator returns a Generator of the objects of the backend, the store named store in the provider
+// configuration. The store is empty for the default store of the provider.
+func NewGenerator(backend client.BackendClient, store string) *Generator {
+	return &Generator{
+		coffees:        client.NewClient[*model.Coffee](backend, resTypeCoffees),
+		customers:      client.NewClient[*model.Customer](backend, KindCustomer),
+		ingredients:    client.NewClient[*model.Ingredient](backend, KindIngredient),
+		inventories:    client.NewClient[*model.Inventory](backend, KindInventory),
+		orders:         client.NewClient[*model.Order](backend, KindOrder),
+		promotions:     client.NewClient[*model.Promotion](backend, KindPromotion),
+		secretManagers: client.NewClient[*model.SecretManager](backend, KindSecretManager),
+		taxRules:       client.NewClient[*model.TaxRule](backend, KindTaxRule),
+		store:          store,
+		names:          map[string]map[string]bool{},
+		addrs:          map[string]map[string]hcl.Traversal{},
+	}
+}
+
+// Generate writes to w an import block and a resource stub for every object of the given kinds.
+// It fails on the kinds it does not know, before writing anything.
+func (g *Generator) Generate(w io.Writer, kinds []string) error {
+	wanted := map[string]bool{}
+	for _, k := range kinds {
+		if !slices.Contains(Kinds(), k) {
+			return fmt.Errorf("unsupported kind %q, expected one of: %s", k, strings.Join(Kinds(), ", "))
+		}
+		wanted[k] = true
+	}
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	// the objects reference the ones generated before them, so generate in the order of Kinds regardless the order
+	// requested
+	for _, kind := range Kinds() {
+		if !wanted[kind] {
+			continue
+		}
+		var err error
+		switch kind {
+		case KindCoffee:
+			err = g.coffeeBlocks(body)
+		case KindCustomer:
+			err = g.customerBlocks(body)
+		case KindIngredient:
+			err = g.ingredientBlocks(body)
+		case KindInventory:
+			err = g.inventoryBlocks(body)
+		case KindOrder:
+			err = g.orderBlocks(body)
+		case KindPromotion:
+			err = g.promotionBlocks(body)
+		case KindSecret:
+			err = g.secretBlocks(body)
+		case KindSecretManager:
+			err = g.secretManagerBlocks(body)
+		case KindSecretManagerPolicy:
+			err = g.secretManagerPolicyBlocks(body)
+		case KindTaxRule:
+			err = g.taxRuleBlocks(body)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate the import blocks for %q: %w", kind, err)
+		}
+	}
+	_, err := w.Write(f.Bytes())
+	return err
+}
+
+func (g *Generator) ingredientBlocks(body *hclwrite.Body) error {
+	ingredients, err := getAll(g.ingredients)
+	if err != nil {
+		return err
+	}
+	sort.Slice(ingredients, func(i, j int) bool { return ingredients[i].ID < ingredients[j].ID })
+	for _, ing := range ingredients {
+		res := g.resource(body, KindIngredient, ing.ID, ing.Name, ing.ID)
+		res.SetAttributeValue("name", cty.StringVal(ing.Name))
+		if ing.Unit != "" {
+			res.SetAttributeValue("unit", cty.StringVal(ing.Unit))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) coffeeBlocks(body *hclwrite.Body) error {
+	coffees, err := getAll(g.coffees)
+	if err != nil {
+		return err
+	}
+	sort.Slice(coffees, func(i, j int) bool { return coffees[i].ID < coffees[j].ID })
+	for _, c := range coffees {
+		res := g.resource(body, KindCoffee, c.ID, c.Name, c.ID)
+		res.SetAttributeValue("name", cty.StringVal(c.Name))
+		res.SetAttributeValue("price", cty.NumberFloatVal(c.Price))
+		for _, attr := range []struct{ name, value string }{
+			{"teaser", c.Teaser}, {"description", c.Description}, {"image", c.Image}, {"category", c.Category},
+		} {
+			if attr.value != "" {
+				res.SetAttributeValue(attr.name, cty.StringVal(attr.value))
+			}
+		}
+		if len(c.Ingredient) > 0 {
+			items := make([]hclwrite.Tokens, 0, len(c.Ingredient))
+			for _, ing := range c.Ingredient {
+				items = append(items, objectTokens([]string{"id", "quantity"}, map[string]hclwrite.Tokens{
+					"id":       g.refTokens(KindIngredient, ing.IngredientID, "id"),
+					"quantity": hclwrite.TokensForValue(cty.NumberIntVal(int64(ing.Quantity))),
+				}))
+			}
+			res.SetAttributeRaw("ingredients", hclwrite.TokensForTuple(items))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) inventoryBlocks(body *hclwrite.Body) error {
+	inventories, err := getAll(g.inventories)
+	if err != nil {
+		return err
+	}
+	sort.Slice(inventories, func(i, j int) bool { return inventories[i].ID < inventories[j].ID })
+	for _, inv := range inventories {
+		// the inventory of an ingredient has the ID of the ingredient
+		res := g.resource(body, KindInventory, inv.ID, inv.ID, inv.ID)
+		g.setRef(res, "ingredient_id", KindIngredient, inv.ID, "id")
+		res.SetAttributeValue("quantity", cty.NumberIntVal(int64(inv.Quantity)))
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) customerBlocks(body *hclwrite.Body) error {
+	customers, err := getAll(g.customers)
+	if err != nil {
+		return err
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
+	for _, c := range customers {
+		res := g.resource(body, KindCustomer, c.ID, c.Name, c.ID)
+		res.SetAttributeValue("name", cty.StringVal(c.Name))
+		res.SetAttributeValue("email", cty.StringVal(c.Email))
+		if c.LoyaltyTier != "" {
+			res.SetAttributeValue("loyalty_tier", cty.StringVal(string(c.LoyaltyTier)))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) promotionBlocks(body *hclwrite.Body) error {
+	promotions, err := getAll(g.promotions)
+	if err != nil {
+		return err
+	}
+	sort.Slice(promotions, func(i, j int) bool { return promotions[i].Code < promotions[j].Code })
+	for _, p := range promotions {
+		res := g.resource(body, KindPromotion, p.ID, p.Code, p.ID)
+		res.SetAttributeValue("code", cty.StringVal(p.Code))
+		res.SetAttributeValue("kind", cty.StringVal(string(p.Kind)))
+		res.SetAttributeValue("value", cty.NumberFloatVal(p.Value))
+		if len(p.CoffeeIDs) > 0 {
+			ids := make([]hclwrite.Tokens, 0, len(p.CoffeeIDs))
+			for _, id := range p.CoffeeIDs {
+				ids = append(ids, g.refTokens(KindCoffee, id, "id"))
+			}
+			res.SetAttributeRaw("coffee_ids", hclwrite.TokensForTuple(ids))
+		}
+		if len(p.Categories) > 0 {
+			res.SetAttributeValue("categories", stringList(p.Categories))
+		}
+		if p.StartsAt != nil {
+			res.SetAttributeValue("starts_at", cty.StringVal(p.StartsAt.Format(time.RFC3339Nano)))
+		}
+		if p.EndsAt != nil {
+			res.SetAttributeValue("ends_at", cty.StringVal(p.EndsAt.Format(time.RFC3339Nano)))
+		}
+		if p.MaxRedemptions > 0 {
+			res.SetAttributeValue("max_redemptions", cty.NumberIntVal(int64(p.MaxRedemptions)))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) taxRuleBlocks(body *hclwrite.Body) error {
+	rules, err := getAll(g.taxRules)
+	if err != nil {
+		return err
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	for _, rule := range rules {
+		// the tax rule of a region has the region for ID
+		res := g.resource(body, KindTaxRule, rule.ID, rule.ID, rule.ID)
+		res.SetAttributeValue("region", cty.StringVal(rule.ID))
+		res.SetAttributeValue("rate", cty.NumberFloatVal(rule.Rate))
+		if rule.Inclusive {
+			res.SetAttributeValue("inclusive", cty.True)
+		}
+		if len(rule.ExemptCategories) > 0 {
+			res.SetAttributeValue("exempt_categories", stringList(rule.ExemptCategories))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) secretManagerBlocks(body *hclwrite.Body) error {
+	mgrs, err := g.sortedSecretManagers()
+	if err != nil {
+		return err
+	}
+	for _, mgr := range mgrs {
+		res := g.resource(body, KindSecretManager, mgr.ID, mgr.Name, mgr.ID)
+		res.SetAttributeValue("name", cty.StringVal(mgr.Name))
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) secretManagerPolicyBlocks(body *hclwrite.Body) error {
+	mgrs, err := g.sortedSecretManagers()
+	if err != nil {
+		return err
+	}
+	for _, mgr := range mgrs {
+		if mgr.Policy == nil {
+			continue
+		}
+		// the policy of a secret manager is imported by the ID of the secret manager
+		res := g.resource(body, KindSecretManagerPolicy, mgr.ID, mgr.Name, mgr.ID)
+		g.setRef(res, "secret_manager_id", KindSecretManager, mgr.ID, "id")
+		grants := make([]cty.Value, 0, len(mgr.Policy.Grants))
+		for _, grant := range mgr.Policy.Grants {
+			grants = append(grants, cty.ObjectVal(map[string]cty.Value{
+				"principals":   stringList(grant.Principals),
+				"access":       cty.StringVal(string(grant.Access)),
+				"secret_names": stringList(grant.SecretNames),
+			}))
+		}
+		if len(grants) == 0 {
+			res.SetAttributeValue("grants", cty.ListValEmpty(cty.DynamicPseudoType))
+		} else {
+			res.SetAttributeValue("grants", cty.TupleVal(grants))
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+func (g *Generator) secretBlocks(body *hclwrite.Body) error {
+	mgrs, err := g.sortedSecretManagers()
+	if err != nil {
+		return err
+	}
+	for _, mgr := range mgrs {
+		secretNames := make([]string, 0, len(mgr.Secrets))
+		for name := range mgr.Secrets {
+			secretNames = append(secretNames, name)
+		}
+		sort.Strings(secretNames)
+		for _, name := range secretNames {
+			res := g.resource(body, KindSecret, mgr.ID+"/"+name, mgr.Name+"_"+name, mgr.ID+"/"+name)
+			g.setRef(res, "secret_manager_id", KindSecretManager, mgr.ID, "id")
+			res.SetAttributeValue("secret_name", cty.StringVal(name))
+			// Secret values are never written into the generated configuration
+			if mgr.Secrets[name].WriteOnly {
+				appendComment(res, "Provide the value through secret_wo and bump secret_wo_version to write it")
+				res.SetAttributeValue("secret_wo_version", cty.NumberIntVal(1))
+			} else {
+				appendComment(res, "Provide the value through secret or switch to secret_wo and secret_wo_version")
+			}
+			body.AppendNewline()
+		}
+	}
+	return nil
+}
+
+func (g *Generator) orderBlocks(body *hclwrite.Body) error {
+	orders, err := getAll(g.orders)
+	if err != nil {
+		return err
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	for _, o := range orders {
+		res := g.resource(body, KindOrder, o.ID, "order_"+o.ID, o.ID)
+		items := make([]hclwrite.Tokens, 0, len(o.Items))
+		for _, item := range o.Items {
+			items = append(items, objectTokens([]string{"coffee", "quantity"}, map[string]hclwrite.Tokens{
+				"coffee":   objectTokens([]string{"id"}, map[string]hclwrite.Tokens{"id": g.refTokens(KindCoffee, item.Coffee.ID, "id")}),
+				"quantity": hclwrite.TokensForValue(cty.NumberIntVal(int64(item.Quantity))),
+			}))
+		}
+		res.SetAttributeRaw("items", hclwrite.TokensForTuple(items))
+		if o.CustomerID != "" {
+			g.setRef(res, "customer_id", KindCustomer, o.CustomerID, "id")
+		}
+		if o.PromoCode != "" {
+			res.SetAttributeValue("promo_code", cty.StringVal(o.PromoCode))
+		}
+		if o.Region != "" {
+			g.setRef(res, "region", KindTaxRule, o.Region, "region")
+		}
+		body.AppendNewline()
+	}
+	return nil
+}
+
+// sortedSecretManagers returns the secret managers ordered by name
+func (g *Generator) sortedSecretManagers() ([]*model.SecretManager, error) {
+	mgrs, err := getAll(g.secretManagers)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mgrs, func(i, j int) bool { return mgrs[i].Name < mgrs[j].Name })
+	return mgrs, nil
+}
+
+// resource appends the import block and the stub of a new resource of the given kind, named after hint, and returns
+// the body of the stub. The address of the resource is kept under key for the resources referencing it.
+func (g *Generator) resource(body *hclwrite.Body, kind string, key string, hint string, id string) *hclwrite.Body {
+	addr := g.address(kind, hint)
+	if g.addrs[kind] == nil {
+		g.addrs[kind] = map[string]hcl.Traversal{}
+	}
+	g.addrs[kind][key] = addr
+	importID := id
+	if g.store != "" {
+		// the objects of a named store are imported by <store>/<id>
+		importID = g.store + "/" + id
+	}
+	appendImport(body, addr, importID)
+	res := body.AppendNewBlock("resource", []string{addr.RootName(), addr[1].(hcl.TraverseAttr).Name}).Body()
+	if g.store != "" {
+		res.SetAttributeValue("store", cty.StringVal(g.store))
+	}
+	return res
+}
+
+// setRef sets the attribute of the stub to the attribute field of the resource generated for the object of the given
+// kind and key, or to the key itself when the object was not generated.
+func (g *Generator) setRef(res *hclwrite.Body, attr string, kind string, key string, field string) {
+	res.SetAttributeRaw(attr, g.refTokens(kind, key, field))
+}
+
+// refTokens returns the tokens of the reference set by setRef
+func (g *Generator) refTokens(kind string, key string, field string) hclwrite.Tokens {
+	if addr, ok := g.addrs[kind][key]; ok {
+		return hclwrite.TokensForTraversal(append(slices.Clone(addr), hcl.TraverseAttr{Name: field}))
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
+
+// address returns the address of a new resource of the given kind, with a unique name derived from hint.
+func (g *Generator) address(kind string, hint string) hcl.Traversal {
+	resType := providerTypeName + "_" + kind
+	if g.names[resType] == nil {
+		g.names[resType] = map[string]bool{}
+	}
+	base := resourceName(hint)
+	name := base
+	for i := 2; g.names[resType][name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.names[resType][name] = true
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: resType},
+		hcl.TraverseAttr{Name: name},
+	}
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// resourceName turns any string into a valid resource name.
+func resourceName(hint string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(hint), "_"), "_")
+	if name == "" || !hclsyntax.ValidIdentifier(name) {
+		name = "r_" + name
+	}
+	return name
+}
+
+func appendImport(body *hclwrite.Body, addr hcl.Traversal, id string) {
+	imp := body.AppendNewBlock("import", nil).Body()
+	imp.SetAttributeTraversal("to", addr)
+	imp.SetAttributeValue("id", cty.StringVal(id))
+	body.AppendNewline()
+}
+
+func appendComment(body *hclwrite.Body, comment string) {
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte("# " + comment + "\n")},
+	})
+}
+
+// objectTokens returns the tokens of an object with the given attributes, in the order of names
+func objectTokens(names []string, attrs map[string]hclwrite.Tokens) hclwrite.Tokens {
+	res := make([]hclwrite.ObjectAttrTokens, 0, len(names))
+	for _, name := range names {
+		res = append(res, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: attrs[name],
+		})
+	}
+	return hclwrite.TokensForObject(res)
+}
+
+func stringList(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	res := make([]cty.Value, 0, len(values))
+	for _, v := range values {
+		res = append(res, cty.StringVal(v))
+	}
+	return cty.ListVal(res)
+}
+
+// getAll returns all the objects of a type, treating a type that was never written as empty.
+func getAll[T model.IDer](c client.Client[T]) ([]T, error) {
+	all, err := c.GetAll()
+	if client.IsNotFound(err) {
+		return nil, nil
+	}
+	return all, err
+}
@@ -0,0 +1,269 @@
+package importgen_test
+
+import (
+	"bytes"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/importgen"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+	backend := testBackend(t)
+	var buf bytes.Buffer
+	if err := importgen.NewGenerator(backend, "").Generate(&buf, importgen.Kinds()); err != nil {
+		t.Fatal(err)
+	}
+	testValidConfig(t, buf.Bytes())
+	if got := buf.String(); got != testGenerated {
+		t.Fatalf("unexpected configuration:\n%s", got)
+	}
+}
+
+func TestGenerator_Generate_kinds(t *testing.T) {
+	backend := testBackend(t)
+	var buf bytes.Buffer
+	// the secret manager is referenced by its ID when it is not generated
+	if err := importgen.NewGenerator(backend, "").Generate(&buf, []string{importgen.KindSecret}); err != nil {
+		t.Fatal(err)
+	}
+	testValidConfig(t, buf.Bytes())
+	if got := buf.String(); strings.Contains(got, "provs_secret_manager") || !strings.Contains(got, `secret_manager_id = "m1"`) {
+		t.Fatalf("expected the secrets only, referencing the secret manager ID, got:\n%s", got)
+	}
+}
+
+func TestGenerator_Generate_store(t *testing.T) {
+	backend := testBackend(t)
+	var buf bytes.Buffer
+	if err := importgen.NewGenerator(backend, "shared").Generate(&buf, []string{importgen.KindSecretManager, importgen.KindSecret}); err != nil {
+		t.Fatal(err)
+	}
+	testValidConfig(t, buf.Bytes())
+	got := buf.String()
+	for _, want := range []string{`id = "shared/m1"`, `id = "shared/m1/db_password"`, `store = "shared"`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerator_Generate_unsupportedKind(t *testing.T) {
+	backend := testBackend(t)
+	var buf bytes.Buffer
+	err := importgen.NewGenerator(backend, "").Generate(&buf, []string{importgen.KindOrder, "issue2372"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported kind "issue2372"`) {
+		t.Fatalf("expected the kind refused, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing written, got:\n%s", buf.String())
+	}
+}
+
+func TestGenerator_Generate_empty(t *testing.T) {
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := importgen.NewGenerator(backend, "").Generate(&buf, importgen.Kinds()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing generated, got:\n%s", buf.String())
+	}
+}
+
+// testBackend returns a store with an object of every kind
+func testBackend(t *testing.T) client.BackendClient {
+	t.Helper()
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	starts := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mgr := &model.SecretManager{
+		ID:   "m1",
+		Name: "app",
+		Policy: &model.SecretManagerPolicy{Grants: []model.SecretGrant{
+			{Principals: []string{"ci"}, Access: model.SecretAccessRead, SecretNames: []string{"db_*"}},
+		}},
+	}
+	mgr.PutSecret("db_password", "s3cr3t", false, starts)
+	mgr.PutSecret("api-key", "s3cr3t", true, starts)
+	for resType, objects := range map[string][]model.IDer{
+		"ingredient": {&model.Ingredient{ID: "beans", Name: "Coffee beans", Unit: "g"}},
+		"coffees": {&model.Coffee{
+			ID: "1", Name: "Espresso", Price: 2.5, Category: "espresso",
+			Ingredient: []model.CoffeeIngredient{{IngredientID: "beans", Quantity: 7}},
+		}},
+		"inventory": {&model.Inventory{ID: "beans", Quantity: 100}},
+		"customer":  {&model.Customer{ID: "c1", Name: "Ada", Email: "ada@example.com", LoyaltyTier: model.LoyaltyTierSilver}},
+		"promotion": {&model.Promotion{ID: "p1", Code: "WELCOME", Kind: model.DiscountKindPercentage, Value: 10, StartsAt: &starts, CoffeeIDs: []string{"1"}}},
+		"tax_rule":  {&model.TaxRule{ID: "US-CA", Rate: 0.0725}},
+		"order": {&model.Order{
+			ID:         "o1",
+			Items:      []model.OrderItem{{Coffee: model.Coffee{ID: "1"}, Quantity: 2}},
+			CustomerID: "c1",
+			PromoCode:  "WELCOME",
+			Region:     "US-CA",
+		}},
+		"secret_manager": {mgr},
+	} {
+		for _, o := range objects {
+			if _, err := client.NewClient[model.IDer](backend, resType).Create(o); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return backend
+}
+
+// testValidConfig fails the test unless the configuration parses
+func testValidConfig(t *testing.T, config []byte) {
+	t.Helper()
+	if _, diags := hclwrite.ParseConfig(config, "imports.tf", hcl.InitialPos); diags.HasErrors() {
+		t.Fatalf("invalid configuration: %s\n%s", diags, config)
+	}
+}
+
+// testGenerated is the configuration generated for the objects of testBackend
+const testGenerated = `import {
+  to = provs_ingredient.coffee_beans
+  id = "beans"
+}
+
+resource "provs_ingredient" "coffee_beans" {
+  name = "Coffee beans"
+  unit = "g"
+}
+
+import {
+  to = provs_coffee.espresso
+  id = "1"
+}
+
+resource "provs_coffee" "espresso" {
+  name     = "Espresso"
+  price    = 2.5
+  category = "espresso"
+  ingredients = [{
+    id       = provs_ingredient.coffee_beans.id
+    quantity = 7
+  }]
+}
+
+import {
+  to = provs_inventory.beans
+  id = "beans"
+}
+
+resource "provs_inventory" "beans" {
+  ingredient_id = provs_ingredient.coffee_beans.id
+  quantity      = 100
+}
+
+import {
+  to = provs_customer.ada
+  id = "c1"
+}
+
+resource "provs_customer" "ada" {
+  name         = "Ada"
+  email        = "ada@example.com"
+  loyalty_tier = "silver"
+}
+
+import {
+  to = provs_promotion.welcome
+  id = "p1"
+}
+
+resource "provs_promotion" "welcome" {
+  code       = "WELCOME"
+  kind       = "percentage"
+  value      = 10
+  coffee_ids = [provs_coffee.espresso.id]
+  starts_at  = "2025-06-01T00:00:00Z"
+}
+
+import {
+  to = provs_tax_rule.us_ca
+  id = "US-CA"
+}
+
+resource "provs_tax_rule" "us_ca" {
+  region = "US-CA"
+  rate   = 0.0725
+}
+
+import {
+  to = provs_secret_manager.app
+  id = "m1"
+}
+
+resource "provs_secret_manager" "app" {
+  name = "app"
+}
+
+import {
+  to = provs_secret_manager_policy.app
+  id = "m1"
+}
+
+resource "provs_secret_manager_policy" "app" {
+  secret_manager_id = provs_secret_manager.app.id
+  grants = [{
+    access       = "read"
+    principals   = ["ci"]
+    secret_names = ["db_*"]
+  }]
+}
+
+import {
+  to = provs_secret.app_api_key
+  id = "m1/api-key"
+}
+
+resource "provs_secret" "app_api_key" {
+  secret_manager_id = provs_secret_manager.app.id
+  secret_name       = "api-key"
+  # Provide the value through secret_wo and bump secret_wo_version to write it
+  secret_wo_version = 1
+}
+
+import {
+  to = provs_secret.app_db_password
+  id = "m1/db_password"
+}
+
+resource "provs_secret" "app_db_password" {
+  secret_manager_id = provs_secret_manager.app.id
+  secret_name       = "db_password"
+  # Provide the value through secret or switch to secret_wo and secret_wo_version
+}
+
+import {
+  to = provs_order.order_o1
+  id = "o1"
+}
+
+resource "provs_order" "order_o1" {
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = 2
+  }]
+  customer_id = provs_customer.ada.id
+  promo_code  = "WELCOME"
+  region      = provs_tax_rule.us_ca.region
+}
+
+`
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// AuditOperation is the kind of access recorded by an AuditEntry
+type AuditOperation string
+
+const (
+	AuditOperationCreate AuditOperation = "create"
+	AuditOperationRead   AuditOperation = "read"
+	AuditOperationUpdate AuditOperation = "update"
+	AuditOperationDelete AuditOperation = "delete"
+	AuditOperationImport AuditOperation = "import"
+	// the operations on the policy of a secret manager, recorded without secret name
+	AuditOperationCreatePolicy AuditOperation = "create_policy"
+	AuditOperationUpdatePolicy AuditOperation = "update_policy"
+	AuditOperationDeletePolicy AuditOperation = "delete_policy"
+)
+
+// AuditOutcome is the result of a recorded access. The accesses performed have no outcome, like all the entries
+// recorded before the outcomes were.
+type AuditOutcome string
+
+const (
+	// AuditOutcomeDenied is the outcome of the accesses refused by the policy of the secret manager
+	AuditOutcomeDenied AuditOutcome = "denied"
+	// AuditOutcomeFailed is the outcome of the accesses allowed by the policy that failed, like the reads of a
+	// missing secret
+	AuditOutcomeFailed AuditOutcome = "failed"
+)
+
+// AuditEntry records one access to a secret, or to the secret manager itself when SecretName is empty. It never
+// contains the value of the secret.
+// The entries are chained: each one holds the hash of the previous entry, next to its own hash.
+type AuditEntry struct {
+	// ID is the Seq, zero padded so that the IDs sort in the order of the entries
+	ID              string         `json:"id"`
+	Seq             int64          `json:"seq"`
+	Timestamp       time.Time      `json:"timestamp"`
+	Principal       string         `json:"principal"`
+	SecretManagerID string         `json:"secret_manager_id"`
+	SecretName      string         `json:"secret_name"`
+	Operation       AuditOperation `json:"operation"`
+	Outcome         AuditOutcome   `json:"outcome,omitempty"`
+	PrevHash        string         `json:"prev_hash"`
+	Hash            string         `json:"hash"`
+}
+
+func (e *AuditEntry) GetID() string {
+	return e.ID
+}
+
+func (e *AuditEntry) SetID(id string) {
+	e.ID = id
+}
+
+// AuditHead points to an entry of the audit log, allowing to detect the removal of the newest entries. A head is
+// appended with every entry, the newest head pointing to the last entry.
+type AuditHead struct {
+	ID   string `json:"id"`
+	Seq  int64  `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+func (h *AuditHead) GetID() string {
+	return h.ID
+}
+
+func (h *AuditHead) SetID(id string) {
+	h.ID = id
+}
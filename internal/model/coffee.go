@@ -1,13 +1,14 @@
 package model
 
 type Coffee struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	Teaser      string       `json:"teaser"`
-	Description string       `json:"description"`
-	Price       float64      `json:"price"`
-	Image       string       `json:"image"`
-	Ingredient  []Ingredient `json:"ingredients"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Teaser      string             `json:"teaser"`
+	Description string             `json:"description"`
+	Price       float64            `json:"price"`
+	Image       string             `json:"image"`
+	Category    string             `json:"category,omitempty"`
+	Ingredient  []CoffeeIngredient `json:"ingredients"`
 }
 
 func (c *Coffee) GetID() string {
@@ -18,11 +19,31 @@ func (c *Coffee) SetID(id string) {
 	c.ID = id
 }
 
+// UsesIngredient reports whether the coffee is made with the ingredient
+func (c *Coffee) UsesIngredient(id string) bool {
+	for _, i := range c.Ingredient {
+		if i.IngredientID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// CoffeeIngredient references an Ingredient with the quantity a coffee is made with.
+type CoffeeIngredient struct {
+	IngredientID string `json:"ingredient_id"`
+	Quantity     int    `json:"quantity"`
+	// Name and Unit are kept inline by the coffees stored before the ingredients were shared. They describe the
+	// ingredient as long as there is no Ingredient with the ID.
+	Name string `json:"name,omitempty"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// Ingredient is shared by all the coffees made with it. It cannot be deleted while a coffee uses it.
 type Ingredient struct {
-	ID       string `json:"ingredient_id"`
-	Name     string `json:"name"`
-	Quantity int    `json:"quantity"`
-	Unit     string `json:"unit"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Unit string `json:"unit"`
 }
 
 func (c *Ingredient) GetID() string {
@@ -0,0 +1,19 @@
+package model_test
+
+import (
+	"encoding/json"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestCoffee_legacyIngredients(t *testing.T) {
+	var coffee model.Coffee
+	// stored before the ingredients were shared
+	if err := json.Unmarshal([]byte(`{"id":"1","name":"Latte","ingredients":[{"ingredient_id":"1","name":"Milk","quantity":200,"unit":"ml"}]}`), &coffee); err != nil {
+		t.Fatal(err)
+	}
+	want := model.CoffeeIngredient{IngredientID: "1", Quantity: 200, Name: "Milk", Unit: "ml"}
+	if len(coffee.Ingredient) != 1 || coffee.Ingredient[0] != want {
+		t.Fatalf("expected the ingredients %v, got %v", []model.CoffeeIngredient{want}, coffee.Ingredient)
+	}
+}
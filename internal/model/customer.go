@@ -0,0 +1,45 @@
+package model
+
+// LoyaltyTier grants a discount on the orders of the customers
+type LoyaltyTier string
+
+const (
+	LoyaltyTierNone   LoyaltyTier = "none"
+	LoyaltyTierBronze LoyaltyTier = "bronze"
+	LoyaltyTierSilver LoyaltyTier = "silver"
+	LoyaltyTierGold   LoyaltyTier = "gold"
+)
+
+// loyaltyDiscountRates are the rates of the discounts granted by the tiers
+var loyaltyDiscountRates = map[LoyaltyTier]float64{
+	LoyaltyTierNone:   0,
+	LoyaltyTierBronze: 0.05,
+	LoyaltyTierSilver: 0.10,
+	LoyaltyTierGold:   0.15,
+}
+
+// LoyaltyTiers returns the tiers, from the lowest to the highest
+func LoyaltyTiers() []string {
+	return []string{string(LoyaltyTierNone), string(LoyaltyTierBronze), string(LoyaltyTierSilver), string(LoyaltyTierGold)}
+}
+
+// DiscountRate returns the part of the orders discounted, between 0 and 1. The unknown tiers grant no discount.
+func (t LoyaltyTier) DiscountRate() float64 {
+	return loyaltyDiscountRates[t]
+}
+
+// Customer places orders, discounted according to the loyalty tier of the customer.
+type Customer struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Email       string      `json:"email"`
+	LoyaltyTier LoyaltyTier `json:"loyalty_tier"`
+}
+
+func (c *Customer) GetID() string {
+	return c.ID
+}
+
+func (c *Customer) SetID(id string) {
+	c.ID = id
+}
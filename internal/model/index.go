@@ -0,0 +1,17 @@
+package model
+
+// IndexEntry maps a unique name to the ID of the object that owns it.
+type IndexEntry struct {
+	// ID is the encoded name, safe to be used as a storage key.
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+}
+
+func (e *IndexEntry) GetID() string {
+	return e.ID
+}
+
+func (e *IndexEntry) SetID(id string) {
+	e.ID = id
+}
@@ -0,0 +1,33 @@
+package model
+
+// Inventory is the stock of an Ingredient, identified by the ID of the ingredient.
+// The orders reserve the quantity of the ingredient their coffees are made with, until they are deleted.
+type Inventory struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
+	// Reservations are the quantities reserved by the orders, by order ID
+	Reservations map[string]int `json:"reservations,omitempty"`
+}
+
+func (i *Inventory) GetID() string {
+	return i.ID
+}
+
+func (i *Inventory) SetID(id string) {
+	i.ID = id
+}
+
+// Reserved returns the quantity reserved by all the orders
+func (i *Inventory) Reserved() int {
+	res := 0
+	for _, q := range i.Reservations {
+		res += q
+	}
+	return res
+}
+
+// Available returns the quantity not reserved by any order. It is negative when the stock was lowered below
+// the quantity reserved.
+func (i *Inventory) Available() int {
+	return i.Quantity - i.Reserved()
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+const (
+	// MaxMoneyAmount is the largest amount handled, far enough from the precision of the float64 for the amounts to
+	// keep their minor units exactly
+	MaxMoneyAmount = 1e12
+
+	// MaxSplitShares is the largest number of shares an amount is split in
+	MaxSplitShares = 1000
+)
+
+// currencyDecimals are the minor units of the ISO 4217 currencies that are not divided in cents
+var currencyDecimals = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// CurrencyDecimals returns the number of decimals of the amounts in the currency, 2 unless known otherwise
+func CurrencyDecimals(currency string) int {
+	if d, ok := currencyDecimals[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// RoundMoney rounds the amount to the minor unit of the currency, half away from zero
+func RoundMoney(amount float64, currency string) float64 {
+	return roundTo(amount, CurrencyDecimals(currency))
+}
+
+// roundTo rounds the amount to the given number of decimals, half away from zero
+func roundTo(amount float64, decimals int) float64 {
+	units, ok := roundDecimal(amount, decimals)
+	if !ok {
+		return amount
+	}
+	res, _ := new(big.Rat).SetFrac(units, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)).Float64()
+	return res
+}
+
+// SplitCents splits the amount in n shares, rounded to the cent, that add up to the amount once rounded.
+// The first shares take the cents that cannot be split evenly.
+// The amount must be between 0 and MaxMoneyAmount, and n between 1 and MaxSplitShares.
+func SplitCents(amount float64, n int) ([]float64, error) {
+	if n < 1 || n > MaxSplitShares {
+		return nil, fmt.Errorf("the number of shares must be between 1 and %d, got %d", MaxSplitShares, n)
+	}
+	if amount < 0 || amount > MaxMoneyAmount || math.IsNaN(amount) {
+		return nil, fmt.Errorf("the amount must be between 0 and %v, got %v", MaxMoneyAmount, amount)
+	}
+	units, _ := roundDecimal(amount, 2)
+	cents := units.Int64()
+	base, rest := cents/int64(n), cents%int64(n)
+	res := make([]float64, n)
+	for i := range res {
+		share := base
+		if int64(i) < rest {
+			share++
+		}
+		res[i] = float64(share) / 100
+	}
+	return res, nil
+}
+
+// allocateCents splits the amount, rounded to the cent, in shares proportional to the non-negative weights, rounded to
+// the cent, that add up to the amount once rounded. The cents left over by the rounding go to the shares with the
+// largest remainders, the first ones on a tie. The shares are all 0 when the weights are.
+func allocateCents(amount float64, weights []float64) []float64 {
+	res := make([]float64, len(weights))
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	units, ok := roundDecimal(amount, 2)
+	if !ok || total <= 0 {
+		return res
+	}
+	cents := units.Int64()
+	shares := make([]int64, len(weights))
+	remainders := make([]float64, len(weights))
+	left := cents
+	for i, w := range weights {
+		exact := float64(cents) * w / total
+		shares[i] = int64(math.Floor(exact))
+		remainders[i] = exact - float64(shares[i])
+		left -= shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; left > 0; i++ {
+		if j := order[i%len(order)]; weights[j] > 0 {
+			shares[j]++
+			left--
+		}
+	}
+	for i, share := range shares {
+		res[i] = float64(share) / 100
+	}
+	return res
+}
+
+// roundDecimal returns the amount in units of 10^-decimals, rounded half away from zero.
+// The amount is rounded as written in decimal, 1.005 being rounded up to 1.01 even though the float64 closest to it
+// is slightly below. It returns false for the infinities and NaN.
+func roundDecimal(amount float64, decimals int) (*big.Int, bool) {
+	if math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return nil, false
+	}
+	// the shortest decimal representation of the amount, that parses back to it
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(math.Abs(amount), 'g', -1, 64))
+	if !ok {
+		return nil, false
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	r.Add(r, big.NewRat(1, 2))
+	units := new(big.Int).Quo(r.Num(), r.Denom())
+	if amount < 0 {
+		units.Neg(units)
+	}
+	return units, true
+}
@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"fmt"
+	"math"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestRoundMoney(t *testing.T) {
+	for _, tc := range []struct {
+		amount   float64
+		currency string
+		want     float64
+	}{
+		{amount: 1.005, currency: "EUR", want: 1.01},
+		{amount: 1.004, currency: "EUR", want: 1},
+		{amount: 0.1 + 0.2, currency: "EUR", want: 0.3},
+		{amount: 2.5, currency: "JPY", want: 3},
+		{amount: 1.0005, currency: "KWD", want: 1.001},
+		{amount: -1.005, currency: "EUR", want: -1.01},
+		{amount: 1e20, currency: "EUR", want: 1e20},
+	} {
+		if got := model.RoundMoney(tc.amount, tc.currency); got != tc.want {
+			t.Errorf("RoundMoney(%v, %s): expected %v, got %v", tc.amount, tc.currency, tc.want, got)
+		}
+	}
+	if got := model.RoundMoney(math.Inf(1), "EUR"); !math.IsInf(got, 1) {
+		t.Errorf("expected the infinity kept, got %v", got)
+	}
+}
+
+func TestSplitCents(t *testing.T) {
+	shares, err := model.SplitCents(10.005, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(shares) != "[3.34 3.34 3.33]" {
+		t.Fatalf("expected the 10.01 split, got %v", shares)
+	}
+
+	for _, tc := range []struct {
+		amount float64
+		n      int
+	}{
+		{amount: 10, n: 0},
+		{amount: 10, n: model.MaxSplitShares + 1},
+		{amount: -1, n: 2},
+		{amount: model.MaxMoneyAmount * 10, n: 2},
+		{amount: math.NaN(), n: 2},
+	} {
+		if _, err := model.SplitCents(tc.amount, tc.n); err == nil {
+			t.Errorf("SplitCents(%v, %d): expected an error", tc.amount, tc.n)
+		}
+	}
+}
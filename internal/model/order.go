@@ -1,8 +1,30 @@
 package model
 
+import (
+	"math"
+	"time"
+)
+
 type Order struct {
 	ID    string      `json:"id,omitempty"`
 	Items []OrderItem `json:"items,omitempty"`
+	// CustomerID is the Customer placing the order, empty for the orders without customer
+	CustomerID string `json:"customer_id,omitempty"`
+	// LoyaltyTier is the tier of the customer when the order was placed or last updated
+	LoyaltyTier LoyaltyTier `json:"loyalty_tier,omitempty"`
+	// PromoCode is the code of the Promotion used by the order, empty for the orders without promotion
+	PromoCode string `json:"promo_code,omitempty"`
+	// Region is the region of the TaxRule taxing the order, empty for the orders without tax
+	Region   string    `json:"region,omitempty"`
+	PlacedAt time.Time `json:"placed_at,omitempty"`
+	Subtotal float64   `json:"subtotal"`
+	// PromotionDiscount is the part of the Discount granted by the promotion
+	PromotionDiscount float64 `json:"promotion_discount,omitempty"`
+	Discount          float64 `json:"discount"`
+	// Tax is the sum of the taxes of the TaxLines, one per item
+	Tax      float64   `json:"tax,omitempty"`
+	TaxLines []TaxLine `json:"tax_lines,omitempty"`
+	Total    float64   `json:"total"`
 }
 
 func (o *Order) GetID() string {
@@ -13,7 +35,76 @@ func (o *Order) SetID(id string) {
 	o.ID = id
 }
 
+// ComputeTotal sets the subtotal of the items, at the price of their coffees, and the total once discounted and
+// taxed. The promotion, unless nil, is applied first, then the discount of the loyalty tier on what remains.
+// The tax rule, unless nil, taxes what remains of the items, and adds the tax to the total unless the prices include
+// it. The amounts are rounded to the cent, the ones of the tax lines adding up to the total and to the tax.
+func (o *Order) ComputeTotal(promotion *Promotion, taxRule *TaxRule) {
+	subtotal := 0.0
+	for _, item := range o.Items {
+		subtotal += item.Coffee.Price * float64(item.Quantity)
+	}
+	o.Subtotal = RoundCents(subtotal)
+	o.PromotionDiscount = 0
+	eligible := 0.0
+	if promotion != nil {
+		eligible = promotion.Eligible(o.Items)
+		o.PromotionDiscount = promotion.Kind.Discount(promotion.Value, eligible)
+	}
+	loyaltyRate := o.LoyaltyTier.DiscountRate()
+	loyaltyDiscount := RoundCents((o.Subtotal - o.PromotionDiscount) * loyaltyRate)
+	o.Discount = RoundCents(o.PromotionDiscount + loyaltyDiscount)
+	o.Total = RoundCents(o.Subtotal - o.Discount)
+
+	o.Tax = 0
+	o.TaxLines = nil
+	if taxRule == nil {
+		return
+	}
+	// the amounts and the taxes of the lines are spread to the cent, so that they add up to the total and the tax
+	amounts := make([]float64, len(o.Items))
+	for i, item := range o.Items {
+		amount := item.Coffee.Price * float64(item.Quantity)
+		if promotion != nil && eligible > 0 && promotion.Applies(&item.Coffee) {
+			amount -= o.PromotionDiscount * amount / eligible
+		}
+		amounts[i] = math.Max(amount*(1-loyaltyRate), 0)
+	}
+	amounts = allocateCents(o.Total, amounts)
+	taxed := make([]float64, len(o.Items))
+	taxable := 0.0
+	for i, item := range o.Items {
+		if !taxRule.Exempts(&item.Coffee) {
+			taxed[i] = amounts[i]
+			taxable += amounts[i]
+		}
+	}
+	o.Tax = taxRule.Tax(taxable)
+	taxes := allocateCents(o.Tax, taxed)
+	for i, item := range o.Items {
+		line := TaxLine{
+			CoffeeID: item.Coffee.ID,
+			Category: item.Coffee.Category,
+			Amount:   amounts[i],
+			Exempt:   taxRule.Exempts(&item.Coffee),
+		}
+		if !line.Exempt {
+			line.Rate = taxRule.Rate
+			line.Tax = taxes[i]
+		}
+		o.TaxLines = append(o.TaxLines, line)
+	}
+	if !taxRule.Inclusive {
+		o.Total = RoundCents(o.Total + o.Tax)
+	}
+}
+
 type OrderItem struct {
 	Coffee   Coffee `json:"coffee"`
 	Quantity int    `json:"quantity"`
 }
+
+// RoundCents rounds the amount to the cent, half away from zero as RoundMoney does
+func RoundCents(amount float64) float64 {
+	return roundTo(amount, 2)
+}
@@ -0,0 +1,75 @@
+package model_test
+
+import (
+	"fmt"
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestOrder_ComputeTotal(t *testing.T) {
+	order := func() *model.Order {
+		return &model.Order{
+			LoyaltyTier: model.LoyaltyTierSilver,
+			Items: []model.OrderItem{
+				{Coffee: model.Coffee{ID: "espresso", Category: "espresso", Price: 4}, Quantity: 2},
+				{Coffee: model.Coffee{ID: "tea", Category: "tea", Price: 2}, Quantity: 1},
+			},
+		}
+	}
+	promotion := &model.Promotion{Kind: model.DiscountKindPercentage, Value: 10, Categories: []string{"espresso"}}
+
+	// 10% of the espressos, then 10% of silver on what remains
+	o := order()
+	o.ComputeTotal(promotion, nil)
+	if o.Subtotal != 10 || o.PromotionDiscount != 0.8 || o.Discount != 1.72 || o.Tax != 0 || o.Total != 8.28 || o.TaxLines != nil {
+		t.Fatalf("unexpected totals without tax: %+v", o)
+	}
+
+	// the tea is exempt, the espressos are taxed on what remains once discounted
+	rule := &model.TaxRule{ID: "FR", Rate: 0.2, ExemptCategories: []string{"tea"}}
+	o = order()
+	o.ComputeTotal(promotion, rule)
+	want := []model.TaxLine{
+		{CoffeeID: "espresso", Category: "espresso", Amount: 6.48, Rate: 0.2, Tax: 1.3},
+		{CoffeeID: "tea", Category: "tea", Amount: 1.8, Exempt: true},
+	}
+	if fmt.Sprint(o.TaxLines) != fmt.Sprint(want) {
+		t.Fatalf("expected the tax lines %v, got %v", want, o.TaxLines)
+	}
+	if o.Tax != 1.3 || o.Total != 9.58 {
+		t.Fatalf("expected the tax added to the total, got %+v", o)
+	}
+
+	// the prices include the tax
+	rule.Inclusive = true
+	o = order()
+	o.ComputeTotal(promotion, rule)
+	if o.Tax != 1.08 || o.Total != 8.28 {
+		t.Fatalf("expected the tax included in the total, got %+v", o)
+	}
+}
+
+// TestOrder_ComputeTotalLinesAddUp spreads the cents of the total and of the tax over the tax lines.
+func TestOrder_ComputeTotalLinesAddUp(t *testing.T) {
+	o := &model.Order{
+		LoyaltyTier: model.LoyaltyTierSilver,
+		Items: []model.OrderItem{
+			{Coffee: model.Coffee{ID: "1", Price: 0.35}, Quantity: 1},
+			{Coffee: model.Coffee{ID: "2", Price: 0.35}, Quantity: 1},
+			{Coffee: model.Coffee{ID: "3", Price: 0.35}, Quantity: 1},
+		},
+	}
+	o.ComputeTotal(nil, &model.TaxRule{ID: "FR", Rate: 0.2})
+	// each line is 0.315 once discounted, 0.063 of tax
+	want := []model.TaxLine{
+		{CoffeeID: "1", Amount: 0.32, Rate: 0.2, Tax: 0.07},
+		{CoffeeID: "2", Amount: 0.31, Rate: 0.2, Tax: 0.06},
+		{CoffeeID: "3", Amount: 0.31, Rate: 0.2, Tax: 0.06},
+	}
+	if fmt.Sprint(o.TaxLines) != fmt.Sprint(want) {
+		t.Fatalf("expected the tax lines %v, got %v", want, o.TaxLines)
+	}
+	if o.Tax != 0.19 || o.Total != 1.13 {
+		t.Fatalf("expected the tax of the whole order, got %+v", o)
+	}
+}
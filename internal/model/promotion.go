@@ -0,0 +1,95 @@
+package model
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// DiscountKind is the way a Promotion discounts the orders
+type DiscountKind string
+
+const (
+	// DiscountKindPercentage discounts a percentage, between 0 and 100, of the amount
+	DiscountKindPercentage DiscountKind = "percentage"
+	// DiscountKindFixed discounts a fixed amount, at most the amount discounted
+	DiscountKindFixed DiscountKind = "fixed"
+)
+
+// DiscountKinds returns the kinds of discounts
+func DiscountKinds() []string {
+	return []string{string(DiscountKindPercentage), string(DiscountKindFixed)}
+}
+
+// Discount returns the discount of the given value on the amount, rounded to the cent.
+// It is never more than the amount, and never negative.
+func (k DiscountKind) Discount(value float64, amount float64) float64 {
+	var res float64
+	switch k {
+	case DiscountKindPercentage:
+		res = amount * value / 100
+	case DiscountKindFixed:
+		res = value
+	}
+	return RoundCents(math.Max(0, math.Min(res, amount)))
+}
+
+// Promotion discounts the orders using its code. A promotion scoped to coffees or categories only discounts the
+// items of these coffees, or of the coffees of these categories.
+type Promotion struct {
+	ID    string       `json:"id"`
+	Code  string       `json:"code"`
+	Kind  DiscountKind `json:"kind"`
+	Value float64      `json:"value"`
+	// CoffeeIDs and Categories scope the promotion. The promotion applies to all the coffees when both are empty.
+	CoffeeIDs  []string   `json:"coffee_ids,omitempty"`
+	Categories []string   `json:"categories,omitempty"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	// MaxRedemptions is the number of orders that can use the promotion, 0 when unlimited
+	MaxRedemptions int `json:"max_redemptions,omitempty"`
+	// Redemptions are the IDs of the orders using the promotion
+	Redemptions []string `json:"redemptions,omitempty"`
+}
+
+func (p *Promotion) GetID() string {
+	return p.ID
+}
+
+func (p *Promotion) SetID(id string) {
+	p.ID = id
+}
+
+// ActiveAt reports whether the time is in the validity window of the promotion
+func (p *Promotion) ActiveAt(t time.Time) bool {
+	return (p.StartsAt == nil || !t.Before(*p.StartsAt)) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+// RedeemedBy reports whether the order uses the promotion
+func (p *Promotion) RedeemedBy(orderID string) bool {
+	return slices.Contains(p.Redemptions, orderID)
+}
+
+// Exhausted reports whether the promotion cannot be used by any other order
+func (p *Promotion) Exhausted() bool {
+	return p.MaxRedemptions > 0 && len(p.Redemptions) >= p.MaxRedemptions
+}
+
+// Applies reports whether the promotion discounts the coffee
+func (p *Promotion) Applies(coffee *Coffee) bool {
+	if len(p.CoffeeIDs) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	return slices.Contains(p.CoffeeIDs, coffee.ID) || (coffee.Category != "" && slices.Contains(p.Categories, coffee.Category))
+}
+
+// Eligible returns the amount of the items the promotion applies to
+func (p *Promotion) Eligible(items []OrderItem) float64 {
+	res := 0.0
+	for _, item := range items {
+		if p.Applies(&item.Coffee) {
+			res += item.Coffee.Price * float64(item.Quantity)
+		}
+	}
+	return RoundCents(res)
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// SecretLease is held by an open reader of a secret. It is extended by renewing it and revoked once the reader
+// is closed. A lease that is not renewed in time expires on its own.
+type SecretLease struct {
+	ID              string        `json:"id"`
+	SecretManagerID string        `json:"secret_manager_id"`
+	SecretName      string        `json:"secret_name"`
+	VersionID       string        `json:"version_id"`
+	Principal       string        `json:"principal,omitempty"`
+	TTL             time.Duration `json:"ttl"`
+	CreatedAt       time.Time     `json:"created_at"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+}
+
+func (l *SecretLease) GetID() string {
+	return l.ID
+}
+
+func (l *SecretLease) SetID(id string) {
+	l.ID = id
+}
+
+// Active reports whether the lease is not expired at the given time
+func (l *SecretLease) Active(now time.Time) bool {
+	return now.Before(l.ExpiresAt)
+}
+
+// Renew extends the lease by its TTL, starting from now
+func (l *SecretLease) Renew(now time.Time) {
+	l.ExpiresAt = now.Add(l.TTL)
+}
+
+// ActiveLeases returns the leases of the secret that are still active at the given time
+func ActiveLeases(leases []*SecretLease, secretManagerID string, secretName string, now time.Time) []*SecretLease {
+	var res []*SecretLease
+	for _, l := range leases {
+		if l.SecretManagerID == secretManagerID && l.SecretName == secretName && l.Active(now) {
+			res = append(res, l)
+		}
+	}
+	return res
+}
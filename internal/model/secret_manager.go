@@ -1,9 +1,28 @@
 package model
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"terraform-provider-provs/internal/generator"
+	"time"
+)
+
+// DefaultSecretVersionRetention is the number of versions of a secret retained when the secret manager does not configure it
+const DefaultSecretVersionRetention = 10
+
 type SecretManager struct {
-	ID      string            `json:"id,omitempty"`
-	Secrets map[string]string `json:"secrets,omitempty"`
-	Name    string            `json:"name"`
+	ID      string             `json:"id,omitempty"`
+	Secrets map[string]*Secret `json:"secrets,omitempty"`
+	Name    string             `json:"name"`
+	// VersionRetention is the maximum number of versions of a secret that keep their value.
+	// Older versions are destroyed, only their metadata being kept.
+	VersionRetention int `json:"version_retention,omitempty"`
+	// DenyRotationWithActiveLeases refuses to write new versions of the secrets that are leased by open readers
+	DenyRotationWithActiveLeases bool `json:"deny_rotation_with_active_leases,omitempty"`
+	// Policy restricts the access to the secrets. Nil when every access is allowed.
+	Policy *SecretManagerPolicy `json:"policy,omitempty"`
 }
 
 func (o *SecretManager) GetID() string {
@@ -13,3 +32,180 @@ func (o *SecretManager) GetID() string {
 func (o *SecretManager) SetID(id string) {
 	o.ID = id
 }
+
+// Retention returns the number of versions retained for each secret
+func (o *SecretManager) Retention() int {
+	if o.VersionRetention < 1 {
+		return DefaultSecretVersionRetention
+	}
+	return o.VersionRetention
+}
+
+// PutSecret writes a new version of the secret with the given name, creating the secret if it does not exist.
+// The new version becomes the current one and the versions exceeding the retention of the manager are destroyed.
+// Writing the value of the current version again adds no version and returns the current one.
+// The versions of the manager without a creation time are given the time of the write, see BackfillCreatedAt.
+func (o *SecretManager) PutSecret(name string, value string, writeOnly bool, now time.Time) *SecretVersion {
+	o.BackfillCreatedAt(now)
+	if o.Secrets == nil {
+		o.Secrets = map[string]*Secret{}
+	}
+	s, ok := o.Secrets[name]
+	if !ok {
+		s = &Secret{}
+		o.Secrets[name] = s
+	}
+	s.WriteOnly = writeOnly
+	if cur := s.Current(); cur != nil && cur.State != SecretVersionDestroyed && cur.Value == value {
+		return cur
+	}
+	return s.addVersion(value, now, o.Retention())
+}
+
+// BackfillCreatedAt gives the given time to the versions without a creation time, which are the ones read from the
+// secret managers stored before secrets were versioned. It reports whether any version was changed.
+func (o *SecretManager) BackfillCreatedAt(now time.Time) bool {
+	changed := false
+	for _, s := range o.Secrets {
+		for i := range s.Versions {
+			if s.Versions[i].CreatedAt.IsZero() {
+				s.Versions[i].CreatedAt = now
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// UnmarshalJSON reads also the secret managers stored before secrets were versioned, when a secret was a plain
+// string and the write-only flags were kept in a separate map. Their single version has no creation time until
+// BackfillCreatedAt gives it one.
+func (o *SecretManager) UnmarshalJSON(b []byte) error {
+	type secretManager SecretManager // no methods, to not recurse into this one
+	aux := struct {
+		*secretManager
+		Secrets   map[string]json.RawMessage `json:"secrets,omitempty"`
+		WriteOnly map[string]bool            `json:"write_only,omitempty"`
+	}{
+		secretManager: (*secretManager)(o),
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	o.Secrets = nil
+	if len(aux.Secrets) > 0 {
+		o.Secrets = make(map[string]*Secret, len(aux.Secrets))
+	}
+	for name, raw := range aux.Secrets {
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`)) {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			s := &Secret{WriteOnly: aux.WriteOnly[name]}
+			s.addVersion(value, time.Time{}, o.Retention())
+			o.Secrets[name] = s
+			continue
+		}
+		var s Secret
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return fmt.Errorf("invalid secret %q: %w", name, err)
+		}
+		o.Secrets[name] = &s
+	}
+	return nil
+}
+
+// SecretVersionState describes the lifecycle of a secret version
+type SecretVersionState string
+
+const (
+	SecretVersionCurrent    SecretVersionState = "current"
+	SecretVersionPrevious   SecretVersionState = "previous"
+	SecretVersionDeprecated SecretVersionState = "deprecated"
+	// SecretVersionDestroyed is the state of the versions that exceeded the retention. These have no value anymore.
+	SecretVersionDestroyed SecretVersionState = "destroyed"
+)
+
+type Secret struct {
+	// WriteOnly records if the secret was written through a write-only attribute so that its value
+	// is never returned back into the state, including when the secret is imported.
+	WriteOnly bool `json:"write_only,omitempty"`
+	// Versions is ordered from the oldest to the newest version
+	Versions []SecretVersion `json:"versions"`
+	// Rotation is set when the values of the secret are generated and rotated by the provider
+	Rotation *SecretRotation `json:"rotation,omitempty"`
+}
+
+// SecretRotation describes how often and how a secret is regenerated
+type SecretRotation struct {
+	Interval       time.Duration  `json:"interval"`
+	Generator      generator.Spec `json:"generator"`
+	NextRotationAt time.Time      `json:"next_rotation_at"`
+}
+
+// Due reports whether the secret must be rotated at the given time
+func (r *SecretRotation) Due(now time.Time) bool {
+	return !now.Before(r.NextRotationAt)
+}
+
+type SecretVersion struct {
+	ID        string             `json:"version_id"`
+	CreatedAt time.Time          `json:"created_at"`
+	State     SecretVersionState `json:"state"`
+	Value     string             `json:"value,omitempty"`
+}
+
+// Current returns the current version of the secret or nil if the secret has no versions
+func (s *Secret) Current() *SecretVersion {
+	if len(s.Versions) == 0 {
+		return nil
+	}
+	return &s.Versions[len(s.Versions)-1]
+}
+
+// Version returns the version with the given ID
+func (s *Secret) Version(id string) (*SecretVersion, bool) {
+	for i := range s.Versions {
+		if s.Versions[i].ID == id {
+			return &s.Versions[i], true
+		}
+	}
+	return nil, false
+}
+
+func (s *Secret) addVersion(value string, now time.Time, retention int) *SecretVersion {
+	id := 1
+	if cur := s.Current(); cur != nil {
+		if prevID, err := strconv.Atoi(cur.ID); err == nil {
+			id = prevID + 1
+		}
+	}
+	s.Versions = append(s.Versions, SecretVersion{
+		ID:        strconv.Itoa(id),
+		CreatedAt: now,
+		Value:     value,
+	})
+
+	// walk from the newest to the oldest version to assign the states
+	retained := 0
+	for i := len(s.Versions) - 1; i >= 0; i-- {
+		v := &s.Versions[i]
+		if v.State == SecretVersionDestroyed {
+			continue
+		}
+		retained++
+		switch {
+		case retained > retention:
+			v.State = SecretVersionDestroyed
+			v.Value = ""
+		case i == len(s.Versions)-1:
+			v.State = SecretVersionCurrent
+		case retained == 2:
+			v.State = SecretVersionPrevious
+		default:
+			v.State = SecretVersionDeprecated
+		}
+	}
+	return s.Current()
+}
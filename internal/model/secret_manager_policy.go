@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// SecretAccess is the kind of access a principal has to the secrets of a secret manager
+type SecretAccess string
+
+const (
+	SecretAccessRead SecretAccess = "read"
+	// SecretAccessWrite allows reading the secrets too
+	SecretAccessWrite SecretAccess = "write"
+	// SecretAccessManage allows changing and deleting the secret manager and its policy, and reading and writing all
+	// its secrets. It is granted for all the secrets: the patterns of its grants must be "*".
+	SecretAccessManage SecretAccess = "manage"
+)
+
+// secretAccessRanks orders the accesses, each one including the accesses of lower rank
+var secretAccessRanks = map[SecretAccess]int{
+	SecretAccessRead:   1,
+	SecretAccessWrite:  2,
+	SecretAccessManage: 3,
+}
+
+// SecretAccesses returns all the supported accesses
+func SecretAccesses() []string {
+	return []string{string(SecretAccessRead), string(SecretAccessWrite), string(SecretAccessManage)}
+}
+
+// SecretManagerPolicy restricts the access to the secrets of a secret manager.
+// A secret manager without a policy allows every access.
+type SecretManagerPolicy struct {
+	Grants []SecretGrant `json:"grants"`
+}
+
+// SecretGrant gives the principals access to the secrets with names matching any of the patterns.
+// The patterns use the syntax of path.Match, like "db_*".
+type SecretGrant struct {
+	Principals  []string     `json:"principals"`
+	Access      SecretAccess `json:"access"`
+	SecretNames []string     `json:"secret_names"`
+}
+
+// Validate returns an error if any of the patterns of the policy is malformed, or if a manage grant is restricted to
+// some secrets
+func (p *SecretManagerPolicy) Validate() error {
+	for _, g := range p.Grants {
+		if g.Access == SecretAccessManage && !slices.Equal(g.SecretNames, []string{"*"}) {
+			return fmt.Errorf("the manage access applies to all the secrets: its secret names must be [\"*\"], got %q", g.SecretNames)
+		}
+		for _, pattern := range g.SecretNames {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid secret name pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the principal has the given access to the secret with the given name. The manage access
+// applies to the secret manager itself, the secret name being ignored.
+// A nil policy allows everything, while an empty principal is allowed only by a nil policy.
+func (p *SecretManagerPolicy) Allows(principal string, access SecretAccess, secretName string) bool {
+	if p == nil {
+		return true
+	}
+	if principal == "" {
+		return false
+	}
+	for _, g := range p.Grants {
+		if secretAccessRanks[g.Access] < secretAccessRanks[access] {
+			continue
+		}
+		if !slices.Contains(g.Principals, principal) {
+			continue
+		}
+		if access == SecretAccessManage {
+			return true
+		}
+		for _, pattern := range g.SecretNames {
+			if ok, _ := path.Match(pattern, secretName); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package model_test
+
+import (
+	"terraform-provider-provs/internal/model"
+	"testing"
+)
+
+func TestSecretManagerPolicy_Allows(t *testing.T) {
+	policy := &model.SecretManagerPolicy{Grants: []model.SecretGrant{
+		{Principals: []string{"admin"}, Access: model.SecretAccessManage, SecretNames: []string{"*"}},
+		{Principals: []string{"ci"}, Access: model.SecretAccessWrite, SecretNames: []string{"db_*"}},
+		{Principals: []string{"app"}, Access: model.SecretAccessRead, SecretNames: []string{"*"}},
+	}}
+	for _, tc := range []struct {
+		principal  string
+		access     model.SecretAccess
+		secretName string
+		want       bool
+	}{
+		{"admin", model.SecretAccessManage, "", true},
+		{"admin", model.SecretAccessWrite, "api_key", true},
+		{"ci", model.SecretAccessWrite, "db_password", true},
+		{"ci", model.SecretAccessRead, "db_password", true},
+		{"ci", model.SecretAccessWrite, "api_key", false},
+		{"ci", model.SecretAccessManage, "", false},
+		{"app", model.SecretAccessRead, "api_key", true},
+		{"app", model.SecretAccessWrite, "api_key", false},
+		{"app", model.SecretAccessManage, "", false},
+		{"", model.SecretAccessRead, "api_key", false},
+	} {
+		if got := policy.Allows(tc.principal, tc.access, tc.secretName); got != tc.want {
+			t.Errorf("expected %s to %s %q: %t, got %t", tc.principal, tc.access, tc.secretName, tc.want, got)
+		}
+	}
+
+	var none *model.SecretManagerPolicy
+	if !none.Allows("", model.SecretAccessManage, "") {
+		t.Error("expected no policy to allow everything")
+	}
+}
+
+func TestSecretManagerPolicy_Validate(t *testing.T) {
+	restricted := &model.SecretManagerPolicy{Grants: []model.SecretGrant{
+		{Principals: []string{"admin"}, Access: model.SecretAccessManage, SecretNames: []string{"db_*"}},
+	}}
+	if err := restricted.Validate(); err == nil {
+		t.Fatal("expected a manage grant restricted to some secrets to be refused")
+	}
+}
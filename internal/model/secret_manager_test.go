@@ -0,0 +1,67 @@
+package model_test
+
+import (
+	"encoding/json"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+)
+
+func TestSecretManager_PutSecret(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mgr := &model.SecretManager{}
+	if v := mgr.PutSecret("api_key", "one", false, created); v.ID != "1" {
+		t.Fatalf("expected the version 1, got %s", v.ID)
+	}
+
+	// writing the same value again adds no version
+	v := mgr.PutSecret("api_key", "one", true, created.Add(time.Hour))
+	if v.ID != "1" || !v.CreatedAt.Equal(created) {
+		t.Fatalf("expected the version 1 created at %s, got %s created at %s", created, v.ID, v.CreatedAt)
+	}
+	secret := mgr.Secrets["api_key"]
+	if len(secret.Versions) != 1 || !secret.WriteOnly {
+		t.Fatalf("expected one write-only version, got %d versions, write-only %t", len(secret.Versions), secret.WriteOnly)
+	}
+
+	if v := mgr.PutSecret("api_key", "two", true, created.Add(time.Hour)); v.ID != "2" || v.Value != "two" {
+		t.Fatalf("expected the version 2 of value two, got %s of value %s", v.ID, v.Value)
+	}
+	if prev, _ := secret.Version("1"); prev.State != model.SecretVersionPrevious {
+		t.Fatalf("expected the version 1 previous, got %s", prev.State)
+	}
+}
+
+func TestSecretManager_legacySecrets(t *testing.T) {
+	var mgr model.SecretManager
+	if err := json.Unmarshal([]byte(`{"name":"legacy","secrets":{"api_key":"one"},"write_only":{"api_key":true}}`), &mgr); err != nil {
+		t.Fatal(err)
+	}
+	secret := mgr.Secrets["api_key"]
+	if cur := secret.Current(); cur.Value != "one" || !secret.WriteOnly {
+		t.Fatalf("expected the write-only value one, got %q (write-only %t)", cur.Value, secret.WriteOnly)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !mgr.BackfillCreatedAt(now) {
+		t.Fatal("expected the legacy version backfilled")
+	}
+	if cur := secret.Current(); !cur.CreatedAt.Equal(now) {
+		t.Fatalf("expected the legacy version created at %s, got %s", now, cur.CreatedAt)
+	}
+	if mgr.BackfillCreatedAt(now.Add(time.Hour)) {
+		t.Fatal("expected nothing left to backfill")
+	}
+
+	// a write gives the time of the write to the versions of the other secrets too
+	if err := json.Unmarshal([]byte(`{"name":"legacy","secrets":{"api_key":"one","db_password":"two"}}`), &mgr); err != nil {
+		t.Fatal(err)
+	}
+	mgr.PutSecret("api_key", "three", false, now)
+	if cur := mgr.Secrets["db_password"].Current(); !cur.CreatedAt.Equal(now) {
+		t.Fatalf("expected the legacy version created at %s, got %s", now, cur.CreatedAt)
+	}
+	if v, _ := mgr.Secrets["api_key"].Version("1"); !v.CreatedAt.Equal(now) {
+		t.Fatalf("expected the legacy version created at %s, got %s", now, v.CreatedAt)
+	}
+}
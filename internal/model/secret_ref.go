@@ -0,0 +1,43 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SecretRefPrefix is the prefix of the secret references
+const SecretRefPrefix = "provs://"
+
+// SecretRef references a secret of a SecretManager, without its value
+type SecretRef struct {
+	SecretManagerID string
+	SecretName      string
+}
+
+// String returns the canonical form of the reference: provs://<secret_manager_id>/<secret_name>, both being
+// escaped so that the reference can be parsed back whatever characters they contain.
+func (r SecretRef) String() string {
+	return SecretRefPrefix + url.PathEscape(r.SecretManagerID) + "/" + url.PathEscape(r.SecretName)
+}
+
+// ParseSecretRef parses a reference in the format returned by SecretRef.String
+func ParseSecretRef(ref string) (SecretRef, error) {
+	rest, ok := strings.CutPrefix(ref, SecretRefPrefix)
+	if !ok {
+		return SecretRef{}, fmt.Errorf("a secret reference must start with %s, got %q", SecretRefPrefix, ref)
+	}
+	mgrID, name, ok := strings.Cut(rest, "/")
+	if !ok || mgrID == "" || name == "" || strings.Contains(name, "/") {
+		return SecretRef{}, fmt.Errorf("a secret reference must have the format %s<secret_manager_id>/<secret_name>, got %q", SecretRefPrefix, ref)
+	}
+	var res SecretRef
+	var err error
+	if res.SecretManagerID, err = url.PathUnescape(mgrID); err != nil {
+		return SecretRef{}, fmt.Errorf("invalid secret manager ID in the secret reference %q: %w", ref, err)
+	}
+	if res.SecretName, err = url.PathUnescape(name); err != nil {
+		return SecretRef{}, fmt.Errorf("invalid secret name in the secret reference %q: %w", ref, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import "slices"
+
+// TaxRule is the tax of the orders of a region, identified by the region
+type TaxRule struct {
+	ID   string  `json:"id"`
+	Rate float64 `json:"rate"`
+	// Inclusive is true when the prices of the coffees include the tax, which is then not added to the total
+	Inclusive bool `json:"inclusive,omitempty"`
+	// ExemptCategories are the categories of the coffees that are not taxed
+	ExemptCategories []string `json:"exempt_categories,omitempty"`
+}
+
+func (r *TaxRule) GetID() string {
+	return r.ID
+}
+
+func (r *TaxRule) SetID(id string) {
+	r.ID = id
+}
+
+// Exempts reports whether the coffee is not taxed
+func (r *TaxRule) Exempts(coffee *Coffee) bool {
+	return coffee.Category != "" && slices.Contains(r.ExemptCategories, coffee.Category)
+}
+
+// Tax returns the tax of the amount, rounded to the cent. The amount includes the tax when the rule is inclusive.
+func (r *TaxRule) Tax(amount float64) float64 {
+	if r.Inclusive {
+		return RoundCents(amount * r.Rate / (1 + r.Rate))
+	}
+	return RoundCents(amount * r.Rate)
+}
+
+// TaxLine traces the tax of an item of an order
+type TaxLine struct {
+	CoffeeID string `json:"coffee_id"`
+	Category string `json:"category,omitempty"`
+	// Amount is the price of the item once discounted, the discounts being spread over the items they apply to
+	// in proportion to their price. The amounts of the lines add up to the total of the order before tax.
+	Amount float64 `json:"amount"`
+	Exempt bool    `json:"exempt,omitempty"`
+	// Rate is the rate applied, 0 when the item is exempt
+	Rate float64 `json:"rate"`
+	Tax  float64 `json:"tax"`
+}
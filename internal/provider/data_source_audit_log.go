@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// defaultAuditLogLimit is the number of entries returned when the limit is not configured
+const defaultAuditLogLimit = 20
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &auditLogDataSource{}
+	_ datasource.DataSourceWithConfigure = &auditLogDataSource{}
+)
+
+// auditLogDataSourceModel maps the data source schema data.
+type auditLogDataSourceModel struct {
+	Limit           types.Int64          `tfsdk:"limit"`
+	SecretManagerID types.String         `tfsdk:"secret_manager_id"`
+	SecretName      types.String         `tfsdk:"secret_name"`
+	Principal       types.String         `tfsdk:"principal"`
+	Entries         []auditLogEntryModel `tfsdk:"entries"`
+	Store           types.String         `tfsdk:"store"`
+}
+
+// auditLogEntryModel maps an entry of the audit log.
+type auditLogEntryModel struct {
+	Seq             types.Int64  `tfsdk:"seq"`
+	Timestamp       types.String `tfsdk:"timestamp"`
+	Principal       types.String `tfsdk:"principal"`
+	SecretManagerID types.String `tfsdk:"secret_manager_id"`
+	SecretName      types.String `tfsdk:"secret_name"`
+	Operation       types.String `tfsdk:"operation"`
+	Outcome         types.String `tfsdk:"outcome"`
+	Hash            types.String `tfsdk:"hash"`
+}
+
+// NewAuditLogDataSource is a helper function to simplify the provider implementation.
+func NewAuditLogDataSource() datasource.DataSource {
+	return &auditLogDataSource{}
+}
+
+// auditLogDataSource returns the most recent entries of the audit log of the secret accesses.
+type auditLogDataSource struct {
+	backend      client.BackendClient
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *auditLogDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeAuditLog
+}
+
+// Schema defines the schema for the data source.
+func (d *auditLogDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Returns the most recent accesses to the secrets, from the newest to the oldest.",
+		Attributes: map[string]schema.Attribute{
+			"limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("The maximum number of entries returned. Defaults to %d.", defaultAuditLogLimit),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"secret_manager_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Return only the accesses to the secrets of this secret manager.",
+			},
+			"secret_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Return only the accesses to the secrets with this name.",
+			},
+			"principal": schema.StringAttribute{
+				Optional:    true,
+				Description: "Return only the accesses of this principal.",
+			},
+			"entries": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"seq": schema.Int64Attribute{
+							Computed: true,
+						},
+						"timestamp": schema.StringAttribute{
+							Computed: true,
+						},
+						"principal": schema.StringAttribute{
+							Computed: true,
+						},
+						"secret_manager_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"secret_name": schema.StringAttribute{
+							Computed: true,
+						},
+						"operation": schema.StringAttribute{
+							Computed: true,
+						},
+						"outcome": schema.StringAttribute{
+							Computed: true,
+							Description: fmt.Sprintf("%q for the accesses performed, %q for the accesses refused by the policy, %q for the allowed accesses that failed.",
+								auditOutcomeSuccess, model.AuditOutcomeDenied, model.AuditOutcomeFailed),
+						},
+						"hash": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *auditLogDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state auditLogDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	limit := defaultAuditLogLimit
+	if !state.Limit.IsNull() {
+		limit = int(state.Limit.ValueInt64())
+	}
+	entries, err := audit.Recent(d.backend, audit.Filter{
+		SecretManagerID: state.SecretManagerID.ValueString(),
+		SecretName:      state.SecretName.ValueString(),
+		Principal:       state.Principal.ValueString(),
+	}, limit)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read the audit log",
+			err.Error(),
+		)
+		return
+	}
+
+	state.Entries = []auditLogEntryModel{}
+	for _, e := range entries {
+		state.Entries = append(state.Entries, auditLogEntryModel{
+			Seq:             types.Int64Value(e.Seq),
+			Timestamp:       types.StringValue(e.Timestamp.Format(time.RFC3339Nano)),
+			Principal:       types.StringValue(e.Principal),
+			SecretManagerID: types.StringValue(e.SecretManagerID),
+			SecretName:      types.StringValue(e.SecretName),
+			Operation:       types.StringValue(string(e.Operation)),
+			Outcome:         types.StringValue(auditOutcome(e)),
+			Hash:            types.StringValue(e.Hash),
+		})
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *auditLogDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.backend = c
+}
+
+// auditOutcomeSuccess is the outcome shown for the accesses performed, recorded without outcome
+const auditOutcomeSuccess = "success"
+
+func auditOutcome(e *model.AuditEntry) string {
+	if e.Outcome == "" {
+		return auditOutcomeSuccess
+	}
+	return string(e.Outcome)
+}
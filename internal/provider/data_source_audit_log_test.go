@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAuditLogDataSource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "s3cr3t"`),
+			},
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "s3cr3t"`) + `
+data "provs_audit_log" "test" {
+  secret_name = provs_secret.test.secret_name
+  limit       = 1
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.provs_audit_log.test", "entries.#", "1"),
+					resource.TestCheckResourceAttr("data.provs_audit_log.test", "entries.0.secret_name", "db_password"),
+					resource.TestCheckResourceAttr("data.provs_audit_log.test", "entries.0.operation", "read"),
+					resource.TestCheckResourceAttrPair("data.provs_audit_log.test", "entries.0.secret_manager_id", "provs_secret_manager.test", "id"),
+					resource.TestCheckNoResourceAttr("data.provs_audit_log.test", "entries.0.value"),
+				),
+			},
+		},
+	})
+}
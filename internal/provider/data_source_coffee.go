@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &coffeeDataSource{}
+	_ datasource.DataSourceWithConfigure = &coffeeDataSource{}
+)
+
+// coffeesModel maps coffees schema data.
+// It is the model of the provs_coffee data source and of the items of the provs_coffees data source.
+type coffeesModel struct {
+	ID          types.String              `tfsdk:"id"`
+	Name        types.String              `tfsdk:"name"`
+	Teaser      types.String              `tfsdk:"teaser"`
+	Description types.String              `tfsdk:"description"`
+	Price       types.Float64             `tfsdk:"price"`
+	Image       types.String              `tfsdk:"image"`
+	Category    types.String              `tfsdk:"category"`
+	Ingredients []coffeesIngredientsModel `tfsdk:"ingredients"`
+}
+
+// coffeeDataSourceModel maps the provs_coffee data source schema data: the coffee and the store it is looked up in.
+type coffeeDataSourceModel struct {
+	coffeesModel
+	Store types.String `tfsdk:"store"`
+}
+
+// coffeesIngredientsModel maps coffee ingredients data
+type coffeesIngredientsModel struct {
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	Unit     types.String `tfsdk:"unit"`
+	Quantity types.Int64  `tfsdk:"quantity"`
+}
+
+// NewCoffeeDataSource is a helper function to simplify the provider implementation.
+func NewCoffeeDataSource() datasource.DataSource {
+	return &coffeeDataSource{}
+}
+
+// coffeeDataSource looks up a single coffee by its ID or name.
+type coffeeDataSource struct {
+	client       client.Client[*model.Coffee]
+	ingredients  client.Client[*model.Ingredient]
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *coffeeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeCoffee
+}
+
+// Schema defines the schema for the data source.
+func (d *coffeeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attrs := coffeeAttributes()
+	attrs["id"] = schema.StringAttribute{
+		Optional: true,
+		Computed: true,
+		Validators: []validator.String{
+			stringvalidator.ExactlyOneOf(path.MatchRoot("name")),
+		},
+	}
+	attrs["name"] = schema.StringAttribute{
+		Optional:    true,
+		Computed:    true,
+		Description: "The name of the coffee. Exactly one coffee must have it.",
+	}
+	attrs["store"] = dataSourceStoreSchema()
+	resp.Schema = schema.Schema{
+		Description: "Looks up a coffee of the catalog by its ID or name.",
+		Attributes:  attrs,
+	}
+}
+
+// coffeeAttributes returns the schema of the coffees returned by the coffee data sources
+func coffeeAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Computed: true,
+		},
+		"name": schema.StringAttribute{
+			Computed: true,
+		},
+		"teaser": schema.StringAttribute{
+			Computed: true,
+		},
+		"description": schema.StringAttribute{
+			Computed: true,
+		},
+		"price": schema.Float64Attribute{
+			Computed: true,
+		},
+		"image": schema.StringAttribute{
+			Computed: true,
+		},
+		"category": schema.StringAttribute{
+			Computed: true,
+		},
+		"ingredients": schema.ListNestedAttribute{
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.StringAttribute{
+						Computed: true,
+					},
+					"name": schema.StringAttribute{
+						Computed:    true,
+						Description: "Null when the ingredient does not exist anymore.",
+					},
+					"unit": schema.StringAttribute{
+						Computed:    true,
+						Description: "Null when the ingredient does not exist anymore.",
+					},
+					"quantity": schema.Int64Attribute{
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *coffeeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config coffeeDataSourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, config.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var coffee *model.Coffee
+	if !config.ID.IsNull() {
+		var err error
+		coffee, err = d.client.GetByID(config.ID.ValueString())
+		if client.IsNotFound(err) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("id"),
+				"Coffee not found",
+				fmt.Sprintf("There is no coffee with the ID %q.", config.ID.ValueString()),
+			)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Read Coffee",
+				fmt.Sprintf("Could not read coffee ID %s: %s", config.ID.ValueString(), err),
+			)
+			return
+		}
+	} else {
+		coffees, err := d.client.GetAll()
+		if err != nil && !client.IsNotFound(err) {
+			resp.Diagnostics.AddError(
+				"Unable to Read Coffees",
+				err.Error(),
+			)
+			return
+		}
+		var found []*model.Coffee
+		for _, c := range coffees {
+			if c.Name == config.Name.ValueString() {
+				found = append(found, c)
+			}
+		}
+		switch len(found) {
+		case 0:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Coffee not found",
+				fmt.Sprintf("There is no coffee named %q.", config.Name.ValueString()),
+			)
+			return
+		case 1:
+			coffee = found[0]
+		default:
+			var ids []string
+			for _, c := range found {
+				ids = append(ids, c.ID)
+			}
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Multiple coffees found",
+				fmt.Sprintf("The name %q is used by multiple coffees: %s. Look the coffee up by its ID instead.", config.Name.ValueString(), strings.Join(ids, ", ")),
+			)
+			return
+		}
+	}
+
+	ingredients, err := ingredientsByID(d.ingredients)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Ingredients",
+			err.Error(),
+		)
+		return
+	}
+	state := coffeeDataSourceModel{
+		coffeesModel: coffeeDataSourceState(coffee, ingredients),
+		Store:        config.Store,
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *coffeeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.Coffee](c, typeCoffees)
+	d.ingredients = client.NewClient[*model.Ingredient](c, typeIngredient)
+}
+
+// ingredientsByID returns all the ingredients, indexed by their ID
+func ingredientsByID(c client.Client[*model.Ingredient]) (map[string]*model.Ingredient, error) {
+	all, err := c.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		return nil, err
+	}
+	res := make(map[string]*model.Ingredient, len(all))
+	for _, i := range all {
+		res[i.ID] = i
+	}
+	return res, nil
+}
+
+// coffeeDataSourceState maps the coffee to the data source model, with the details of its ingredients
+func coffeeDataSourceState(coffee *model.Coffee, ingredients map[string]*model.Ingredient) coffeesModel {
+	res := coffeesModel{
+		ID:          types.StringValue(coffee.ID),
+		Name:        types.StringValue(coffee.Name),
+		Teaser:      types.StringValue(coffee.Teaser),
+		Description: types.StringValue(coffee.Description),
+		Price:       types.Float64Value(coffee.Price),
+		Image:       types.StringValue(coffee.Image),
+		Category:    types.StringValue(coffee.Category),
+	}
+	for _, item := range coffee.Ingredient {
+		ingredient := coffeesIngredientsModel{
+			ID:       types.StringValue(item.IngredientID),
+			Name:     types.StringNull(),
+			Unit:     types.StringNull(),
+			Quantity: types.Int64Value(int64(item.Quantity)),
+		}
+		if i, ok := ingredients[item.IngredientID]; ok {
+			ingredient.Name = types.StringValue(i.Name)
+			ingredient.Unit = types.StringValue(i.Unit)
+		} else if item.Name != "" {
+			// stored inline by the coffees created before the ingredients were shared
+			ingredient.Name = types.StringValue(item.Name)
+			ingredient.Unit = types.StringValue(item.Unit)
+		}
+		res.Ingredients = append(res.Ingredients, ingredient)
+	}
+	return res
+}
@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/client/filesystem"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccCoffeesDataSource_filter(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffees" "test" {
+  filter {
+    name_regex = "^(Espresso|Latte)"
+  }
+  filter {
+    ingredient_id = provs_ingredient.milk.id
+  }
+  depends_on = [provs_coffee.espresso, provs_coffee.latte, provs_coffee.mocha]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_coffees.test", tfjsonpath.New("coffees"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"name": knownvalue.StringExact("Latte"),
+							"ingredients": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.ObjectPartial(map[string]knownvalue.Check{
+									"name": knownvalue.StringExact("Coffee beans"),
+									"unit": knownvalue.StringExact("g"),
+								}),
+								knownvalue.ObjectPartial(map[string]knownvalue.Check{
+									"name":     knownvalue.StringExact("Milk"),
+									"unit":     knownvalue.StringExact("ml"),
+									"quantity": knownvalue.Int64Exact(150),
+								}),
+							}),
+						}),
+					})),
+				},
+			},
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffees" "test" {
+  filter {
+    min_price = 2
+    max_price = 4
+  }
+  sort_by    = "price"
+  limit      = 1
+  depends_on = [provs_coffee.espresso, provs_coffee.latte, provs_coffee.mocha]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_coffees.test", tfjsonpath.New("coffees"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"name": knownvalue.StringExact("Espresso"),
+						}),
+					})),
+				},
+			},
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffees" "test" {
+  filter {
+    min_price = 4
+    max_price = 2
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid price range`),
+			},
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffees" "test" {
+  filter {
+    name_regex = "("
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid regular expression`),
+			},
+		},
+	})
+}
+
+func TestAccCoffeeDataSource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffee" "by_id" {
+  id = provs_coffee.latte.id
+}
+
+data "provs_coffee" "by_name" {
+  name       = "Espresso"
+  depends_on = [provs_coffee.espresso]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.provs_coffee.by_id", "name", "Latte"),
+					resource.TestCheckResourceAttr("data.provs_coffee.by_id", "ingredients.#", "2"),
+					resource.TestCheckResourceAttrPair("data.provs_coffee.by_name", "id", "provs_coffee.espresso", "id"),
+					resource.TestCheckResourceAttr("data.provs_coffee.by_name", "price", "2.5"),
+				),
+			},
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+data "provs_coffee" "test" {
+  name = "Cappuccino"
+}
+`,
+				ExpectError: regexp.MustCompile(`There is no coffee named "Cappuccino"`),
+			},
+			{
+				Config: testAccCoffeeCatalogConfig(storagePath) + `
+resource "provs_coffee" "other_espresso" {
+  name  = "Espresso"
+  price = 2.8
+}
+
+data "provs_coffee" "test" {
+  name       = "Espresso"
+  depends_on = [provs_coffee.espresso, provs_coffee.other_espresso]
+}
+`,
+				ExpectError: regexp.MustCompile(`Multiple coffees found`),
+			},
+		},
+	})
+}
+
+// testAccCoffeeCatalogConfig returns a catalog of three coffees, two of them with milk
+func testAccCoffeeCatalogConfig(storagePath string) string {
+	return testAccProviderConfig(storagePath) + `
+resource "provs_ingredient" "beans" {
+  name = "Coffee beans"
+  unit = "g"
+}
+
+resource "provs_ingredient" "milk" {
+  name = "Milk"
+  unit = "ml"
+}
+
+resource "provs_coffee" "espresso" {
+  name  = "Espresso"
+  price = 2.5
+  ingredients = [{
+    id       = provs_ingredient.beans.id
+    quantity = 7
+  }]
+}
+
+resource "provs_coffee" "latte" {
+  name  = "Latte"
+  price = 3.5
+  ingredients = [{
+    id       = provs_ingredient.beans.id
+    quantity = 7
+  }, {
+    id       = provs_ingredient.milk.id
+    quantity = 150
+  }]
+}
+
+resource "provs_coffee" "mocha" {
+  name  = "Mocha"
+  price = 4.5
+  ingredients = [{
+    id       = provs_ingredient.milk.id
+    quantity = 120
+  }]
+}
+`
+}
+
+func TestCoffeeDataSource_legacyIngredients(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stored before the ingredients were shared
+	if err := backend.CreateWithId(typeCoffees, "legacy", strings.NewReader(`{"id":"legacy","name":"Latte","ingredients":[{"ingredient_id":"1","name":"Milk","quantity":200,"unit":"ml"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := testReadDataSource(ctx, t, server, schemas, "provs_coffee", map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, "legacy"),
+	})
+	var ingredients []tftypes.Value
+	var ingredient map[string]tftypes.Value
+	if err := state["ingredients"].As(&ingredients); err != nil || len(ingredients) != 1 {
+		t.Fatalf("expected one ingredient, got %s (%v)", state["ingredients"], err)
+	}
+	if err := ingredients[0].As(&ingredient); err != nil {
+		t.Fatal(err)
+	}
+	if name, unit := testStringAttr(t, ingredient, "name"), testStringAttr(t, ingredient, "unit"); name != "Milk" || unit != "ml" {
+		t.Fatalf("expected the ingredient Milk in ml, got %s in %s", name, unit)
+	}
+}
@@ -3,13 +3,19 @@ package provider
 import (
 	"context"
 	"fmt"
-	"os"
-	"strconv"
+	"regexp"
+	"sort"
+	"strings"
 	"terraform-provider-provs/internal/client"
 	"terraform-provider-provs/internal/model"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -19,25 +25,24 @@ var (
 	_ datasource.DataSourceWithConfigure = &coffeesDataSource{}
 )
 
+// coffeesSortBy are the values accepted by sort_by, the first one being the default
+var coffeesSortBy = []string{"id", "name", "price"}
+
 // coffeesDataSourceModel maps the data source schema data.
 type coffeesDataSourceModel struct {
-	Coffees []coffeesModel `tfsdk:"coffees"`
-}
-
-// coffeesModel maps coffees schema data.
-type coffeesModel struct {
-	ID          types.String              `tfsdk:"id"`
-	Name        types.String              `tfsdk:"name"`
-	Teaser      types.String              `tfsdk:"teaser"`
-	Description types.String              `tfsdk:"description"`
-	Price       types.Float64             `tfsdk:"price"`
-	Image       types.String              `tfsdk:"image"`
-	Ingredients []coffeesIngredientsModel `tfsdk:"ingredients"`
+	Filters []coffeesFilterModel `tfsdk:"filter"`
+	SortBy  types.String         `tfsdk:"sort_by"`
+	Limit   types.Int64          `tfsdk:"limit"`
+	Coffees []coffeesModel       `tfsdk:"coffees"`
+	Store   types.String         `tfsdk:"store"`
 }
 
-// coffeesIngredientsModel maps coffee ingredients data
-type coffeesIngredientsModel struct {
-	ID types.String `tfsdk:"id"`
+// coffeesFilterModel maps a filter block. A coffee matches when it matches all the conditions set.
+type coffeesFilterModel struct {
+	NameRegex    types.String  `tfsdk:"name_regex"`
+	MinPrice     types.Float64 `tfsdk:"min_price"`
+	MaxPrice     types.Float64 `tfsdk:"max_price"`
+	IngredientID types.String  `tfsdk:"ingredient_id"`
 }
 
 // NewCoffeesDataSource is a helper function to simplify the provider implementation.
@@ -47,7 +52,9 @@ func NewCoffeesDataSource() datasource.DataSource {
 
 // coffeesDataSource is the data source implementation.
 type coffeesDataSource struct {
-	client client.Client[*model.Coffee]
+	client       client.Client[*model.Coffee]
+	ingredients  client.Client[*model.Ingredient]
+	providerData any
 }
 
 // Metadata returns the data source type name.
@@ -58,39 +65,58 @@ func (d *coffeesDataSource) Metadata(_ context.Context, req datasource.MetadataR
 // Schema defines the schema for the data source.
 func (d *coffeesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "Lists the coffees of the catalog. The coffees returned match all the filter blocks.",
 		Attributes: map[string]schema.Attribute{
+			"sort_by": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("One of: %s. Defaults to %s.", strings.Join(coffeesSortBy, ", "), coffeesSortBy[0]),
+				Validators: []validator.String{
+					stringvalidator.OneOf(coffeesSortBy...),
+				},
+			},
+			"limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of coffees returned, after sorting them.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
 			"coffees": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
+					Attributes: coffeeAttributes(),
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+		Blocks: map[string]schema.Block{
+			"filter": schema.ListNestedBlock{
+				Description: "Selects the coffees matching all the conditions set in the block.",
+				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
-						"id": schema.StringAttribute{
-							Computed: true,
-						},
-						"name": schema.StringAttribute{
-							Computed: true,
-						},
-						"teaser": schema.StringAttribute{
-							Computed: true,
-						},
-						"description": schema.StringAttribute{
-							Computed: true,
-						},
-						"price": schema.Float64Attribute{
-							Computed: true,
+						"name_regex": schema.StringAttribute{
+							Optional:    true,
+							Description: "A regular expression the name of the coffee matches, in the RE2 syntax.",
+							Validators: []validator.String{
+								regexValidator{},
+							},
 						},
-						"image": schema.StringAttribute{
-							Computed: true,
+						"min_price": schema.Float64Attribute{
+							Optional: true,
+							Validators: []validator.Float64{
+								float64validator.AtLeast(0),
+							},
 						},
-						"ingredients": schema.ListNestedAttribute{
-							Computed: true,
-							NestedObject: schema.NestedAttributeObject{
-								Attributes: map[string]schema.Attribute{
-									"id": schema.StringAttribute{
-										Computed: true,
-									},
-								},
+						"max_price": schema.Float64Attribute{
+							Optional: true,
+							Validators: []validator.Float64{
+								float64validator.AtLeast(0),
 							},
 						},
+						"ingredient_id": schema.StringAttribute{
+							Optional:    true,
+							Description: "The ID of an ingredient the coffee is made with.",
+						},
 					},
 				},
 			},
@@ -99,40 +125,68 @@ func (d *coffeesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 }
 
 // Read refreshes the Terraform state with the latest data.
-func (d *coffeesDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+func (d *coffeesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state coffeesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, f := range state.Filters {
+		if !f.MinPrice.IsNull() && !f.MaxPrice.IsNull() && f.MinPrice.ValueFloat64() > f.MaxPrice.ValueFloat64() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filter").AtListIndex(i).AtName("max_price"),
+				"Invalid price range",
+				fmt.Sprintf("The max_price %v is lower than the min_price %v.", f.MaxPrice.ValueFloat64(), f.MinPrice.ValueFloat64()),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	coffees, err := d.client.GetAll()
-	if err != nil {
+	if err != nil && !client.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Unable to Read Coffees",
 			err.Error(),
 		)
 		return
 	}
+	ingredients, err := ingredientsByID(d.ingredients)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Ingredients",
+			err.Error(),
+		)
+		return
+	}
 
-	// Map response body to model
+	var selected []*model.Coffee
 	for _, coffee := range coffees {
-		coffeeState := coffeesModel{
-			ID:          types.StringValue(coffee.ID),
-			Name:        types.StringValue(coffee.Name),
-			Teaser:      types.StringValue(coffee.Teaser),
-			Description: types.StringValue(coffee.Description),
-			Price:       types.Float64Value(coffee.Price),
-			Image:       types.StringValue(coffee.Image),
+		matches := true
+		for _, f := range state.Filters {
+			matches = matches && f.matches(coffee)
 		}
-
-		for _, ingredient := range coffee.Ingredient {
-			coffeeState.Ingredients = append(coffeeState.Ingredients, coffeesIngredientsModel{
-				ID: types.StringValue(ingredient.ID),
-			})
+		if matches {
+			selected = append(selected, coffee)
 		}
+	}
+	sortCoffees(selected, state.SortBy.ValueString())
+	if limit := int(state.Limit.ValueInt64()); limit > 0 && len(selected) > limit {
+		selected = selected[:limit]
+	}
 
-		state.Coffees = append(state.Coffees, coffeeState)
+	// Map response body to model
+	state.Coffees = nil
+	for _, coffee := range selected {
+		state.Coffees = append(state.Coffees, coffeeDataSourceState(coffee, ingredients))
 	}
 
 	// Set state
-	diags := resp.State.Set(ctx, &state)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -157,39 +211,39 @@ func (d *coffeesDataSource) Configure(_ context.Context, req datasource.Configur
 		return
 	}
 
+	d.providerData = req.ProviderData
 	d.client = client.NewClient[*model.Coffee](c, typeCoffees)
-	if err := d.provisionData(); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to create coffee data",
-			fmt.Sprintf("Failed to configure the available coffees: %v", err),
-		)
+	d.ingredients = client.NewClient[*model.Ingredient](c, typeIngredient)
+}
+
+// matches reports whether the coffee matches all the conditions of the filter.
+// The regular expression is already validated by the schema.
+func (f coffeesFilterModel) matches(coffee *model.Coffee) bool {
+	if !f.NameRegex.IsNull() && !regexp.MustCompile(f.NameRegex.ValueString()).MatchString(coffee.Name) {
+		return false
+	}
+	if !f.MinPrice.IsNull() && coffee.Price < f.MinPrice.ValueFloat64() {
+		return false
+	}
+	if !f.MaxPrice.IsNull() && coffee.Price > f.MaxPrice.ValueFloat64() {
+		return false
 	}
+	if !f.IngredientID.IsNull() && !coffee.UsesIngredient(f.IngredientID.ValueString()) {
+		return false
+	}
+	return true
 }
 
-func (d *coffeesDataSource) provisionData() error {
-	_, err := d.client.GetByID("1")
-	if os.IsNotExist(err) {
-		// coffees not initialized, create all of them
-		for i := 1; i < 10; i++ {
-			var ingredients []model.Ingredient
-			for j := 0; j < i; j++ {
-				ingredients = append(ingredients, model.Ingredient{
-					ID:       strconv.Itoa(j),
-					Name:     fmt.Sprintf("Ingredient name %d", j),
-					Quantity: i * j,
-				})
-			}
-			if _, err := d.client.Create(&model.Coffee{
-				ID:          strconv.Itoa(i),
-				Name:        fmt.Sprintf("Name %d", i),
-				Teaser:      fmt.Sprintf("Teaser %d", i),
-				Description: fmt.Sprintf("Description %d", i),
-				Price:       1.1,
-				Ingredient:  ingredients,
-			}); err != nil {
-				return err
-			}
+// sortCoffees sorts the coffees by the given attribute, then by ID to keep the order stable
+func sortCoffees(coffees []*model.Coffee, by string) {
+	sort.Slice(coffees, func(i, j int) bool {
+		a, b := coffees[i], coffees[j]
+		switch {
+		case by == "name" && a.Name != b.Name:
+			return a.Name < b.Name
+		case by == "price" && a.Price != b.Price:
+			return a.Price < b.Price
 		}
-	}
-	return nil
+		return a.ID < b.ID
+	})
 }
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &customerOrdersDataSource{}
+	_ datasource.DataSourceWithConfigure = &customerOrdersDataSource{}
+)
+
+// customerOrdersDataSourceModel maps the data source schema data.
+type customerOrdersDataSourceModel struct {
+	CustomerID    types.String                `tfsdk:"customer_id"`
+	LoyaltyTier   types.String                `tfsdk:"loyalty_tier"`
+	OrderCount    types.Int64                 `tfsdk:"order_count"`
+	LifetimeSpend types.Float64               `tfsdk:"lifetime_spend"`
+	Orders        []customerOrderSummaryModel `tfsdk:"orders"`
+	Store         types.String                `tfsdk:"store"`
+}
+
+// customerOrderSummaryModel maps an order of the customer
+type customerOrderSummaryModel struct {
+	ID          types.String  `tfsdk:"id"`
+	PlacedAt    types.String  `tfsdk:"placed_at"`
+	LoyaltyTier types.String  `tfsdk:"loyalty_tier"`
+	ItemCount   types.Int64   `tfsdk:"item_count"`
+	Subtotal    types.Float64 `tfsdk:"subtotal"`
+	Discount    types.Float64 `tfsdk:"discount"`
+	Tax         types.Float64 `tfsdk:"tax"`
+	Total       types.Float64 `tfsdk:"total"`
+}
+
+// NewCustomerOrdersDataSource is a helper function to simplify the provider implementation.
+func NewCustomerOrdersDataSource() datasource.DataSource {
+	return &customerOrdersDataSource{}
+}
+
+// customerOrdersDataSource aggregates the order history of a customer.
+type customerOrdersDataSource struct {
+	client       client.Client[*model.Order]
+	customers    client.Client[*model.Customer]
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *customerOrdersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeCustomerOrders
+}
+
+// Schema defines the schema for the data source.
+func (d *customerOrdersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The order history of a customer, the oldest order first.",
+		Attributes: map[string]schema.Attribute{
+			"customer_id": schema.StringAttribute{
+				Required: true,
+			},
+			"loyalty_tier": schema.StringAttribute{
+				Computed:    true,
+				Description: "The current loyalty tier of the customer.",
+			},
+			"order_count": schema.Int64Attribute{
+				Computed: true,
+			},
+			"lifetime_spend": schema.Float64Attribute{
+				Computed:    true,
+				Description: "The sum of the totals of the orders, discounts and taxes applied.",
+			},
+			"orders": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"placed_at": schema.StringAttribute{
+							Computed:    true,
+							Description: "When the order was placed, in RFC 3339 format.",
+						},
+						"loyalty_tier": schema.StringAttribute{
+							Computed:    true,
+							Description: "The loyalty tier the order was discounted for.",
+						},
+						"item_count": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The number of coffees ordered.",
+						},
+						"subtotal": schema.Float64Attribute{
+							Computed: true,
+						},
+						"discount": schema.Float64Attribute{
+							Computed: true,
+						},
+						"tax": schema.Float64Attribute{
+							Computed: true,
+						},
+						"total": schema.Float64Attribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *customerOrdersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state customerOrdersDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	customer, err := d.customers.GetByID(state.CustomerID.ValueString())
+	if client.IsNotFound(err) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("customer_id"),
+			"Customer not found",
+			fmt.Sprintf("There is no customer with the ID %q.", state.CustomerID.ValueString()),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Customer",
+			fmt.Sprintf("Could not read customer ID %s: %s", state.CustomerID.ValueString(), err),
+		)
+		return
+	}
+	orders, err := customerOrders(d.client, customer.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Orders",
+			err.Error(),
+		)
+		return
+	}
+
+	spend := 0.0
+	state.Orders = []customerOrderSummaryModel{}
+	for _, o := range orders {
+		items := 0
+		for _, item := range o.Items {
+			items += item.Quantity
+		}
+		summary := customerOrderSummaryModel{
+			ID:          types.StringValue(o.ID),
+			PlacedAt:    types.StringNull(),
+			LoyaltyTier: types.StringValue(string(o.LoyaltyTier)),
+			ItemCount:   types.Int64Value(int64(items)),
+			Subtotal:    types.Float64Value(o.Subtotal),
+			Discount:    types.Float64Value(o.Discount),
+			Tax:         types.Float64Value(o.Tax),
+			Total:       types.Float64Value(o.Total),
+		}
+		if !o.PlacedAt.IsZero() {
+			summary.PlacedAt = types.StringValue(o.PlacedAt.UTC().Format(time.RFC3339))
+		}
+		state.Orders = append(state.Orders, summary)
+		spend += o.Total
+	}
+	state.LoyaltyTier = types.StringValue(string(customer.LoyaltyTier))
+	state.OrderCount = types.Int64Value(int64(len(orders)))
+	state.LifetimeSpend = types.Float64Value(model.RoundCents(spend))
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *customerOrdersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.Order](c, typeOrder)
+	d.customers = client.NewClient[*model.Customer](c, typeCustomer)
+}
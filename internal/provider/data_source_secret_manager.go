@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &secretManagerDataSource{}
+	_ datasource.DataSourceWithConfigure = &secretManagerDataSource{}
+)
+
+// secretManagerDataSourceModel maps the data source schema data.
+// It is also used for the items of the provs_secret_managers data source.
+type secretManagerDataSourceModel struct {
+	ID               types.String          `tfsdk:"id"`
+	Name             types.String          `tfsdk:"name"`
+	VersionRetention types.Int64           `tfsdk:"version_retention"`
+	Secrets          []secretMetadataModel `tfsdk:"secrets"`
+}
+
+// secretManagerLookupModel maps the provs_secret_manager data source schema data: the secret manager and the store it is
+// looked up in.
+type secretManagerLookupModel struct {
+	secretManagerDataSourceModel
+	Store types.String `tfsdk:"store"`
+}
+
+// secretMetadataModel maps the metadata of a secret. It never contains the values of the secret.
+type secretMetadataModel struct {
+	Name             types.String                 `tfsdk:"name"`
+	WriteOnly        types.Bool                   `tfsdk:"write_only"`
+	CurrentVersionID types.String                 `tfsdk:"current_version_id"`
+	ActiveLeases     types.Int64                  `tfsdk:"active_leases"`
+	NextRotationAt   types.String                 `tfsdk:"next_rotation_at"`
+	Versions         []secretVersionMetadataModel `tfsdk:"versions"`
+}
+
+// secretVersionMetadataModel maps the metadata of a version of a secret.
+type secretVersionMetadataModel struct {
+	VersionID types.String `tfsdk:"version_id"`
+	State     types.String `tfsdk:"state"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+// NewSecretManagerDataSource is a helper function to simplify the provider implementation.
+func NewSecretManagerDataSource() datasource.DataSource {
+	return newSecretManagerDataSource(time.Now)
+}
+
+// newSecretManagerDataSource returns the data source counting the active leases with the given clock.
+func newSecretManagerDataSource(now func() time.Time) datasource.DataSource {
+	return &secretManagerDataSource{
+		now: now,
+	}
+}
+
+// secretManagerDataSource looks up a single secret manager by its ID or name. Only the metadata of the
+// secrets is returned, the values are available through the provs_secret ephemeral resource.
+type secretManagerDataSource struct {
+	client client.Client[*model.SecretManager]
+	leases client.Client[*model.SecretLease]
+	names  client.NameIndex
+	// now is the clock telling the active leases. Replaced in tests.
+	now          func() time.Time
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *secretManagerDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeSecretManager
+}
+
+// Schema defines the schema for the data source.
+func (d *secretManagerDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Looks up a secret manager by its ID or name and lists the metadata of its secrets, without their values.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("name")),
+				},
+			},
+			"name": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+			},
+			"version_retention": schema.Int64Attribute{
+				Computed: true,
+			},
+			"secrets": secretMetadataSchema(),
+			"store":   dataSourceStoreSchema(),
+		},
+	}
+}
+
+// secretMetadataSchema returns the schema of the secrets listed by the secret manager data sources
+func secretMetadataSchema() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Computed:    true,
+		Description: "The secrets of the secret manager, sorted by name.",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Computed: true,
+				},
+				"write_only": schema.BoolAttribute{
+					Computed: true,
+				},
+				"current_version_id": schema.StringAttribute{
+					Computed: true,
+				},
+				"active_leases": schema.Int64Attribute{
+					Computed:    true,
+					Description: "The number of provs_secret ephemeral resources currently holding a lease of the secret.",
+				},
+				"next_rotation_at": schema.StringAttribute{
+					Computed:    true,
+					Description: "RFC3339 timestamp of the next rotation. Null when the secret is not rotated by the provider.",
+				},
+				"versions": schema.ListNestedAttribute{
+					Computed:    true,
+					Description: "The versions of the secret, from the oldest to the newest.",
+					NestedObject: schema.NestedAttributeObject{
+						Attributes: map[string]schema.Attribute{
+							"version_id": schema.StringAttribute{
+								Computed: true,
+							},
+							"state": schema.StringAttribute{
+								Computed: true,
+							},
+							"created_at": schema.StringAttribute{
+								Computed:    true,
+								Description: "RFC3339 timestamp of the creation of the version. Null for the versions stored before the secrets were versioned, until a secret of the secret manager is written.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *secretManagerDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state secretManagerLookupModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.ID.ValueString()
+	if !state.Name.IsNull() {
+		var err error
+		id, err = lookupSecretManagerByName(d.client, d.names, state.Name.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Unable to Read SecretManager",
+				fmt.Sprintf("Could not find the secret manager named %q: %s", state.Name.ValueString(), err),
+			)
+			return
+		}
+	}
+	mgr, err := d.client.GetByID(id)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read SecretManager",
+			fmt.Sprintf("Could not read secret manager with id %q: %s", id, err),
+		)
+		return
+	}
+
+	leases, err := d.leases.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read the leases of the secrets",
+			err.Error(),
+		)
+		return
+	}
+
+	state.secretManagerDataSourceModel = secretManagerMetadata(mgr, leases, d.now())
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *secretManagerDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	d.leases = client.NewClient[*model.SecretLease](c, typeSecretLease)
+	d.names = newSecretManagerNameIndex(c)
+}
+
+// secretManagerMetadata maps the secret manager to the data source model, leaving out the values of the secrets.
+// The leases are counted only when active at the given time.
+func secretManagerMetadata(mgr *model.SecretManager, leases []*model.SecretLease, now time.Time) secretManagerDataSourceModel {
+	res := secretManagerDataSourceModel{
+		ID:               types.StringValue(mgr.ID),
+		Name:             types.StringValue(mgr.Name),
+		VersionRetention: types.Int64Value(int64(mgr.Retention())),
+		Secrets:          []secretMetadataModel{},
+	}
+	names := make([]string, 0, len(mgr.Secrets))
+	for name := range mgr.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		secret := mgr.Secrets[name]
+		item := secretMetadataModel{
+			Name:             types.StringValue(name),
+			WriteOnly:        types.BoolValue(secret.WriteOnly),
+			CurrentVersionID: types.StringNull(),
+			ActiveLeases:     types.Int64Value(int64(len(model.ActiveLeases(leases, mgr.ID, name, now)))),
+			NextRotationAt:   rotationTimestamp(secret.Rotation),
+			Versions:         []secretVersionMetadataModel{},
+		}
+		if cur := secret.Current(); cur != nil {
+			item.CurrentVersionID = types.StringValue(cur.ID)
+		}
+		for _, v := range secret.Versions {
+			// the versions stored before the secrets were versioned have no creation time until their next write
+			createdAt := types.StringNull()
+			if !v.CreatedAt.IsZero() {
+				createdAt = types.StringValue(v.CreatedAt.UTC().Format(time.RFC3339))
+			}
+			item.Versions = append(item.Versions, secretVersionMetadataModel{
+				VersionID: types.StringValue(v.ID),
+				State:     types.StringValue(string(v.State)),
+				CreatedAt: createdAt,
+			})
+		}
+		res.Secrets = append(res.Secrets, item)
+	}
+	return res
+}
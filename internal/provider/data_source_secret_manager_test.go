@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSecretManagerDataSource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretManagerDataSourceConfig(storagePath),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.provs_secret_manager.by_name", "id", "provs_secret_manager.payments", "id"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "name", "payments"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "secrets.#", "1"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "secrets.0.name", "db_password"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "secrets.0.current_version_id", "1"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "secrets.0.versions.#", "1"),
+					resource.TestCheckResourceAttr("data.provs_secret_manager.by_id", "secrets.0.versions.0.state", "current"),
+					resource.TestCheckResourceAttrSet("data.provs_secret_manager.by_id", "secrets.0.versions.0.created_at"),
+					resource.TestCheckNoResourceAttr("data.provs_secret_manager.by_id", "secrets.0.versions.0.value"),
+					resource.TestCheckResourceAttr("data.provs_secret_managers.payments", "secret_managers.#", "1"),
+					resource.TestCheckResourceAttr("data.provs_secret_managers.payments", "secret_managers.0.name", "payments"),
+					resource.TestCheckResourceAttr("data.provs_secret_managers.all", "secret_managers.#", "2"),
+					resource.TestCheckResourceAttr("data.provs_secret_managers.all", "secret_managers.0.name", "billing"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSecretManagerDataSourceConfig(storagePath string) string {
+	return testAccProviderConfig(storagePath) + `
+resource "provs_secret_manager" "payments" {
+  name = "payments"
+}
+
+resource "provs_secret_manager" "billing" {
+  name = "billing"
+}
+
+resource "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager.payments.id
+  secret_name       = "db_password"
+  secret            = "s3cr3t"
+}
+
+data "provs_secret_manager" "by_id" {
+  id = provs_secret.test.secret_manager_id
+}
+
+data "provs_secret_manager" "by_name" {
+  name       = provs_secret_manager.payments.name
+  depends_on = [provs_secret.test]
+}
+
+data "provs_secret_managers" "payments" {
+  name_prefix = "pay"
+  depends_on  = [provs_secret.test, provs_secret_manager.billing]
+}
+
+data "provs_secret_managers" "all" {
+  depends_on = [provs_secret.test, provs_secret_manager.billing]
+}
+`
+}
+
+func TestSecretManagerDataSource_legacyName(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// created before the name index existed
+	mgr, err := client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(&model.SecretManager{Name: "legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := testReadDataSource(ctx, t, server, schemas, "provs_secret_manager", map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "legacy"),
+	})
+	if got := testStringAttr(t, state, "id"); got != mgr.ID {
+		t.Fatalf("expected the secret manager %s, got %s", mgr.ID, got)
+	}
+	// reading does not write the index
+	if _, err := backend.ReadAll(typeSecretManager + "_names"); !client.IsNotFound(err) {
+		t.Fatalf("expected no name index, got %v", err)
+	}
+
+	// refreshing the secret manager does
+	testReadResource(ctx, t, server, schemas, "provs_secret_manager", map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, mgr.ID),
+		"name": tftypes.NewValue(tftypes.String, "legacy"),
+	})
+	if id, err := newSecretManagerNameIndex(backend).Lookup("legacy"); err != nil || id != mgr.ID {
+		t.Fatalf("expected the name indexed for %s, got %s (%v)", mgr.ID, id, err)
+	}
+}
+
+func TestSecretManagerDataSource_legacyVersions(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stored before the secrets were versioned
+	if err := backend.CreateWithId(typeSecretManager, "legacy", strings.NewReader(`{"id":"legacy","name":"legacy","secrets":{"db_password":"s3cr3t"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := testReadDataSource(ctx, t, server, schemas, "provs_secret_manager", map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, "legacy"),
+	})
+	var secrets, versions []tftypes.Value
+	var secret, version map[string]tftypes.Value
+	if err := state["secrets"].As(&secrets); err != nil || len(secrets) != 1 {
+		t.Fatalf("expected one secret, got %s (%v)", state["secrets"], err)
+	}
+	if err := secrets[0].As(&secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := secret["versions"].As(&versions); err != nil || len(versions) != 1 {
+		t.Fatalf("expected one version, got %s (%v)", secret["versions"], err)
+	}
+	if err := versions[0].As(&version); err != nil {
+		t.Fatal(err)
+	}
+	if !version["created_at"].IsNull() {
+		t.Fatalf("expected no creation time for the legacy version, got %s", version["created_at"])
+	}
+}
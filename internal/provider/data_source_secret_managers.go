@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &secretManagersDataSource{}
+	_ datasource.DataSourceWithConfigure = &secretManagersDataSource{}
+)
+
+// secretManagersDataSourceModel maps the data source schema data.
+type secretManagersDataSourceModel struct {
+	NamePrefix     types.String                   `tfsdk:"name_prefix"`
+	SecretManagers []secretManagerDataSourceModel `tfsdk:"secret_managers"`
+	Store          types.String                   `tfsdk:"store"`
+}
+
+// NewSecretManagersDataSource is a helper function to simplify the provider implementation.
+func NewSecretManagersDataSource() datasource.DataSource {
+	return newSecretManagersDataSource(time.Now)
+}
+
+// newSecretManagersDataSource returns the data source counting the active leases with the given clock.
+func newSecretManagersDataSource(now func() time.Time) datasource.DataSource {
+	return &secretManagersDataSource{
+		now: now,
+	}
+}
+
+// secretManagersDataSource lists the secret managers with the metadata of their secrets.
+type secretManagersDataSource struct {
+	client client.Client[*model.SecretManager]
+	leases client.Client[*model.SecretLease]
+	// now is the clock telling the active leases. Replaced in tests.
+	now          func() time.Time
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *secretManagersDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeSecretManagers
+}
+
+// Schema defines the schema for the data source.
+func (d *secretManagersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the secret managers and the metadata of their secrets, without their values.",
+		Attributes: map[string]schema.Attribute{
+			"name_prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only the secret managers with the name starting with this prefix are listed.",
+			},
+			"secret_managers": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The secret managers, sorted by name.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"version_retention": schema.Int64Attribute{
+							Computed: true,
+						},
+						"secrets": secretMetadataSchema(),
+					},
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *secretManagersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state secretManagersDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mgrs, err := d.client.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read SecretManagers",
+			err.Error(),
+		)
+		return
+	}
+	leases, err := d.leases.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read the leases of the secrets",
+			err.Error(),
+		)
+		return
+	}
+	now := d.now()
+	sort.Slice(mgrs, func(i, j int) bool {
+		if mgrs[i].Name == mgrs[j].Name {
+			return mgrs[i].ID < mgrs[j].ID
+		}
+		return mgrs[i].Name < mgrs[j].Name
+	})
+
+	state.SecretManagers = []secretManagerDataSourceModel{}
+	for _, mgr := range mgrs {
+		if !strings.HasPrefix(mgr.Name, state.NamePrefix.ValueString()) {
+			continue
+		}
+		state.SecretManagers = append(state.SecretManagers, secretManagerMetadata(mgr, leases, now))
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *secretManagersDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	d.leases = client.NewClient[*model.SecretLease](c, typeSecretLease)
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &stockDataSource{}
+	_ datasource.DataSourceWithConfigure = &stockDataSource{}
+)
+
+// stockDataSourceModel maps the data source schema data.
+type stockDataSourceModel struct {
+	Levels []stockLevelModel `tfsdk:"levels"`
+	Store  types.String      `tfsdk:"store"`
+}
+
+// stockLevelModel maps the stock of an ingredient
+type stockLevelModel struct {
+	IngredientID types.String `tfsdk:"ingredient_id"`
+	Name         types.String `tfsdk:"name"`
+	Unit         types.String `tfsdk:"unit"`
+	Quantity     types.Int64  `tfsdk:"quantity"`
+	Reserved     types.Int64  `tfsdk:"reserved"`
+	Available    types.Int64  `tfsdk:"available"`
+}
+
+// NewStockDataSource is a helper function to simplify the provider implementation.
+func NewStockDataSource() datasource.DataSource {
+	return &stockDataSource{}
+}
+
+// stockDataSource reports the stock levels of the ingredients having an inventory.
+type stockDataSource struct {
+	client       client.Client[*model.Inventory]
+	ingredients  client.Client[*model.Ingredient]
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *stockDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeStock
+}
+
+// Schema defines the schema for the data source.
+func (d *stockDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the current stock levels of the ingredients having a provs_inventory, sorted by ingredient ID.",
+		Attributes: map[string]schema.Attribute{
+			"levels": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"ingredient_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Null when the ingredient does not exist anymore.",
+						},
+						"unit": schema.StringAttribute{
+							Computed:    true,
+							Description: "Null when the ingredient does not exist anymore.",
+						},
+						"quantity": schema.Int64Attribute{
+							Computed: true,
+						},
+						"reserved": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The quantity reserved by the orders.",
+						},
+						"available": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The quantity not reserved by any order.",
+						},
+					},
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *stockDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state stockDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	inventories, err := d.client.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Inventories",
+			err.Error(),
+		)
+		return
+	}
+	ingredients, err := ingredientsByID(d.ingredients)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Ingredients",
+			err.Error(),
+		)
+		return
+	}
+	sort.Slice(inventories, func(i, j int) bool {
+		return inventories[i].ID < inventories[j].ID
+	})
+
+	for _, inv := range inventories {
+		level := stockLevelModel{
+			IngredientID: types.StringValue(inv.ID),
+			Name:         types.StringNull(),
+			Unit:         types.StringNull(),
+			Quantity:     types.Int64Value(int64(inv.Quantity)),
+			Reserved:     types.Int64Value(int64(inv.Reserved())),
+			Available:    types.Int64Value(int64(inv.Available())),
+		}
+		if i, ok := ingredients[inv.ID]; ok {
+			level.Name = types.StringValue(i.Name)
+			level.Unit = types.StringValue(i.Unit)
+		}
+		state.Levels = append(state.Levels, level)
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *stockDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.Inventory](c, typeInventory)
+	d.ingredients = client.NewClient[*model.Ingredient](c, typeIngredient)
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &taxRulesDataSource{}
+	_ datasource.DataSourceWithConfigure = &taxRulesDataSource{}
+)
+
+// taxRulesDataSourceModel maps the data source schema data.
+type taxRulesDataSourceModel struct {
+	Rules map[string]taxRulesRuleModel `tfsdk:"rules"`
+	Store types.String                 `tfsdk:"store"`
+}
+
+// taxRulesRuleModel maps the tax rule of a region
+type taxRulesRuleModel struct {
+	Rate             types.Float64  `tfsdk:"rate"`
+	Inclusive        types.Bool     `tfsdk:"inclusive"`
+	ExemptCategories []types.String `tfsdk:"exempt_categories"`
+}
+
+// NewTaxRulesDataSource is a helper function to simplify the provider implementation.
+func NewTaxRulesDataSource() datasource.DataSource {
+	return &taxRulesDataSource{}
+}
+
+// taxRulesDataSource lists the tax rules, for the compute_region_tax function.
+type taxRulesDataSource struct {
+	client       client.Client[*model.TaxRule]
+	providerData any
+}
+
+// Metadata returns the data source type name.
+func (d *taxRulesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeTaxRules
+}
+
+// Schema defines the schema for the data source.
+func (d *taxRulesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the tax rules of the provs_tax_rule resources, to compute the tax of a region with " +
+			"provider::provs::compute_region_tax(price, category, region, data.provs_tax_rules.all.rules).",
+		Attributes: map[string]schema.Attribute{
+			"rules": schema.MapNestedAttribute{
+				Computed:    true,
+				Description: "The tax rules by region.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"rate": schema.Float64Attribute{
+							Computed: true,
+						},
+						"inclusive": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the prices include the tax.",
+						},
+						"exempt_categories": schema.SetAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"store": dataSourceStoreSchema(),
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *taxRulesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state taxRulesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureDataSourceStore(ctx, d, d.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Rules = map[string]taxRulesRuleModel{}
+
+	rules, err := d.client.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Tax Rules",
+			err.Error(),
+		)
+		return
+	}
+	for _, rule := range rules {
+		res := taxRulesRuleModel{
+			Rate:             types.Float64Value(rule.Rate),
+			Inclusive:        types.BoolValue(rule.Inclusive),
+			ExemptCategories: []types.String{},
+		}
+		for _, category := range rule.ExemptCategories {
+			res.ExemptCategories = append(res.ExemptCategories, types.StringValue(category))
+		}
+		state.Rules[rule.ID] = res
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *taxRulesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = req.ProviderData
+	d.client = client.NewClient[*model.TaxRule](c, typeTaxRule)
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// The ephemeral resources generating values (provs_random, provs_password, provs_keypair and provs_token)
+// share the same lifecycle: the value is valid for a TTL, Terraform renews it for as long as it is used
+// and closes it afterwards. Nothing is stored outside of Terraform.
+
+const (
+	// defaultGeneratedTTL is how long a generated value is valid when the ttl is not configured
+	defaultGeneratedTTL = 2 * time.Minute
+	// generatedPrivateKey is the key of the private data holding the validity of the value between Open, Renew and Close
+	generatedPrivateKey = "generated"
+)
+
+// generatedValue is kept in the private data so that Renew and Close know which value they handle.
+// It never contains the value itself.
+type generatedValue struct {
+	ID        string        `json:"id"`
+	TTL       time.Duration `json:"ttl"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// newGeneratedValue starts the validity of a value generated now. The ttl is already validated by the schema.
+func newGeneratedValue(ttl types.String, now time.Time) generatedValue {
+	d := defaultGeneratedTTL
+	if !ttl.IsNull() {
+		d, _ = time.ParseDuration(ttl.ValueString())
+	}
+	return generatedValue{
+		ID:        uuid.NewString(),
+		TTL:       d,
+		ExpiresAt: now.Add(d),
+	}
+}
+
+func generatedTTLSchema() schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional:    true,
+		Description: fmt.Sprintf("How long the value is valid before it has to be renewed, like \"30s\". Defaults to %s.", defaultGeneratedTTL),
+		Validators: []validator.String{
+			durationValidator{},
+		},
+	}
+}
+
+func generatedExpiresAtSchema() schema.StringAttribute {
+	return schema.StringAttribute{
+		Computed:    true,
+		Description: "When the value expires unless renewed, in RFC 3339 format.",
+	}
+}
+
+// expiresAt returns the expiry in the format of the expires_at attribute
+func (v generatedValue) expiresAt() types.String {
+	return types.StringValue(v.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// open keeps the value in the private data and schedules its renewal. Call it once the result is set.
+func (v generatedValue) open(ctx context.Context, resp *ephemeral.OpenResponse) {
+	resp.Diagnostics.Append(v.set(ctx, resp.Private)...)
+	resp.RenewAt = v.renewAt()
+}
+
+// renewGenerated extends the validity of the value by its TTL. The value itself does not change: Terraform keeps
+// the result of Open for as long as the ephemeral resource is open.
+func renewGenerated(ctx context.Context, kind string, now time.Time, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	v, diags := getGeneratedValue(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if v == nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Failed to renew the %s", kind),
+			fmt.Sprintf("The private data of the %s is missing. Please report this issue to the provider developers.", kind),
+		)
+		return
+	}
+	if !now.Before(v.ExpiresAt) {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Failed to renew the %s", kind),
+			fmt.Sprintf("The %s %q expired at %s", kind, v.ID, v.ExpiresAt.UTC().Format(time.RFC3339)),
+		)
+		return
+	}
+	v.ExpiresAt = now.Add(v.TTL)
+	resp.Diagnostics.Append(v.set(ctx, resp.Private)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "Renewed the "+kind, map[string]any{
+		"id":         v.ID,
+		"expires_at": v.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	resp.RenewAt = v.renewAt()
+}
+
+// closeGenerated releases the value. Nothing is stored outside of Terraform, so there is nothing to revoke.
+func closeGenerated(ctx context.Context, kind string, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	v, diags := getGeneratedValue(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || v == nil {
+		return
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "Released the "+kind, map[string]any{
+		"id": v.ID,
+	})
+}
+
+// renewAt asks Terraform to renew the value halfway through its TTL
+func (v generatedValue) renewAt() time.Time {
+	return v.ExpiresAt.Add(-v.TTL / 2)
+}
+
+func (v generatedValue) set(ctx context.Context, private privateState) diag.Diagnostics {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not write the private data of the generated value: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, generatedPrivateKey, raw)
+}
+
+// getGeneratedValue returns nil when the private data has no generated value
+func getGeneratedValue(ctx context.Context, private privateState) (*generatedValue, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, generatedPrivateKey)
+	if diags.HasError() || len(raw) == 0 {
+		return nil, diags
+	}
+	var res generatedValue
+	if err := json.Unmarshal(raw, &res); err != nil {
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not read the private data of the generated value: %s", err))
+		return nil, diags
+	}
+	return &res, diags
+}
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/generator"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource                     = &keypairEphemeral{}
+	_ ephemeral.EphemeralResourceWithRenew            = &keypairEphemeral{}
+	_ ephemeral.EphemeralResourceWithClose            = &keypairEphemeral{}
+	_ ephemeral.EphemeralResourceWithConfigValidators = &keypairEphemeral{}
+)
+
+// keypairModel maps the ephemeral resource schema data.
+type keypairModel struct {
+	ID                types.String `tfsdk:"id"`
+	TTL               types.String `tfsdk:"ttl"`
+	ExpiresAt         types.String `tfsdk:"expires_at"`
+	Algorithm         types.String `tfsdk:"algorithm"`
+	RSABits           types.Int64  `tfsdk:"rsa_bits"`
+	PrivateKeyPEM     types.String `tfsdk:"private_key_pem"`
+	PublicKeyPEM      types.String `tfsdk:"public_key_pem"`
+	PrivateKeyOpenSSH types.String `tfsdk:"private_key_openssh"`
+	PublicKeyOpenSSH  types.String `tfsdk:"public_key_openssh"`
+	FingerprintSHA256 types.String `tfsdk:"public_key_fingerprint_sha256"`
+}
+
+func NewEphemeralKeypair() ephemeral.EphemeralResource {
+	return newEphemeralKeypair(time.Now)
+}
+
+// newEphemeralKeypair returns the ephemeral resource computing the validity of the key pairs with the given clock.
+func newEphemeralKeypair(now func() time.Time) ephemeral.EphemeralResource {
+	return &keypairEphemeral{
+		now: now,
+	}
+}
+
+type keypairEphemeral struct {
+	// now is the clock used to compute the validity of the key pairs. Replaced in tests.
+	now func() time.Time
+}
+
+func (r *keypairEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeKeypair
+}
+
+// Schema defines the schema for the resource.
+func (r *keypairEphemeral) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Generates a key pair, in the PEM and OpenSSH formats.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"ttl":        generatedTTLSchema(),
+			"expires_at": generatedExpiresAtSchema(),
+			"algorithm": schema.StringAttribute{
+				Required:    true,
+				Description: "One of: " + strings.Join(generator.KeyAlgorithms(), ", "),
+				Validators: []validator.String{
+					stringvalidator.OneOf(generator.KeyAlgorithms()...),
+				},
+			},
+			"rsa_bits": schema.Int64Attribute{
+				Optional:    true,
+				Description: fmt.Sprintf("The size of the RSA key. Defaults to %d.", generator.DefaultRSABits),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(generator.MinRSABits),
+				},
+			},
+			"private_key_pem": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The private key in the PKCS #8 PEM format.",
+			},
+			"public_key_pem": schema.StringAttribute{
+				Computed:    true,
+				Description: "The public key in the PKIX PEM format.",
+			},
+			"private_key_openssh": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The private key in the OpenSSH format.",
+			},
+			"public_key_openssh": schema.StringAttribute{
+				Computed:    true,
+				Description: "The public key in the authorized_keys format.",
+			},
+			"public_key_fingerprint_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "The SHA256 fingerprint of the public key, as shown by ssh-keygen.",
+			},
+		},
+	}
+}
+
+// ConfigValidators checks that rsa_bits is only set for RSA keys.
+func (r *keypairEphemeral) ConfigValidators(_ context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		keypairRSABitsValidator{},
+	}
+}
+
+func (r *keypairEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var cfg keypairModel
+	diags := req.Config.Get(ctx, &cfg)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kp, err := generator.GenerateKeyPair(generator.KeyAlgorithm(cfg.Algorithm.ValueString()), int(cfg.RSABits.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to generate the key pair",
+			err.Error(),
+		)
+		return
+	}
+	v := newGeneratedValue(cfg.TTL, r.now())
+
+	cfg.ID = types.StringValue(v.ID)
+	cfg.ExpiresAt = v.expiresAt()
+	cfg.PrivateKeyPEM = types.StringValue(kp.PrivateKeyPEM)
+	cfg.PublicKeyPEM = types.StringValue(kp.PublicKeyPEM)
+	cfg.PrivateKeyOpenSSH = types.StringValue(kp.PrivateKeyOpenSSH)
+	cfg.PublicKeyOpenSSH = types.StringValue(kp.PublicKeyOpenSSH)
+	cfg.FingerprintSHA256 = types.StringValue(kp.FingerprintSHA256)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	v.open(ctx, resp)
+}
+
+// Renew extends the validity of the key pair by its TTL.
+func (r *keypairEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	renewGenerated(ctx, "key pair", r.now(), req, resp)
+}
+
+// Close releases the key pair.
+func (r *keypairEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	closeGenerated(ctx, "key pair", req, resp)
+}
+
+// keypairRSABitsValidator refuses rsa_bits for the algorithms other than RSA
+type keypairRSABitsValidator struct{}
+
+func (v keypairRSABitsValidator) Description(_ context.Context) string {
+	return "rsa_bits can only be set when the algorithm is rsa"
+}
+
+func (v keypairRSABitsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v keypairRSABitsValidator) ValidateEphemeralResource(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var cfg keypairModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() || cfg.RSABits.IsNull() || cfg.Algorithm.IsNull() || cfg.Algorithm.IsUnknown() {
+		return
+	}
+	if cfg.Algorithm.ValueString() != string(generator.KeyAlgorithmRSA) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rsa_bits"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("%s, got the algorithm %q.", v.Description(ctx), cfg.Algorithm.ValueString()),
+		)
+	}
+}
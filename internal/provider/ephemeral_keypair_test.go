@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestAccKeypairEphemeral_rsaBitsWithED25519(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_keypair" "test" {
+  algorithm = "ed25519"
+  rsa_bits  = 4096
+}
+`,
+				ExpectError: regexp.MustCompile(`rsa_bits can only be set when the algorithm is rsa`),
+			},
+		},
+	})
+}
+
+func TestKeypairEphemeral(t *testing.T) {
+	ctx := context.Background()
+	server, schemas := testProtocolServer(ctx, t, t.TempDir(), time.Now)
+	for _, tc := range []struct {
+		algorithm string
+		rsaBits   tftypes.Value
+		check     func(t *testing.T, private any)
+	}{
+		{
+			algorithm: "ed25519",
+			rsaBits:   tftypes.NewValue(tftypes.Number, nil),
+			check: func(t *testing.T, private any) {
+				if _, ok := private.(ed25519.PrivateKey); !ok {
+					t.Fatalf("expected an ed25519 key, got %T", private)
+				}
+			},
+		},
+		{
+			algorithm: "rsa",
+			rsaBits:   tftypes.NewValue(tftypes.Number, 2048),
+			check: func(t *testing.T, private any) {
+				key, ok := private.(*rsa.PrivateKey)
+				if !ok {
+					t.Fatalf("expected an RSA key, got %T", private)
+				}
+				if key.N.BitLen() != 2048 {
+					t.Fatalf("expected a key of 2048 bits, got %d", key.N.BitLen())
+				}
+			},
+		},
+	} {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			attrs, _ := testOpenEphemeral(ctx, t, server, schemas, "provs_keypair", map[string]tftypes.Value{
+				"algorithm": tftypes.NewValue(tftypes.String, tc.algorithm),
+				"rsa_bits":  tc.rsaBits,
+			})
+
+			block, _ := pem.Decode([]byte(testStringAttr(t, attrs, "private_key_pem")))
+			if block == nil {
+				t.Fatal("the private key is not PEM encoded")
+			}
+			private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, private)
+
+			sshPrivate, err := ssh.ParsePrivateKey([]byte(testStringAttr(t, attrs, "private_key_openssh")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sshPublic, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testStringAttr(t, attrs, "public_key_openssh")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := ssh.FingerprintSHA256(sshPrivate.PublicKey()), ssh.FingerprintSHA256(sshPublic); got != want {
+				t.Fatalf("the OpenSSH keys do not match: %s != %s", got, want)
+			}
+			if got := testStringAttr(t, attrs, "public_key_fingerprint_sha256"); got != ssh.FingerprintSHA256(sshPublic) {
+				t.Fatalf("unexpected fingerprint %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-provs/internal/generator"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource          = &passwordEphemeral{}
+	_ ephemeral.EphemeralResourceWithRenew = &passwordEphemeral{}
+	_ ephemeral.EphemeralResourceWithClose = &passwordEphemeral{}
+)
+
+// passwordModel maps the ephemeral resource schema data.
+type passwordModel struct {
+	ID              types.String `tfsdk:"id"`
+	Value           types.String `tfsdk:"value"`
+	TTL             types.String `tfsdk:"ttl"`
+	ExpiresAt       types.String `tfsdk:"expires_at"`
+	Length          types.Int64  `tfsdk:"length"`
+	Upper           types.Bool   `tfsdk:"upper"`
+	Lower           types.Bool   `tfsdk:"lower"`
+	Numeric         types.Bool   `tfsdk:"numeric"`
+	Special         types.Bool   `tfsdk:"special"`
+	OverrideSpecial types.String `tfsdk:"override_special"`
+	Exclude         types.String `tfsdk:"exclude"`
+	MinUpper        types.Int64  `tfsdk:"min_upper"`
+	MinLower        types.Int64  `tfsdk:"min_lower"`
+	MinNumeric      types.Int64  `tfsdk:"min_numeric"`
+	MinSpecial      types.Int64  `tfsdk:"min_special"`
+}
+
+func NewEphemeralPassword() ephemeral.EphemeralResource {
+	return newEphemeralPassword(time.Now)
+}
+
+// newEphemeralPassword returns the ephemeral resource computing the validity of the passwords with the given clock.
+func newEphemeralPassword(now func() time.Time) ephemeral.EphemeralResource {
+	return &passwordEphemeral{
+		now: now,
+	}
+}
+
+type passwordEphemeral struct {
+	// now is the clock used to compute the validity of the passwords. Replaced in tests.
+	now func() time.Time
+}
+
+func (r *passwordEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typePassword
+}
+
+// Schema defines the schema for the resource.
+func (r *passwordEphemeral) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	minValidators := []validator.Int64{
+		int64validator.AtLeast(0),
+	}
+	resp.Schema = schema.Schema{
+		Description: "Generates a password following a policy. All the character sets are enabled unless disabled.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"value": schema.StringAttribute{
+				Computed:  true,
+				Sensitive: true,
+			},
+			"ttl":        generatedTTLSchema(),
+			"expires_at": generatedExpiresAtSchema(),
+			"length": schema.Int64Attribute{
+				Required: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"upper": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the password contains upper case characters. Defaults to true.",
+			},
+			"lower": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the password contains lower case characters. Defaults to true.",
+			},
+			"numeric": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the password contains numeric characters. Defaults to true.",
+			},
+			"special": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the password contains special characters. Defaults to true.",
+			},
+			"override_special": schema.StringAttribute{
+				Optional:    true,
+				Description: "Replaces the special characters, " + generator.CharsetSpecial + " by default.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"exclude": schema.StringAttribute{
+				Optional:    true,
+				Description: `The characters that never appear in the password, like "0O1l".`,
+			},
+			"min_upper": schema.Int64Attribute{
+				Optional:   true,
+				Validators: minValidators,
+			},
+			"min_lower": schema.Int64Attribute{
+				Optional:   true,
+				Validators: minValidators,
+			},
+			"min_numeric": schema.Int64Attribute{
+				Optional:   true,
+				Validators: minValidators,
+			},
+			"min_special": schema.Int64Attribute{
+				Optional:   true,
+				Validators: minValidators,
+			},
+		},
+	}
+}
+
+func (r *passwordEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var cfg passwordModel
+	diags := req.Config.Get(ctx, &cfg)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	res, err := generator.Password(cfg.policy())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to generate the password",
+			err.Error(),
+		)
+		return
+	}
+	v := newGeneratedValue(cfg.TTL, r.now())
+
+	cfg.ID = types.StringValue(v.ID)
+	cfg.Value = types.StringValue(res)
+	cfg.ExpiresAt = v.expiresAt()
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	v.open(ctx, resp)
+}
+
+// Renew extends the validity of the password by its TTL.
+func (r *passwordEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	renewGenerated(ctx, "password", r.now(), req, resp)
+}
+
+// Close releases the password.
+func (r *passwordEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	closeGenerated(ctx, "password", req, resp)
+}
+
+func (m *passwordModel) policy() generator.PasswordPolicy {
+	// the character sets are enabled unless disabled explicitly
+	enabled := func(v types.Bool) bool {
+		return v.IsNull() || v.ValueBool()
+	}
+	return generator.PasswordPolicy{
+		Length:          int(m.Length.ValueInt64()),
+		Upper:           enabled(m.Upper),
+		Lower:           enabled(m.Lower),
+		Numeric:         enabled(m.Numeric),
+		Special:         enabled(m.Special),
+		OverrideSpecial: m.OverrideSpecial.ValueString(),
+		Exclude:         m.Exclude.ValueString(),
+		MinUpper:        int(m.MinUpper.ValueInt64()),
+		MinLower:        int(m.MinLower.ValueInt64()),
+		MinNumeric:      int(m.MinNumeric.ValueInt64()),
+		MinSpecial:      int(m.MinSpecial.ValueInt64()),
+	}
+}
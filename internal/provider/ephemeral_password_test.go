@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// The generated values are ephemeral, so they can only be written to write-only attributes
+func TestAccPasswordEphemeral_secretWriteOnly(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `
+  secret_wo         = ephemeral.provs_password.test.value
+  secret_wo_version = 1`) + `
+ephemeral "provs_password" "test" {
+  length  = 20
+  special = false
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("provs_secret.test", tfjsonpath.New("has_secret_wo"), knownvalue.Bool(true)),
+					statecheck.ExpectKnownValue("provs_secret.test", tfjsonpath.New("version_id"), knownvalue.StringExact("1")),
+				},
+			},
+		},
+	})
+}
+
+func TestAccPasswordEphemeral_invalidPolicy(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_password" "test" {
+  length      = 4
+  min_upper   = 3
+  min_numeric = 3
+}
+
+provider "echo" {
+  data = ephemeral.provs_password.test.value
+}
+
+resource "echo" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`exceeds the length of the password`),
+			},
+		},
+	})
+}
+
+func TestPasswordEphemeral_policy(t *testing.T) {
+	ctx := context.Background()
+	server, schemas := testProtocolServer(ctx, t, t.TempDir(), time.Now)
+	attrs, _ := testOpenEphemeral(ctx, t, server, schemas, "provs_password", map[string]tftypes.Value{
+		"length":      tftypes.NewValue(tftypes.Number, 32),
+		"lower":       tftypes.NewValue(tftypes.Bool, false),
+		"exclude":     tftypes.NewValue(tftypes.String, "0O1I"),
+		"min_numeric": tftypes.NewValue(tftypes.Number, 5),
+		"min_special": tftypes.NewValue(tftypes.Number, 2),
+	})
+	value := testStringAttr(t, attrs, "value")
+	if len(value) != 32 {
+		t.Fatalf("expected a password of 32 characters, got %d", len(value))
+	}
+	if strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz0O1I") {
+		t.Fatalf("the password %q contains lower case or excluded characters", value)
+	}
+	if n := len(regexp.MustCompile(`[0-9]`).FindAllString(value, -1)); n < 5 {
+		t.Fatalf("expected at least 5 numeric characters, got %d", n)
+	}
+	if n := len(regexp.MustCompile(`[^A-Z0-9]`).FindAllString(value, -1)); n < 2 {
+		t.Fatalf("expected at least 2 special characters, got %d", n)
+	}
+}
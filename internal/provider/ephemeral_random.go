@@ -2,12 +2,16 @@ package provider
 
 import (
 	"context"
-	"fmt"
+	"terraform-provider-provs/internal/generator"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -15,20 +19,38 @@ import (
 var (
 	_ ephemeral.EphemeralResource          = &randomEphemeral{}
 	_ ephemeral.EphemeralResourceWithRenew = &randomEphemeral{}
+	_ ephemeral.EphemeralResourceWithClose = &randomEphemeral{}
 )
 
-// orderResourceModel maps the resource schema data.
+// randomModel maps the ephemeral resource schema data.
 type randomModel struct {
-	ID     types.String `tfsdk:"id"`
-	Value  types.String `tfsdk:"value"`
-	Prefix types.String `tfsdk:"prefix"`
+	ID         types.String `tfsdk:"id"`
+	Value      types.String `tfsdk:"value"`
+	Prefix     types.String `tfsdk:"prefix"`
+	TTL        types.String `tfsdk:"ttl"`
+	ExpiresAt  types.String `tfsdk:"expires_at"`
+	Length     types.Int64  `tfsdk:"length"`
+	Charset    types.String `tfsdk:"charset"`
+	Special    types.Bool   `tfsdk:"special"`
+	MinUpper   types.Int64  `tfsdk:"min_upper"`
+	MinNumeric types.Int64  `tfsdk:"min_numeric"`
 }
 
 func NewEphemeralRandom() ephemeral.EphemeralResource {
-	return &randomEphemeral{}
+	return newEphemeralRandom(time.Now)
 }
 
-type randomEphemeral struct{}
+// newEphemeralRandom returns the ephemeral resource computing the validity of the values with the given clock.
+func newEphemeralRandom(now func() time.Time) ephemeral.EphemeralResource {
+	return &randomEphemeral{
+		now: now,
+	}
+}
+
+type randomEphemeral struct {
+	// now is the clock used to compute the validity of the values. Replaced in tests.
+	now func() time.Time
+}
 
 func (r *randomEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_" + typeRandom
@@ -37,15 +59,61 @@ func (r *randomEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequ
 // Schema defines the schema for the resource.
 func (r *randomEphemeral) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "Generates a random value, a UUID unless the length is set. " +
+			"The value is valid for the ttl and Terraform renews it for as long as it is used.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
 			},
 			"value": schema.StringAttribute{
-				Computed: true,
+				Computed:  true,
+				Sensitive: true,
 			},
 			"prefix": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: `Prepended to the value, separated by "-".`,
+			},
+			"ttl":        generatedTTLSchema(),
+			"expires_at": generatedExpiresAtSchema(),
+			"length": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The number of characters of the value. When set, a password is generated instead of a UUID.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"charset": schema.StringAttribute{
+				Optional:    true,
+				Description: "The characters the value is made of, replacing the upper, lower and numeric characters.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("length")),
+				},
+			},
+			"special": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the value contains special characters. Defaults to false.",
+				Validators: []validator.Bool{
+					boolvalidator.AlsoRequires(path.MatchRoot("length")),
+				},
+			},
+			"min_upper": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The minimum number of upper case characters.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AlsoRequires(path.MatchRoot("length")),
+					int64validator.ConflictsWith(path.MatchRoot("charset")),
+				},
+			},
+			"min_numeric": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The minimum number of numeric characters.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AlsoRequires(path.MatchRoot("length")),
+					int64validator.ConflictsWith(path.MatchRoot("charset")),
+				},
 			},
 		},
 	}
@@ -59,31 +127,56 @@ func (r *randomEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, r
 		return
 	}
 
-	res := generateSecret([]byte(cfg.Prefix.ValueString()))
+	res, err := cfg.generate()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to generate the random value",
+			err.Error(),
+		)
+		return
+	}
+	v := newGeneratedValue(cfg.TTL, r.now())
+
+	cfg.ID = types.StringValue(v.ID)
 	cfg.Value = types.StringValue(res)
+	cfg.ExpiresAt = v.expiresAt()
 	resp.Diagnostics.Append(resp.Result.Set(ctx, &cfg)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	resp.RenewAt = time.Now().Add(2 * time.Minute)
+	v.open(ctx, resp)
 }
 
+// Renew extends the validity of the value by its TTL.
 func (r *randomEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
-	val, diags := req.Private.GetKey(ctx, "prefix")
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-	res := generateSecret(val)
+	renewGenerated(ctx, "random value", r.now(), req, resp)
+}
 
-	resp.RenewAt = time.Now().Add(2 * time.Minute)
-	resp.Diagnostics.Append(resp.Private.SetKey(ctx, "value", []byte(res))...)
+// Close releases the value.
+func (r *randomEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	closeGenerated(ctx, "random value", req, resp)
 }
 
-func generateSecret(prefix []byte) string {
-	res := uuid.NewString()
-	if len(prefix) > 0 {
-		res = fmt.Sprintf("%s-%s", prefix, res)
+// generate returns a new value as configured
+func (m *randomModel) generate() (string, error) {
+	if m.Length.IsNull() {
+		return generator.UUID(m.Prefix.ValueString()), nil
+	}
+	res, err := generator.Password(generator.PasswordPolicy{
+		Length:     int(m.Length.ValueInt64()),
+		Charset:    m.Charset.ValueString(),
+		Upper:      true,
+		Lower:      true,
+		Numeric:    true,
+		Special:    m.Special.ValueBool(),
+		MinUpper:   int(m.MinUpper.ValueInt64()),
+		MinNumeric: int(m.MinNumeric.ValueInt64()),
+	})
+	if err != nil {
+		return "", err
+	}
+	if prefix := m.Prefix.ValueString(); prefix != "" {
+		res = prefix + "-" + res
 	}
-	return res
+	return res, nil
 }
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccRandomEphemeral(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_random" "uuid" {
+  prefix = "test"
+}
+
+ephemeral "provs_random" "password" {
+  length      = 24
+  special     = true
+  min_upper   = 4
+  min_numeric = 4
+  ttl         = "30s"
+}
+
+ephemeral "provs_random" "charset" {
+  length  = 16
+  charset = "ab"
+}
+
+provider "echo" {
+  data = {
+    uuid     = ephemeral.provs_random.uuid.value
+    password = ephemeral.provs_random.password.value
+    charset  = ephemeral.provs_random.charset.value
+  }
+}
+
+resource "echo" "test" {}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("uuid"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^test-[0-9a-f-]{36}$`))),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("password"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^(?:.*[A-Z]){4}`))),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("password"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^.{24}$`))),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("charset"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^[ab]{16}$`))),
+				},
+			},
+		},
+	})
+}
+
+func TestAccRandomEphemeral_invalidConfig(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_random" "test" {
+  min_upper = 2
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_random" "test" {
+  length    = 2
+  min_upper = 3
+}
+`,
+				ExpectError: regexp.MustCompile(`exceeds the length of the password`),
+			},
+		},
+	})
+}
+
+func TestRandomEphemeral_renew(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	server, schemas := testProtocolServer(ctx, t, t.TempDir(), func() time.Time { return now })
+	randomSchema := schemas.EphemeralResourceSchemas["provs_random"]
+
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "provs_random",
+		Config: testDynamicValue(t, randomSchema, map[string]tftypes.Value{
+			"ttl":    tftypes.NewValue(tftypes.String, "1m"),
+			"length": tftypes.NewValue(tftypes.Number, 12),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, openResp.Diagnostics)
+	if want := now.Add(30 * time.Second); !openResp.RenewAt.Equal(want) {
+		t.Fatalf("expected the renewal at %s, got %s", want, openResp.RenewAt)
+	}
+	result, err := openResp.Result.Unmarshal(randomSchema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := result.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	var value string
+	if err := attrs["value"].As(&value); err != nil {
+		t.Fatal(err)
+	}
+	if len(value) != 12 {
+		t.Fatalf("expected a value of 12 characters, got %d", len(value))
+	}
+
+	// every renewal extends the validity from the time of the renewal
+	private := openResp.Private
+	for i := 0; i < 3; i++ {
+		now = now.Add(30 * time.Second)
+		renewResp, err := server.RenewEphemeralResource(ctx, &tfprotov6.RenewEphemeralResourceRequest{
+			TypeName: "provs_random",
+			Private:  private,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		testNoDiagnostics(t, renewResp.Diagnostics)
+		if want := now.Add(30 * time.Second); !renewResp.RenewAt.Equal(want) {
+			t.Fatalf("expected the renewal at %s, got %s", want, renewResp.RenewAt)
+		}
+		private = renewResp.Private
+	}
+
+	// a value that expired cannot be renewed anymore
+	now = now.Add(2 * time.Minute)
+	renewResp, err := server.RenewEphemeralResource(ctx, &tfprotov6.RenewEphemeralResourceRequest{
+		TypeName: "provs_random",
+		Private:  private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renewResp.Diagnostics) == 0 || renewResp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityError {
+		t.Fatal("expected the renewal of the expired value to fail")
+	}
+
+	closeResp, err := server.CloseEphemeralResource(ctx, &tfprotov6.CloseEphemeralResourceRequest{
+		TypeName: "provs_random",
+		Private:  private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, closeResp.Diagnostics)
+}
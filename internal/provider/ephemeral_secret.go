@@ -2,27 +2,76 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"terraform-provider-provs/internal/audit"
 	"terraform-provider-provs/internal/client"
 	"terraform-provider-provs/internal/model"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
 	_ ephemeral.EphemeralResource              = &secretEphemeral{}
 	_ ephemeral.EphemeralResourceWithConfigure = &secretEphemeral{}
+	_ ephemeral.EphemeralResourceWithRenew     = &secretEphemeral{}
+	_ ephemeral.EphemeralResourceWithClose     = &secretEphemeral{}
+)
+
+const (
+	// defaultSecretLeaseTTL is the TTL of the leases when the lease_ttl is not configured
+	defaultSecretLeaseTTL = 5 * time.Minute
+	// secretLeasePrivateKey is the private data key holding the ID of the lease between Open, Renew and Close
+	secretLeasePrivateKey = "lease_id"
+	// secretStorePrivateKey is the private data key holding the store of the lease between Open, Renew and Close
+	secretStorePrivateKey = "store"
 )
 
+// secretEphemeralModel maps the ephemeral resource schema data.
+type secretEphemeralModel struct {
+	SecretManagerID types.String `tfsdk:"secret_manager_id"`
+	SecretName      types.String `tfsdk:"secret_name"`
+	Version         types.String `tfsdk:"version"`
+	Secret          types.String `tfsdk:"secret"`
+	LeaseTTL        types.String `tfsdk:"lease_ttl"`
+	LeaseID         types.String `tfsdk:"lease_id"`
+	LeaseExpiresAt  types.String `tfsdk:"lease_expires_at"`
+	Store           types.String `tfsdk:"store"`
+}
+
 func NewEphemeralSecret() ephemeral.EphemeralResource {
-	return &secretEphemeral{}
+	return newEphemeralSecret(time.Now)
+}
+
+// newEphemeralSecret returns the ephemeral resource handling the leases with the given clock.
+func newEphemeralSecret(now func() time.Time) ephemeral.EphemeralResource {
+	return &secretEphemeral{
+		now: now,
+	}
 }
 
-// This ephemeral resource is responsible with reading the secret from the secret manager to be able to be used as an ephemeral value
+// This ephemeral resource is responsible with reading the secret from the secret manager to be able to be used as an ephemeral value.
+// Every open secret holds a lease, that is kept while Terraform renews it and is revoked on Close.
 type secretEphemeral struct {
 	client client.Client[*model.SecretManager]
+	leases client.Client[*model.SecretLease]
+	// now is the clock used for the leases. Replaced in tests.
+	now func() time.Time
+	// principal is checked against the policy of the secret manager
+	principal string
+	// audit records every read of a secret
+	audit *audit.Log
+	// providerData is the data the store of the secret is selected in
+	providerData any
 }
 
 func (r *secretEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
@@ -39,17 +88,40 @@ func (r *secretEphemeral) Schema(_ context.Context, _ ephemeral.SchemaRequest, r
 			"secret_name": schema.StringAttribute{
 				Required: true,
 			},
+			"version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the version to read. When not set, the current version is read.",
+			},
 			"secret": schema.StringAttribute{
-				Computed: true,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"lease_ttl": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("How long the lease of the secret lasts without being renewed, like \"10m\". Defaults to %q.", defaultSecretLeaseTTL),
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"lease_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the lease held while the secret is open.",
+			},
+			"lease_expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "RFC3339 timestamp of the moment the lease expires, if not renewed.",
 			},
+			"store": ephemeralStoreSchema(),
 		},
 	}
 }
 
 func (r *secretEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
-	var cfg secretModel
+	var cfg secretEphemeralModel
 	diags := req.Config.Get(ctx, &cfg)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureEphemeralStore(ctx, r, r.providerData, cfg.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -61,6 +133,11 @@ func (r *secretEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, r
 		)
 		return
 	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessRead, cfg.SecretName.ValueString(), model.AuditOperationRead)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
 	secret, ok := mgr.Secrets[cfg.SecretName.ValueString()]
 	if !ok {
 		resp.Diagnostics.AddError(
@@ -72,8 +149,214 @@ func (r *secretEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, r
 		)
 		return
 	}
-	cfg.Secret = types.StringValue(secret)
+	version := secret.Current()
+	if !cfg.Version.IsNull() {
+		version, ok = secret.Version(cfg.Version.ValueString())
+		if !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("version"),
+				"Failed to retrieve the secret version from secret manager",
+				fmt.Sprintf(
+					"Error retrieving version %q of secret %q from secret manager for id %q: no such version existing",
+					cfg.Version.ValueString(),
+					cfg.SecretName.ValueString(),
+					cfg.SecretManagerID.ValueString()),
+			)
+			return
+		}
+	}
+	if version == nil || version.State == model.SecretVersionDestroyed {
+		resp.Diagnostics.AddError(
+			"Failed to retrieve the secret version from secret manager",
+			fmt.Sprintf(
+				"The requested version of secret %q from secret manager for id %q is destroyed and has no value anymore",
+				cfg.SecretName.ValueString(),
+				cfg.SecretManagerID.ValueString()),
+		)
+		return
+	}
+	ttl := defaultSecretLeaseTTL
+	if !cfg.LeaseTTL.IsNull() {
+		// already validated by the schema
+		ttl, _ = time.ParseDuration(cfg.LeaseTTL.ValueString())
+	}
+	now := r.now()
+	lease := &model.SecretLease{
+		ID:              uuid.NewString(),
+		SecretManagerID: cfg.SecretManagerID.ValueString(),
+		SecretName:      cfg.SecretName.ValueString(),
+		VersionID:       version.ID,
+		Principal:       r.principal,
+		TTL:             ttl,
+		CreatedAt:       now,
+	}
+	lease.Renew(now)
+	if _, err := r.leases.Create(lease); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to lease the secret",
+			fmt.Sprintf("Error creating the lease of secret %q from secret manager for id %q: %s", lease.SecretName, lease.SecretManagerID, err),
+		)
+		return
+	}
+	r.revokeExpiredLeases(ctx, now)
+	if !access.succeeded(&resp.Diagnostics) {
+		_ = r.leases.Delete(lease.ID)
+		return
+	}
+
+	cfg.Version = types.StringValue(version.ID)
+	cfg.Secret = types.StringValue(version.Value)
+	cfg.LeaseID = types.StringValue(lease.ID)
+	cfg.LeaseExpiresAt = types.StringValue(lease.ExpiresAt.UTC().Format(time.RFC3339))
+	tflog.SubsystemDebug(ctx, logSubsystem, "Read secret from secret manager", map[string]any{
+		"secret_manager_id": cfg.SecretManagerID.ValueString(),
+		"secret_name":       cfg.SecretName.ValueString(),
+		"version":           cfg.Version.ValueString(),
+		"lease_id":          lease.ID,
+	})
 	resp.Diagnostics.Append(resp.Result.Set(ctx, &cfg)...)
+	resp.Diagnostics.Append(setSecretLeaseID(ctx, resp.Private, lease.ID)...)
+	resp.Diagnostics.Append(setSecretLeaseStore(ctx, resp.Private, cfg.Store)...)
+	if resp.Diagnostics.HasError() {
+		// a failed Open is never closed, the lease would count as an active reader until it expires
+		_ = r.leases.Delete(lease.ID)
+		return
+	}
+	resp.RenewAt = secretLeaseRenewAt(lease)
+}
+
+// Renew extends the lease of the secret by its TTL.
+func (r *secretEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	leaseID, diags := getSecretLeaseID(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || leaseID == "" {
+		return
+	}
+	store, diags := getSecretLeaseStore(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureEphemeralStore(ctx, r, r.providerData, store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	now := r.now()
+	lease, err := r.leases.GetByID(leaseID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to renew the lease of the secret",
+			fmt.Sprintf("Error reading the lease %q: %s", leaseID, err),
+		)
+		return
+	}
+	if !lease.Active(now) {
+		resp.Diagnostics.AddError(
+			"Failed to renew the lease of the secret",
+			fmt.Sprintf("The lease %q of secret %q expired at %s", leaseID, lease.SecretName, lease.ExpiresAt.UTC().Format(time.RFC3339)),
+		)
+		return
+	}
+	lease.Renew(now)
+	if err := r.leases.Update(lease); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to renew the lease of the secret",
+			fmt.Sprintf("Error updating the lease %q: %s", leaseID, err),
+		)
+		return
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "Renewed the lease of the secret", map[string]any{
+		"lease_id":   leaseID,
+		"expires_at": lease.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	resp.RenewAt = secretLeaseRenewAt(lease)
+}
+
+// Close revokes the lease of the secret. A lease that is gone already is not an error.
+func (r *secretEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	leaseID, diags := getSecretLeaseID(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || leaseID == "" {
+		return
+	}
+	store, diags := getSecretLeaseStore(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureEphemeralStore(ctx, r, r.providerData, store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.leases.Delete(leaseID); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to revoke the lease of the secret",
+			fmt.Sprintf("Error deleting the lease %q: %s", leaseID, err),
+		)
+		return
+	}
+	tflog.SubsystemDebug(ctx, logSubsystem, "Revoked the lease of the secret", map[string]any{"lease_id": leaseID})
+}
+
+// revokeExpiredLeases removes the leases of the readers that were never closed.
+// Failing to do so is not an error since the expired leases are not counted as active anyway.
+func (r *secretEphemeral) revokeExpiredLeases(ctx context.Context, now time.Time) {
+	leases, err := r.leases.GetAll()
+	if err != nil {
+		return
+	}
+	for _, l := range leases {
+		if l.Active(now) {
+			continue
+		}
+		if err := r.leases.Delete(l.ID); err != nil && !client.IsNotFound(err) {
+			tflog.SubsystemWarn(ctx, logSubsystem, "Failed to remove the expired lease", map[string]any{"lease_id": l.ID, "error": err.Error()})
+		}
+	}
+}
+
+// secretLeaseRenewAt asks Terraform to renew the lease halfway through its TTL
+func secretLeaseRenewAt(lease *model.SecretLease) time.Time {
+	return lease.ExpiresAt.Add(-lease.TTL / 2)
+}
+
+func setSecretLeaseID(ctx context.Context, private privateState, id string) diag.Diagnostics {
+	raw, err := json.Marshal(id)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not write the ID of the lease: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, secretLeasePrivateKey, raw)
+}
+
+func getSecretLeaseID(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, secretLeasePrivateKey)
+	if diags.HasError() || len(raw) == 0 {
+		return "", diags
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil {
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not read the ID of the lease: %s", err))
+	}
+	return id, diags
+}
+
+// setSecretLeaseStore keeps the store of the lease, null for the default store
+func setSecretLeaseStore(ctx context.Context, private privateState, store types.String) diag.Diagnostics {
+	raw, err := json.Marshal(store.ValueStringPointer())
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not write the store of the lease: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, secretStorePrivateKey, raw)
+}
+
+func getSecretLeaseStore(ctx context.Context, private privateState) (types.String, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, secretStorePrivateKey)
+	if diags.HasError() || len(raw) == 0 {
+		return types.StringNull(), diags
+	}
+	var store *string
+	if err := json.Unmarshal(raw, &store); err != nil {
+		diags.AddError("Invalid private data", fmt.Sprintf("Could not read the store of the lease: %s", err))
+	}
+	return types.StringPointerValue(store), diags
 }
 
 // Configure adds the provider configured client to the resource.
@@ -93,6 +376,10 @@ func (r *secretEphemeral) Configure(_ context.Context, req ephemeral.ConfigureRe
 		return
 	}
 
+	r.providerData = req.ProviderData
 	// typeSecretManager because it reads data from there
 	r.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	r.leases = client.NewClient[*model.SecretLease](c, typeSecretLease)
+	r.principal = principalFrom(req.ProviderData)
+	r.audit = auditLogFrom(req.ProviderData)
 }
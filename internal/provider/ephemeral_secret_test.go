@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSecretEphemeral_versions(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 2, "v1", ""),
+			},
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 2, "v2", ""),
+			},
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 2, "v2", `
+ephemeral "provs_secret" "current" {
+  secret_manager_id = provs_secret.test.secret_manager_id
+  secret_name       = provs_secret.test.secret_name
+}
+
+ephemeral "provs_secret" "first" {
+  secret_manager_id = provs_secret.test.secret_manager_id
+  secret_name       = provs_secret.test.secret_name
+  version           = "1"
+}
+
+provider "echo" {
+  data = {
+    current         = ephemeral.provs_secret.current.secret
+    current_version = ephemeral.provs_secret.current.version
+    first           = ephemeral.provs_secret.first.secret
+  }
+}
+
+resource "echo" "test" {}
+`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("provs_secret.test", tfjsonpath.New("version_id"), knownvalue.StringExact("2")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("current"), knownvalue.StringExact("v2")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("current_version"), knownvalue.StringExact("2")),
+					statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data").AtMapKey("first"), knownvalue.StringExact("v1")),
+				},
+			},
+		},
+	})
+}
+
+func TestAccSecretEphemeral_destroyedVersion(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 1, "v1", ""),
+			},
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 1, "v2", ""),
+			},
+			{
+				Config: testAccSecretEphemeralConfig(storagePath, 1, "v2", `
+ephemeral "provs_secret" "first" {
+  secret_manager_id = provs_secret.test.secret_manager_id
+  secret_name       = provs_secret.test.secret_name
+  version           = "1"
+}
+
+provider "echo" {
+  data = ephemeral.provs_secret.first.secret
+}
+
+resource "echo" "test" {}
+`),
+				ExpectError: regexp.MustCompile(`is destroyed and has no value anymore`),
+			},
+		},
+	})
+}
+
+func testAccSecretEphemeralConfig(storagePath string, retention int, value string, extra string) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_secret_manager" "test" {
+  name              = "test"
+  version_retention = %d
+}
+
+resource "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager.test.id
+  secret_name       = "api_key"
+  secret            = %q
+}
+%s`, retention, value, extra)
+}
+
+func TestSecretEphemeral_lease(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", "v1", false, now)
+	mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leases := client.NewClient[*model.SecretLease](backend, typeSecretLease)
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, func() time.Time { return now })
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Config: testDynamicValue(t, schemas.EphemeralResourceSchemas["provs_secret"], map[string]tftypes.Value{
+			"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+			"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+			"lease_ttl":         tftypes.NewValue(tftypes.String, "10m"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, openResp.Diagnostics)
+	if want := now.Add(5 * time.Minute); !openResp.RenewAt.Equal(want) {
+		t.Fatalf("expected the renewal at %s, got %s", want, openResp.RenewAt)
+	}
+	all, err := leases.GetAll()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("expected one lease, got %d (%v)", len(all), err)
+	}
+	lease := all[0]
+	if got := model.ActiveLeases(all, mgr.ID, "db_password", now); len(got) != 1 {
+		t.Fatalf("expected the lease to be active, got %d active leases", len(got))
+	}
+
+	now = now.Add(5 * time.Minute)
+	renewResp, err := server.RenewEphemeralResource(ctx, &tfprotov6.RenewEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, renewResp.Diagnostics)
+	if lease, err = leases.GetByID(lease.ID); err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(10 * time.Minute); !lease.ExpiresAt.Equal(want) {
+		t.Fatalf("expected the lease to expire at %s, got %s", want, lease.ExpiresAt)
+	}
+
+	// a lease that expired cannot be renewed anymore
+	now = now.Add(11 * time.Minute)
+	renewResp, err = server.RenewEphemeralResource(ctx, &tfprotov6.RenewEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renewResp.Diagnostics) == 0 || renewResp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityError {
+		t.Fatal("expected the renewal of the expired lease to fail")
+	}
+
+	closeResp, err := server.CloseEphemeralResource(ctx, &tfprotov6.CloseEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, closeResp.Diagnostics)
+	if _, err := leases.GetByID(lease.ID); !client.IsNotFound(err) {
+		t.Fatalf("expected the lease to be revoked, got %v", err)
+	}
+}
+
+// TestSecretEphemeral_leasedSecret counts the lease of the secret with the clock of the provider, and refuses to delete
+// the secret while it is active.
+func TestSecretEphemeral_leasedSecret(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mgr := &model.SecretManager{Name: "test", DenyRotationWithActiveLeases: true}
+	mgr.PutSecret("db_password", "v1", false, now)
+	mgrs := client.NewClient[*model.SecretManager](backend, typeSecretManager)
+	if mgr, err = mgrs.Create(mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, func() time.Time { return now })
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Config: testDynamicValue(t, schemas.EphemeralResourceSchemas["provs_secret"], map[string]tftypes.Value{
+			"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+			"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+			"lease_ttl":         tftypes.NewValue(tftypes.String, "10m"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, openResp.Diagnostics)
+
+	activeLeases := func() int64 {
+		t.Helper()
+		state := testReadDataSource(ctx, t, server, schemas, "provs_secret_manager", map[string]tftypes.Value{
+			"id": tftypes.NewValue(tftypes.String, mgr.ID),
+		})
+		var secrets []tftypes.Value
+		var secret map[string]tftypes.Value
+		var count big.Float
+		if err := state["secrets"].As(&secrets); err != nil || len(secrets) != 1 {
+			t.Fatalf("expected a secret, got %v (%v)", secrets, err)
+		}
+		if err := secrets[0].As(&secret); err != nil {
+			t.Fatal(err)
+		}
+		if err := secret["active_leases"].As(&count); err != nil {
+			t.Fatal(err)
+		}
+		res, _ := count.Int64()
+		return res
+	}
+	if got := activeLeases(); got != 1 {
+		t.Fatalf("expected 1 active lease, got %d", got)
+	}
+
+	state := map[string]tftypes.Value{
+		"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+		"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+	}
+	resp := testApplyDestroy(ctx, t, server, schemas, "provs_secret", state)
+	if got := testDiagnosticSummaries(resp.Diagnostics); got != "Secret is leased" {
+		t.Fatalf("expected the deletion refused, got %v", resp.Diagnostics)
+	}
+	if mgr, err = mgrs.GetByID(mgr.ID); err != nil || mgr.Secrets["db_password"] == nil {
+		t.Fatalf("expected the secret kept, got %v", err)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if got := activeLeases(); got != 0 {
+		t.Fatalf("expected no active lease, got %d", got)
+	}
+	testNoDiagnostics(t, testApplyDestroy(ctx, t, server, schemas, "provs_secret", state).Diagnostics)
+	if mgr, err = mgrs.GetByID(mgr.ID); err != nil || mgr.Secrets["db_password"] != nil {
+		t.Fatalf("expected the secret deleted, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/generator"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource          = &tokenEphemeral{}
+	_ ephemeral.EphemeralResourceWithRenew = &tokenEphemeral{}
+	_ ephemeral.EphemeralResourceWithClose = &tokenEphemeral{}
+)
+
+const (
+	// defaultTokenExpiresIn is the lifetime of the tokens when expires_in is not configured
+	defaultTokenExpiresIn = time.Hour
+	defaultTokenAlgorithm = generator.TokenAlgorithmHS256
+)
+
+// tokenRegisteredClaims are set from their own attributes, so they cannot be part of the claims
+var tokenRegisteredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// tokenModel maps the ephemeral resource schema data.
+type tokenModel struct {
+	ID             types.String            `tfsdk:"id"`
+	TTL            types.String            `tfsdk:"ttl"`
+	ExpiresAt      types.String            `tfsdk:"expires_at"`
+	Algorithm      types.String            `tfsdk:"algorithm"`
+	SigningKey     types.String            `tfsdk:"signing_key"`
+	Issuer         types.String            `tfsdk:"issuer"`
+	Subject        types.String            `tfsdk:"subject"`
+	Audience       []types.String          `tfsdk:"audience"`
+	Claims         map[string]types.String `tfsdk:"claims"`
+	ExpiresIn      types.String            `tfsdk:"expires_in"`
+	Token          types.String            `tfsdk:"token"`
+	IssuedAt       types.String            `tfsdk:"issued_at"`
+	TokenExpiresAt types.String            `tfsdk:"token_expires_at"`
+}
+
+func NewEphemeralToken() ephemeral.EphemeralResource {
+	return newEphemeralToken(time.Now)
+}
+
+// newEphemeralToken returns the ephemeral resource issuing the tokens with the given clock.
+func newEphemeralToken(now func() time.Time) ephemeral.EphemeralResource {
+	return &tokenEphemeral{
+		now: now,
+	}
+}
+
+type tokenEphemeral struct {
+	// now is the clock used to issue the tokens and compute their validity. Replaced in tests.
+	now func() time.Time
+}
+
+func (r *tokenEphemeral) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeToken
+}
+
+// Schema defines the schema for the resource.
+func (r *tokenEphemeral) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Issues a JWT signed with HMAC. The id is the jti claim of the token.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"ttl":        generatedTTLSchema(),
+			"expires_at": generatedExpiresAtSchema(),
+			"algorithm": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("One of: %s. Defaults to %s.", strings.Join(generator.TokenAlgorithms(), ", "), defaultTokenAlgorithm),
+				Validators: []validator.String{
+					stringvalidator.OneOf(generator.TokenAlgorithms()...),
+				},
+			},
+			"signing_key": schema.StringAttribute{
+				Required:    true,
+				Sensitive:   true,
+				Description: fmt.Sprintf("The key signing the token, at least %d bytes long.", generator.MinTokenKeyLength),
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(generator.MinTokenKeyLength),
+				},
+			},
+			"issuer": schema.StringAttribute{
+				Optional:    true,
+				Description: "The iss claim.",
+			},
+			"subject": schema.StringAttribute{
+				Optional:    true,
+				Description: "The sub claim.",
+			},
+			"audience": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "The aud claim.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+			},
+			"claims": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "The private claims of the token. The registered claims (" + strings.Join(tokenRegisteredClaims, ", ") +
+					") are set from the other attributes.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.NoneOf(tokenRegisteredClaims...)),
+				},
+			},
+			"expires_in": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("The lifetime of the token, setting its exp claim. Defaults to %s.", defaultTokenExpiresIn),
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"token": schema.StringAttribute{
+				Computed:  true,
+				Sensitive: true,
+			},
+			"issued_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The iat claim, in RFC 3339 format.",
+			},
+			"token_expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "The exp claim, in RFC 3339 format. Renewing the ephemeral resource does not extend it.",
+			},
+		},
+	}
+}
+
+func (r *tokenEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var cfg tokenModel
+	diags := req.Config.Get(ctx, &cfg)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	now := r.now()
+	v := newGeneratedValue(cfg.TTL, now)
+	expiresIn := defaultTokenExpiresIn
+	if !cfg.ExpiresIn.IsNull() {
+		// already validated by the schema
+		expiresIn, _ = time.ParseDuration(cfg.ExpiresIn.ValueString())
+	}
+	issuedAt := now.Truncate(time.Second)
+	expiresAt := issuedAt.Add(expiresIn)
+	alg := defaultTokenAlgorithm
+	if !cfg.Algorithm.IsNull() {
+		alg = generator.TokenAlgorithm(cfg.Algorithm.ValueString())
+	}
+
+	token, err := generator.SignToken(alg, []byte(cfg.SigningKey.ValueString()), cfg.claims(v.ID, issuedAt, expiresAt))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to issue the token",
+			err.Error(),
+		)
+		return
+	}
+
+	cfg.ID = types.StringValue(v.ID)
+	cfg.ExpiresAt = v.expiresAt()
+	cfg.Token = types.StringValue(token)
+	cfg.IssuedAt = types.StringValue(issuedAt.UTC().Format(time.RFC3339))
+	cfg.TokenExpiresAt = types.StringValue(expiresAt.UTC().Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	v.open(ctx, resp)
+}
+
+// Renew extends the validity of the token by its TTL.
+func (r *tokenEphemeral) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	renewGenerated(ctx, "token", r.now(), req, resp)
+}
+
+// Close releases the token.
+func (r *tokenEphemeral) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	closeGenerated(ctx, "token", req, resp)
+}
+
+// claims returns the claims of the token identified by the id
+func (m *tokenModel) claims(id string, issuedAt time.Time, expiresAt time.Time) map[string]any {
+	res := map[string]any{}
+	for k, v := range m.Claims {
+		res[k] = v.ValueString()
+	}
+	res["jti"] = id
+	res["iat"] = issuedAt.Unix()
+	res["exp"] = expiresAt.Unix()
+	if !m.Issuer.IsNull() {
+		res["iss"] = m.Issuer.ValueString()
+	}
+	if !m.Subject.IsNull() {
+		res["sub"] = m.Subject.ValueString()
+	}
+	if len(m.Audience) == 1 {
+		res["aud"] = m.Audience[0].ValueString()
+	} else if len(m.Audience) > 1 {
+		res["aud"] = stringValues(m.Audience)
+	}
+	return res
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccTokenEphemeral_registeredClaim(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+ephemeral "provs_token" "test" {
+  signing_key = "0123456789abcdef0123456789abcdef"
+  claims = {
+    exp = "never"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestTokenEphemeral(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	server, schemas := testProtocolServer(ctx, t, t.TempDir(), func() time.Time { return now })
+	attrs, _ := testOpenEphemeral(ctx, t, server, schemas, "provs_token", map[string]tftypes.Value{
+		"algorithm":   tftypes.NewValue(tftypes.String, "HS512"),
+		"signing_key": tftypes.NewValue(tftypes.String, key),
+		"issuer":      tftypes.NewValue(tftypes.String, "provs"),
+		"audience":    tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "api")}),
+		"claims": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"role": tftypes.NewValue(tftypes.String, "admin"),
+		}),
+		"expires_in": tftypes.NewValue(tftypes.String, "15m"),
+	})
+
+	parts := strings.Split(testStringAttr(t, attrs, "token"), ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a JWT of 3 parts, got %d", len(parts))
+	}
+	mac := hmac.New(sha512.New, []byte(key))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if got := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); got != parts[2] {
+		t.Fatal("the signature of the token does not match")
+	}
+
+	var header map[string]any
+	testDecodeTokenPart(t, parts[0], &header)
+	if header["alg"] != "HS512" || header["typ"] != "JWT" {
+		t.Fatalf("unexpected header %v", header)
+	}
+	var claims map[string]any
+	testDecodeTokenPart(t, parts[1], &claims)
+	expected := map[string]any{
+		"iss":  "provs",
+		"aud":  "api",
+		"role": "admin",
+		"jti":  testStringAttr(t, attrs, "id"),
+		"iat":  float64(now.Unix()),
+		"exp":  float64(now.Add(15 * time.Minute).Unix()),
+	}
+	for k, v := range expected {
+		if claims[k] != v {
+			t.Fatalf("expected the claim %s to be %v, got %v", k, v, claims[k])
+		}
+	}
+	if _, ok := claims["sub"]; ok {
+		t.Fatal("expected no sub claim")
+	}
+	if got := testStringAttr(t, attrs, "token_expires_at"); got != "2025-01-01T00:15:00Z" {
+		t.Fatalf("unexpected token_expires_at %s", got)
+	}
+}
+
+func testDecodeTokenPart(t *testing.T, part string, v any) {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ApplyDiscountFunction{}
+
+type ApplyDiscountFunction struct{}
+
+func NewFunctionApplyDiscount() function.Function {
+	return &ApplyDiscountFunction{}
+}
+
+func (f *ApplyDiscountFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "apply_discount"
+}
+
+func (f *ApplyDiscountFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Apply a discount to an amount",
+		Description: "Given an amount and a discount, return the amount once discounted, rounded to the cent. " +
+			"The discount is computed like the promotions of the orders.",
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "amount",
+				Description: "Amount discounted.",
+			},
+			function.StringParameter{
+				Name:        "kind",
+				Description: fmt.Sprintf("Kind of the discount, one of: %s.", strings.Join(model.DiscountKinds(), ", ")),
+			},
+			function.Float64Parameter{
+				Name:        "value",
+				Description: "Percentage, at most 100, or amount of the discount.",
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *ApplyDiscountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var amount float64
+	var kind string
+	var value float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &amount, &kind, &value))
+	if resp.Error != nil {
+		return
+	}
+	if resp.Error = validatePrice(0, "amount", amount); resp.Error != nil {
+		return
+	}
+	if !slices.Contains(model.DiscountKinds(), kind) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("kind must be one of: %s", strings.Join(model.DiscountKinds(), ", ")))
+		return
+	}
+	if value < 0 || (model.DiscountKind(kind) == model.DiscountKindPercentage && value > 100) {
+		resp.Error = function.NewArgumentFuncError(2, "value must be between 0 and 100 for a percentage, and not negative")
+		return
+	}
+
+	total := model.RoundCents(amount - model.DiscountKind(kind).Discount(value, amount))
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestApplyDiscountFunction(t *testing.T) {
+	testFunction(t, NewFunctionApplyDiscount(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(8), types.StringValue("percentage"), types.Float64Value(10)}, want: types.Float64Value(7.2)},
+		{args: []attr.Value{types.Float64Value(3.33), types.StringValue("percentage"), types.Float64Value(15)}, want: types.Float64Value(2.83)},
+		{args: []attr.Value{types.Float64Value(8), types.StringValue("fixed"), types.Float64Value(2.5)}, want: types.Float64Value(5.5)},
+		{args: []attr.Value{types.Float64Value(8), types.StringValue("fixed"), types.Float64Value(10)}, want: types.Float64Value(0)},
+		{args: []attr.Value{types.Float64Value(8), types.StringValue("percentage"), types.Float64Value(120)}, err: "value must be between 0 and 100"},
+		{args: []attr.Value{types.Float64Value(8), types.StringValue("bogo"), types.Float64Value(1)}, err: "kind must be one of"},
+		{args: []attr.Value{types.Float64Value(-1), types.StringValue("fixed"), types.Float64Value(1)}, err: "amount must not be negative"},
+	})
+}
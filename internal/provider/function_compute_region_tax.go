@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ComputeRegionTaxFunction{}
+
+// computeTaxRuleModel maps a tax rule of the compute_region_tax function
+type computeTaxRuleModel struct {
+	Rate             float64  `tfsdk:"rate"`
+	Inclusive        bool     `tfsdk:"inclusive"`
+	ExemptCategories []string `tfsdk:"exempt_categories"`
+}
+
+type ComputeRegionTaxFunction struct{}
+
+func NewFunctionComputeRegionTax() function.Function {
+	return &ComputeRegionTaxFunction{}
+}
+
+func (f *ComputeRegionTaxFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "compute_region_tax"
+}
+
+func (f *ComputeRegionTaxFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Compute tax for coffee in a region",
+		Description: "Given a price, the category of the coffee, a region and the tax rules by region, like the rules of " +
+			"the provs_tax_rules data source, return the total cost including the tax of the region, as the tax of the " +
+			"orders: the price itself when the rule of the region is inclusive or exempts the category.",
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "price",
+				Description: "Price of coffee item. It must not be negative.",
+			},
+			function.StringParameter{
+				Name:           "category",
+				Description:    "Category of the coffee item, null when it has none.",
+				AllowNullValue: true,
+			},
+			function.StringParameter{
+				Name:        "region",
+				Description: "Region of a tax rule.",
+			},
+			function.MapParameter{
+				Name:        "rules",
+				Description: "Tax rules by region, objects with a rate, inclusive and the exempt_categories.",
+				ElementType: types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"rate":              types.Float64Type,
+						"inclusive":         types.BoolType,
+						"exempt_categories": types.ListType{ElemType: types.StringType},
+					},
+				},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *ComputeRegionTaxFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var price float64
+	var category types.String
+	var region string
+	var rules map[string]computeTaxRuleModel
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &category, &region, &rules))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = validatePrice(0, "price", price)
+	rule, ok := rules[region]
+	if !ok {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(3,
+			fmt.Sprintf("there is no tax rule for the region %q", region)))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, validateTaxRate(3, rule.Rate))
+	if resp.Error != nil {
+		return
+	}
+
+	// the tax of the orders is computed by the same rule
+	taxRule := model.TaxRule{Rate: rule.Rate, Inclusive: rule.Inclusive, ExemptCategories: rule.ExemptCategories}
+	total := price
+	if !taxRule.Inclusive && !taxRule.Exempts(&model.Coffee{Category: category.ValueString()}) {
+		total = model.RoundCents(price + taxRule.Tax(price))
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
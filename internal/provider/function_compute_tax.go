@@ -2,7 +2,7 @@ package provider
 
 import (
 	"context"
-	"math"
+	"terraform-provider-provs/internal/model"
 
 	"github.com/hashicorp/terraform-plugin-framework/function"
 )
@@ -22,16 +22,17 @@ func (f *ComputeTaxFunction) Metadata(ctx context.Context, req function.Metadata
 
 func (f *ComputeTaxFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
 	resp.Definition = function.Definition{
-		Summary:     "Compute tax for coffee",
-		Description: "Given a price and tax rate, return the total cost including tax.",
+		Summary: "Compute tax for coffee",
+		Description: "Given a price and tax rate, return the total cost including tax. compute_region_tax takes the " +
+			"region of a tax rule instead of the rate.",
 		Parameters: []function.Parameter{
 			function.Float64Parameter{
 				Name:        "price",
-				Description: "Price of coffee item.",
+				Description: "Price of coffee item. It must not be negative.",
 			},
 			function.Float64Parameter{
 				Name:        "rate",
-				Description: "Tax rate. 0.085 == 8.5%",
+				Description: "Tax rate, between 0 and 1. 0.085 == 8.5%",
 			},
 		},
 		Return: function.Float64Return{},
@@ -41,12 +42,19 @@ func (f *ComputeTaxFunction) Definition(ctx context.Context, req function.Defini
 func (f *ComputeTaxFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
 	var price float64
 	var rate float64
-	var total float64
 
 	// Read Terraform argument data into the variables
 	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &price, &rate))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(validatePrice(0, "price", price), validateTaxRate(1, rate))
+	if resp.Error != nil {
+		return
+	}
 
-	total = math.Round((price+price*rate)*100) / 100
+	rule := model.TaxRule{Rate: rate}
+	total := model.RoundCents(price + rule.Tax(price))
 
 	// Set the result
 	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ConvertCurrencyFunction{}
+
+type ConvertCurrencyFunction struct{}
+
+func NewFunctionConvertCurrency() function.Function {
+	return &ConvertCurrencyFunction{}
+}
+
+func (f *ConvertCurrencyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "convert_currency"
+}
+
+func (f *ConvertCurrencyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Convert an amount to another currency",
+		Description: "Given an amount, its currency, the target currency and exchange rates, return the amount in the " +
+			"target currency, rounded to its minor unit.",
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "amount",
+				Description: "Amount converted. It must not be negative.",
+			},
+			function.StringParameter{
+				Name:        "from",
+				Description: "ISO 4217 code of the currency of the amount, like \"EUR\".",
+			},
+			function.StringParameter{
+				Name:        "to",
+				Description: "ISO 4217 code of the target currency.",
+			},
+			function.MapParameter{
+				Name: "rates",
+				Description: "Exchange rates by currency code, in units of the currency for one unit of a common base " +
+					"currency, like { USD = 1, EUR = 0.92 }. Both currencies must have a positive rate.",
+				ElementType: types.Float64Type,
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *ConvertCurrencyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var amount float64
+	var from, to string
+	var rates map[string]float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &amount, &from, &to, &rates))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error,
+		validatePrice(0, "amount", amount), validateCurrency(1, "from", from), validateCurrency(2, "to", to))
+	for _, currency := range []string{from, to} {
+		if rate, ok := rates[currency]; !ok || rate <= 0 {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(3,
+				fmt.Sprintf("rates must have a positive rate for %q", currency)))
+		}
+	}
+	if resp.Error != nil {
+		return
+	}
+
+	converted := model.RoundMoney(amount/rates[from]*rates[to], to)
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, converted))
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"golang.org/x/crypto/argon2"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &HashSecretFunction{}
+
+// hashSecretAlgorithms are the algorithms of the hash_secret function. The function being pure, the salt is an
+// argument: algorithms salting with a random value, like bcrypt, are not supported.
+var hashSecretAlgorithms = []string{"argon2", "sha256"}
+
+// The argon2id parameters recommended by RFC 9106 for memory constrained environments. argon2MinSaltLen is the
+// minimum length of the salt of the Argon2 specification.
+const (
+	argon2Time       = 3
+	argon2Memory     = 64 * 1024
+	argon2Threads    = 4
+	argon2KeyLen     = 32
+	argon2MinSaltLen = 8
+)
+
+type HashSecretFunction struct{}
+
+func NewFunctionHashSecret() function.Function {
+	return &HashSecretFunction{}
+}
+
+func (f *HashSecretFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "hash_secret"
+}
+
+func (f *HashSecretFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Hash a secret",
+		Description: "Given a secret value, an algorithm and a salt, return a hash of the value, the same for the " +
+			"same arguments. sha256 returns the hex digest of the value, or its HMAC-SHA256 keyed with the salt when " +
+			"the salt is not empty: a fingerprint that can be kept in state to detect changes of a write-only secret. " +
+			"An unsalted digest lets anyone reading the state check guesses of the value, it only suits values with " +
+			"enough entropy, like generated keys: hash the passwords with a secret salt. argon2 (argon2id) returns " +
+			"the hash in its standard encoding, to be verified by the services storing password hashes, with a salt " +
+			"of at least 8 bytes that should be random and kept, for example from a random_bytes resource.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "value",
+				Description: "Secret value.",
+			},
+			function.StringParameter{
+				Name:        "algo",
+				Description: fmt.Sprintf("Hash algorithm, one of: %s.", strings.Join(hashSecretAlgorithms, ", ")),
+			},
+			function.StringParameter{
+				Name: "salt",
+				Description: fmt.Sprintf("Salt of the hash, the key of the HMAC for sha256, empty for a plain digest. "+
+					"At least %d bytes for argon2.", argon2MinSaltLen),
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *HashSecretFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value string
+	var algo string
+	var salt string
+	var hash string
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &value, &algo, &salt))
+	if resp.Error != nil {
+		return
+	}
+
+	// the errors never include the value
+	switch algo {
+	case "sha256":
+		if salt == "" {
+			sum := sha256.Sum256([]byte(value))
+			hash = hex.EncodeToString(sum[:])
+			break
+		}
+		mac := hmac.New(sha256.New, []byte(salt))
+		mac.Write([]byte(value))
+		hash = hex.EncodeToString(mac.Sum(nil))
+	case "argon2":
+		if len(salt) < argon2MinSaltLen {
+			resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("argon2 needs a salt of at least %d bytes, got %d", argon2MinSaltLen, len(salt)))
+			return
+		}
+		key := argon2.IDKey([]byte(value), []byte(salt), argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		hash = fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString([]byte(salt)), base64.RawStdEncoding.EncodeToString(key))
+	default:
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("algo must be one of: %s, got %q", strings.Join(hashSecretAlgorithms, ", "), algo))
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, hash))
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &OrderTotalFunction{}
+
+// orderTotalItemModel maps an item of the order_total function
+type orderTotalItemModel struct {
+	CoffeeID string `tfsdk:"coffee_id"`
+	Quantity int64  `tfsdk:"quantity"`
+}
+
+type OrderTotalFunction struct{}
+
+func NewFunctionOrderTotal() function.Function {
+	return &OrderTotalFunction{}
+}
+
+func (f *OrderTotalFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "order_total"
+}
+
+func (f *OrderTotalFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Compute the total of an order",
+		Description: "Given the items of an order, the prices of the coffees and a tax rate, return the total cost of " +
+			"the order including tax, rounded to the cent.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "items",
+				Description: "Items of the order, objects with a coffee_id and a quantity of at least 1.",
+				ElementType: types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"coffee_id": types.StringType,
+						"quantity":  types.Int64Type,
+					},
+				},
+			},
+			function.MapParameter{
+				Name:        "catalog",
+				Description: "Prices of the coffees by coffee ID, like { for c in data.provs_coffees.all.coffees : c.id => c.price }.",
+				ElementType: types.Float64Type,
+			},
+			function.Float64Parameter{
+				Name:        "rate",
+				Description: "Tax rate, between 0 and 1. 0.085 == 8.5%",
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *OrderTotalFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var items []orderTotalItemModel
+	var catalog map[string]float64
+	var rate float64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &items, &catalog, &rate))
+	if resp.Error != nil {
+		return
+	}
+	for _, id := range slices.Sorted(maps.Keys(catalog)) {
+		resp.Error = function.ConcatFuncErrors(resp.Error, validatePrice(1, fmt.Sprintf("the price of %q", id), catalog[id]))
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, validateTaxRate(2, rate))
+
+	subtotal := 0.0
+	for i, item := range items {
+		price, ok := catalog[item.CoffeeID]
+		if !ok {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0,
+				fmt.Sprintf("items[%d]: there is no coffee %q in the catalog", i, item.CoffeeID)))
+		}
+		if item.Quantity < 1 {
+			resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0,
+				fmt.Sprintf("items[%d]: quantity must be at least 1, got %d", i, item.Quantity)))
+		}
+		subtotal += price * float64(item.Quantity)
+	}
+	if resp.Error != nil {
+		return
+	}
+
+	total := model.RoundCents(subtotal + subtotal*rate)
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, total))
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &ParseSecretRefFunction{}
+
+// parseSecretRefModel maps the result of the parse_secret_ref function
+type parseSecretRefModel struct {
+	ManagerID string `tfsdk:"manager_id"`
+	Name      string `tfsdk:"name"`
+}
+
+type ParseSecretRefFunction struct{}
+
+func NewFunctionParseSecretRef() function.Function {
+	return &ParseSecretRefFunction{}
+}
+
+func (f *ParseSecretRefFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_secret_ref"
+}
+
+func (f *ParseSecretRefFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse a secret reference",
+		Description: "Given a secret reference returned by secret_ref, return an object with its manager_id and name.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "ref",
+				Description: "Secret reference, provs://<secret_manager_id>/<secret_name>.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"manager_id": types.StringType,
+				"name":       types.StringType,
+			},
+		},
+	}
+}
+
+func (f *ParseSecretRefFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ref string
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &ref))
+	if resp.Error != nil {
+		return
+	}
+	parsed, err := model.ParseSecretRef(ref)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, parseSecretRefModel{
+		ManagerID: parsed.SecretManagerID,
+		Name:      parsed.SecretName,
+	}))
+}
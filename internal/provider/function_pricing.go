@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// currencyCodeRegex matches the ISO 4217 currency codes
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validatePrice returns an error for the argument at the given position when the price is negative or larger than
+// the amounts handled
+func validatePrice(argument int64, name string, price float64) *function.FuncError {
+	if price < 0 {
+		return function.NewArgumentFuncError(argument, fmt.Sprintf("%s must not be negative, got %v", name, price))
+	}
+	if price > model.MaxMoneyAmount {
+		return function.NewArgumentFuncError(argument, fmt.Sprintf("%s must be at most %v, got %v", name, model.MaxMoneyAmount, price))
+	}
+	return nil
+}
+
+// validateTaxRate returns an error for the argument at the given position unless the rate is between 0 and 1
+func validateTaxRate(argument int64, rate float64) *function.FuncError {
+	if rate < 0 || rate > 1 {
+		return function.NewArgumentFuncError(argument, fmt.Sprintf("rate must be between 0 and 1, like 0.085 for 8.5%%, got %v", rate))
+	}
+	return nil
+}
+
+// validateCurrency returns an error for the argument at the given position unless the code is an ISO 4217 code
+func validateCurrency(argument int64, name string, currency string) *function.FuncError {
+	if !currencyCodeRegex.MatchString(currency) {
+		return function.NewArgumentFuncError(argument, fmt.Sprintf("%s must be an ISO 4217 currency code, like \"EUR\", got %q", name, currency))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestComputeTaxFunction(t *testing.T) {
+	testFunction(t, NewFunctionComputeTax(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(5), types.Float64Value(0.085)}, want: types.Float64Value(5.43)},
+		{args: []attr.Value{types.Float64Value(1.05), types.Float64Value(0.1)}, want: types.Float64Value(1.16)},
+		{args: []attr.Value{types.Float64Value(-5), types.Float64Value(0.085)}, err: "price must not be negative"},
+		{args: []attr.Value{types.Float64Value(5), types.Float64Value(8.5)}, err: "rate must be between 0 and 1"},
+	})
+}
+
+func TestComputeRegionTaxFunction(t *testing.T) {
+	ruleType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"rate":              types.Float64Type,
+		"inclusive":         types.BoolType,
+		"exempt_categories": types.ListType{ElemType: types.StringType},
+	}}
+	rule := func(rate float64, inclusive bool, exempt ...string) attr.Value {
+		categories := types.ListNull(types.StringType)
+		if len(exempt) > 0 {
+			values := []attr.Value{}
+			for _, c := range exempt {
+				values = append(values, types.StringValue(c))
+			}
+			categories = types.ListValueMust(types.StringType, values)
+		}
+		return types.ObjectValueMust(ruleType.AttrTypes, map[string]attr.Value{
+			"rate": types.Float64Value(rate), "inclusive": types.BoolValue(inclusive), "exempt_categories": categories,
+		})
+	}
+	table := types.MapValueMust(ruleType, map[string]attr.Value{
+		"FR":      rule(0.2, true),
+		"US-CA":   rule(0.0725, false, "tea"),
+		"INVALID": rule(7.25, false),
+	})
+	noCategory := types.StringNull()
+
+	testFunction(t, NewFunctionComputeRegionTax(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(5), noCategory, types.StringValue("US-CA"), table}, want: types.Float64Value(5.36)},
+		{args: []attr.Value{types.Float64Value(5), types.StringValue("espresso"), types.StringValue("US-CA"), table}, want: types.Float64Value(5.36)},
+		// exempt, like the items of the orders of the region
+		{args: []attr.Value{types.Float64Value(5), types.StringValue("tea"), types.StringValue("US-CA"), table}, want: types.Float64Value(5)},
+		{args: []attr.Value{types.Float64Value(5), noCategory, types.StringValue("FR"), table}, want: types.Float64Value(5)},
+		{args: []attr.Value{types.Float64Value(-5), noCategory, types.StringValue("FR"), table}, err: "price must not be negative"},
+		{args: []attr.Value{types.Float64Value(5), noCategory, types.StringValue("DE"), table}, err: `there is no tax rule for the region "DE"`},
+		{args: []attr.Value{types.Float64Value(5), noCategory, types.StringValue("INVALID"), table}, err: "rate must be between 0 and 1"},
+	})
+}
+
+func TestOrderTotalFunction(t *testing.T) {
+	item := func(id string, quantity int64) attr.Value {
+		return types.ObjectValueMust(
+			map[string]attr.Type{"coffee_id": types.StringType, "quantity": types.Int64Type},
+			map[string]attr.Value{"coffee_id": types.StringValue(id), "quantity": types.Int64Value(quantity)},
+		)
+	}
+	items := func(values ...attr.Value) attr.Value {
+		return types.ListValueMust(types.ObjectType{
+			AttrTypes: map[string]attr.Type{"coffee_id": types.StringType, "quantity": types.Int64Type},
+		}, values)
+	}
+	catalog := func(prices map[string]float64) attr.Value {
+		values := map[string]attr.Value{}
+		for id, price := range prices {
+			values[id] = types.Float64Value(price)
+		}
+		return types.MapValueMust(types.Float64Type, values)
+	}
+
+	testFunction(t, NewFunctionOrderTotal(), []testFunctionCase{
+		{
+			args: []attr.Value{items(item("1", 2), item("2", 1)), catalog(map[string]float64{"1": 4, "2": 2.5}), types.Float64Value(0.1)},
+			want: types.Float64Value(11.55),
+		},
+		{
+			args: []attr.Value{items(), catalog(nil), types.Float64Value(0)},
+			want: types.Float64Value(0),
+		},
+		{
+			args: []attr.Value{items(item("3", 1)), catalog(map[string]float64{"1": 4}), types.Float64Value(0.1)},
+			err:  `items[0]: there is no coffee "3" in the catalog`,
+		},
+		{
+			args: []attr.Value{items(item("1", 0)), catalog(map[string]float64{"1": 4}), types.Float64Value(0.1)},
+			err:  "items[0]: quantity must be at least 1",
+		},
+		{
+			args: []attr.Value{items(item("1", 1)), catalog(map[string]float64{"1": -4}), types.Float64Value(0.1)},
+			err:  `the price of "1" must not be negative`,
+		},
+		{
+			args: []attr.Value{items(item("1", 1)), catalog(map[string]float64{"1": 4}), types.Float64Value(1.1)},
+			err:  "rate must be between 0 and 1",
+		},
+	})
+}
+
+func TestConvertCurrencyFunction(t *testing.T) {
+	rates := types.MapValueMust(types.Float64Type, map[string]attr.Value{
+		"USD": types.Float64Value(1),
+		"EUR": types.Float64Value(0.92),
+		"JPY": types.Float64Value(151.2),
+		"GBP": types.Float64Value(0),
+	})
+	testFunction(t, NewFunctionConvertCurrency(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("USD"), types.StringValue("EUR"), rates}, want: types.Float64Value(9.2)},
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("EUR"), types.StringValue("JPY"), rates}, want: types.Float64Value(1643)},
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("EUR"), types.StringValue("EUR"), rates}, want: types.Float64Value(10)},
+		{args: []attr.Value{types.Float64Value(-1), types.StringValue("USD"), types.StringValue("EUR"), rates}, err: "amount must not be negative"},
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("usd"), types.StringValue("EUR"), rates}, err: "from must be an ISO 4217 currency code"},
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("USD"), types.StringValue("CHF"), rates}, err: `positive rate for "CHF"`},
+		{args: []attr.Value{types.Float64Value(10), types.StringValue("GBP"), types.StringValue("USD"), rates}, err: `positive rate for "GBP"`},
+	})
+}
+
+func TestRoundMoneyFunction(t *testing.T) {
+	testFunction(t, NewFunctionRoundMoney(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(1.005), types.StringValue("EUR")}, want: types.Float64Value(1.01)},
+		{args: []attr.Value{types.Float64Value(2.675), types.StringValue("USD")}, want: types.Float64Value(2.68)},
+		{args: []attr.Value{types.Float64Value(1234.5), types.StringValue("JPY")}, want: types.Float64Value(1235)},
+		{args: []attr.Value{types.Float64Value(1.2345), types.StringValue("KWD")}, want: types.Float64Value(1.235)},
+		{args: []attr.Value{types.Float64Value(1), types.StringValue("euro")}, err: "currency must be an ISO 4217 currency code"},
+		{args: []attr.Value{types.Float64Value(1e300), types.StringValue("EUR")}, err: "amount must be at most"},
+	})
+}
+
+func TestSplitBillFunction(t *testing.T) {
+	testFunction(t, NewFunctionSplitBill(), []testFunctionCase{
+		{args: []attr.Value{types.Float64Value(10), types.Int64Value(3)}, want: testFloat64List(3.34, 3.33, 3.33)},
+		{args: []attr.Value{types.Float64Value(9), types.Int64Value(3)}, want: testFloat64List(3, 3, 3)},
+		{args: []attr.Value{types.Float64Value(0.01), types.Int64Value(2)}, want: testFloat64List(0.01, 0)},
+		{args: []attr.Value{types.Float64Value(10), types.Int64Value(0)}, err: "n must be between 1 and 1000"},
+		{args: []attr.Value{types.Float64Value(10), types.Int64Value(1 << 40)}, err: "n must be between 1 and 1000"},
+		{args: []attr.Value{types.Float64Value(1e19), types.Int64Value(2)}, err: "total must be at most"},
+		{args: []attr.Value{types.Float64Value(-10), types.Int64Value(2)}, err: "total must not be negative"},
+	})
+}
+
+// testFunctionCase is a call of a function, returning the value want or failing with the error err
+type testFunctionCase struct {
+	args []attr.Value
+	want attr.Value
+	err  string
+}
+
+// testFunction runs the function for each case
+func testFunction(t *testing.T, f function.Function, cases []testFunctionCase) {
+	t.Helper()
+	ctx := context.Background()
+	var def function.DefinitionResponse
+	f.Definition(ctx, function.DefinitionRequest{}, &def)
+	for _, tc := range cases {
+		result, funcErr := def.Definition.Return.NewResultData(ctx)
+		if funcErr != nil {
+			t.Fatal(funcErr)
+		}
+		resp := function.RunResponse{Result: result}
+		f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(tc.args)}, &resp)
+		if tc.err != "" {
+			if resp.Error == nil || !strings.Contains(resp.Error.Error(), tc.err) {
+				t.Errorf("%v: expected the error %q, got %v", tc.args, tc.err, resp.Error)
+			}
+			continue
+		}
+		if resp.Error != nil {
+			t.Errorf("%v: %s", tc.args, resp.Error)
+			continue
+		}
+		if got := resp.Result.Value(); !got.Equal(tc.want) {
+			t.Errorf("%v: expected %s, got %s", tc.args, tc.want, got)
+		}
+	}
+}
+
+func testFloat64List(values ...float64) attr.Value {
+	var elements []attr.Value
+	for _, v := range values {
+		elements = append(elements, types.Float64Value(v))
+	}
+	return types.ListValueMust(types.Float64Type, elements)
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &RoundMoneyFunction{}
+
+type RoundMoneyFunction struct{}
+
+func NewFunctionRoundMoney() function.Function {
+	return &RoundMoneyFunction{}
+}
+
+func (f *RoundMoneyFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "round_money"
+}
+
+func (f *RoundMoneyFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Round an amount of money",
+		Description: "Given an amount and its currency, return the amount rounded half up to the minor unit of the currency: " +
+			"the cent for most currencies, the unit for JPY, the thousandth for KWD. The amount is rounded as written, " +
+			"1.005 being rounded to 1.01.",
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "amount",
+				Description: "Amount rounded. It must not be negative.",
+			},
+			function.StringParameter{
+				Name:        "currency",
+				Description: "ISO 4217 code of the currency, like \"EUR\".",
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+func (f *RoundMoneyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var amount float64
+	var currency string
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &amount, &currency))
+	resp.Error = function.ConcatFuncErrors(resp.Error, validatePrice(0, "amount", amount), validateCurrency(1, "currency", currency))
+	if resp.Error != nil {
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, model.RoundMoney(amount, currency)))
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &SecretRefFunction{}
+
+type SecretRefFunction struct{}
+
+func NewFunctionSecretRef() function.Function {
+	return &SecretRefFunction{}
+}
+
+func (f *SecretRefFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "secret_ref"
+}
+
+func (f *SecretRefFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Reference a secret",
+		Description: "Given the ID of a secret manager and the name of a secret, return the canonical reference of the " +
+			"secret, provs://<secret_manager_id>/<secret_name>. The reference holds no secret value, it can be stored " +
+			"in state, passed around and imported as a provs_secret.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "manager_id",
+				Description: "ID of the secret manager.",
+			},
+			function.StringParameter{
+				Name:        "name",
+				Description: "Name of the secret.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *SecretRefFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ref model.SecretRef
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &ref.SecretManagerID, &ref.SecretName))
+	if resp.Error != nil {
+		return
+	}
+	if ref.SecretManagerID == "" {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, "manager_id must not be empty"))
+	}
+	if ref.SecretName == "" {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(1, "name must not be empty"))
+	}
+	if resp.Error != nil {
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, ref.String()))
+}
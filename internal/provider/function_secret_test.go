@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/argon2"
+)
+
+func TestSecretRefFunction(t *testing.T) {
+	testFunction(t, NewFunctionSecretRef(), []testFunctionCase{
+		{args: []attr.Value{types.StringValue("mgr"), types.StringValue("db_password")}, want: types.StringValue("provs://mgr/db_password")},
+		{args: []attr.Value{types.StringValue("mgr"), types.StringValue("app/db password")}, want: types.StringValue("provs://mgr/app%2Fdb%20password")},
+		{args: []attr.Value{types.StringValue(""), types.StringValue("db_password")}, err: "manager_id must not be empty"},
+		{args: []attr.Value{types.StringValue("mgr"), types.StringValue("")}, err: "name must not be empty"},
+	})
+}
+
+func TestParseSecretRefFunction(t *testing.T) {
+	ref := func(mgrID string, name string) attr.Value {
+		return types.ObjectValueMust(
+			map[string]attr.Type{"manager_id": types.StringType, "name": types.StringType},
+			map[string]attr.Value{"manager_id": types.StringValue(mgrID), "name": types.StringValue(name)},
+		)
+	}
+	testFunction(t, NewFunctionParseSecretRef(), []testFunctionCase{
+		{args: []attr.Value{types.StringValue("provs://mgr/db_password")}, want: ref("mgr", "db_password")},
+		{args: []attr.Value{types.StringValue("provs://mgr/app%2Fdb%20password")}, want: ref("mgr", "app/db password")},
+		{args: []attr.Value{types.StringValue("mgr/db_password")}, err: "must start with provs://"},
+		{args: []attr.Value{types.StringValue("provs://mgr")}, err: "must have the format"},
+		{args: []attr.Value{types.StringValue("provs://mgr/app/db")}, err: "must have the format"},
+		{args: []attr.Value{types.StringValue("provs://mgr/%zz")}, err: "invalid secret name"},
+	})
+}
+
+func TestHashSecretFunction(t *testing.T) {
+	const salt = "0123456789abcdef"
+	argon2Key := argon2.IDKey([]byte("s3cr3t"), []byte(salt), argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte("s3cr3t"))
+	testFunction(t, NewFunctionHashSecret(), []testFunctionCase{
+		{
+			args: []attr.Value{types.StringValue("s3cr3t"), types.StringValue("sha256"), types.StringValue("")},
+			want: types.StringValue("4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"),
+		},
+		{
+			args: []attr.Value{types.StringValue("s3cr3t"), types.StringValue("sha256"), types.StringValue(salt)},
+			want: types.StringValue(hex.EncodeToString(mac.Sum(nil))),
+		},
+		{
+			args: []attr.Value{types.StringValue("s3cr3t"), types.StringValue("argon2"), types.StringValue(salt)},
+			want: types.StringValue("$argon2id$v=19$m=65536,t=3,p=4$MDEyMzQ1Njc4OWFiY2RlZg$" + base64.RawStdEncoding.EncodeToString(argon2Key)),
+		},
+		{args: []attr.Value{types.StringValue("s3cr3t"), types.StringValue("argon2"), types.StringValue("short")}, err: "argon2 needs a salt of at least 8 bytes, got 5"},
+		{args: []attr.Value{types.StringValue("s3cr3t"), types.StringValue("bcrypt"), types.StringValue(salt)}, err: "algo must be one of: argon2, sha256"},
+	})
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the desired interfaces.
+var _ function.Function = &SplitBillFunction{}
+
+type SplitBillFunction struct{}
+
+func NewFunctionSplitBill() function.Function {
+	return &SplitBillFunction{}
+}
+
+func (f *SplitBillFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "split_bill"
+}
+
+func (f *SplitBillFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Split a bill in equal shares",
+		Description: "Given a total and a number of people, return the share of each person, rounded to the cent. " +
+			"The shares add up to the total: the first shares take the cents that cannot be split evenly.",
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "total",
+				Description: "Total of the bill. It must not be negative.",
+			},
+			function.Int64Parameter{
+				Name:        "n",
+				Description: fmt.Sprintf("Number of people, between 1 and %d.", model.MaxSplitShares),
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.Float64Type,
+		},
+	}
+}
+
+func (f *SplitBillFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var total float64
+	var n int64
+
+	// Read Terraform argument data into the variables
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &total, &n))
+	if resp.Error != nil {
+		return
+	}
+	resp.Error = validatePrice(0, "total", total)
+	if n < 1 || n > model.MaxSplitShares {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(1, fmt.Sprintf("n must be between 1 and %d, got %d", model.MaxSplitShares, n)))
+	}
+	if resp.Error != nil {
+		return
+	}
+	shares, err := model.SplitCents(total, int(n))
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	// Set the result
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, shares))
+}
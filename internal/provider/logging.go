@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// logSubsystem is the name of the logger of the provider code. Its level is the one of log_level, when set.
+const logSubsystem = "provs"
+
+// withSecretsMasked returns a context that masks, in the logs of the root provider logger and of the provider code
+// one, the values of the fields with the given keys, and the given secrets wherever they appear. The protocol server
+// of NewProtocol6Server applies it to the context of every request, with the sensitive attributes of its schema.
+func withSecretsMasked(ctx context.Context, keys []string, secrets []string) context.Context {
+	if len(keys) > 0 {
+		ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, keys...)
+		ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, logSubsystem, keys...)
+	}
+	if len(secrets) > 0 {
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, secrets...)
+		ctx = tflog.MaskMessageStrings(ctx, secrets...)
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystem, secrets...)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystem, secrets...)
+	}
+	return ctx
+}
+
+// withLogLevel returns a context holding the logger of the provider code at the given level, or at the level of the
+// root provider logger when empty. The protocol server of NewProtocol6Server applies it to the context of every
+// request, with the level of the provider configuration.
+func withLogLevel(ctx context.Context, level string) context.Context {
+	if level == "" {
+		return tflog.NewSubsystem(ctx, logSubsystem, tflog.WithRootFields())
+	}
+	return tflog.NewSubsystem(ctx, logSubsystem, tflog.WithRootFields(), tflog.WithLevel(hclog.LevelFromString(level)))
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestSecretEphemeral_logsNoSecret(t *testing.T) {
+	const value = "s3cr3t-value"
+	ctx := context.Background()
+	var logs bytes.Buffer
+	ctx = tflogtest.RootLogger(ctx, &logs)
+
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", value, false, time.Now())
+	mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Go through the protocol server, which sets up the private state of the ephemeral resource like Terraform does
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	ephemeralSchema := schemas.EphemeralResourceSchemas["provs_secret"]
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "provs_secret",
+		Config: testDynamicValue(t, ephemeralSchema, map[string]tftypes.Value{
+			"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+			"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, openResp.Diagnostics)
+	result, err := openResp.Result.Unmarshal(ephemeralSchema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := result.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	if err := attrs["secret"].As(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != value {
+		t.Fatalf("expected the secret %q, got %q", value, got)
+	}
+	if len(openResp.Private) == 0 {
+		t.Fatal("expected the lease to be kept in the private state")
+	}
+
+	if !strings.Contains(logs.String(), "Read secret from secret manager") {
+		t.Fatalf("expected the provider logs to be captured, got:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), value) {
+		t.Fatalf("the secret value leaked in the provider logs:\n%s", logs.String())
+	}
+}
+
+func TestProviderLogLevel(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config string
+		env    string
+		want   string
+		// debug tells whether the debug logs of the provider code are written
+		debug bool
+	}{
+		"configuration over environment": {config: "debug", env: "warn", want: "debug", debug: true},
+		"environment":                    {env: "WARN", want: "warn"},
+		"root provider logger level":     {debug: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testResetProviderEnv(t)
+			t.Setenv(envLogLevel, tc.env)
+			var logs bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &logs)
+
+			storagePath := t.TempDir()
+			backend, err := filesystem.NewFsClient(storagePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mgr := &model.SecretManager{Name: "test"}
+			mgr.PutSecret("db_password", "s3cr3t", false, time.Now())
+			if mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr); err != nil {
+				t.Fatal(err)
+			}
+
+			p := &provsProvider{version: "test", now: time.Now}
+			server := newProtocol6Server(p)()
+			schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			attrs := map[string]tftypes.Value{"path": tftypes.NewValue(tftypes.String, storagePath)}
+			if tc.config != "" {
+				attrs["log_level"] = tftypes.NewValue(tftypes.String, tc.config)
+			}
+			resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: testDynamicValue(t, schemas.Provider, attrs)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			testNoDiagnostics(t, resp.Diagnostics)
+			if got := p.configuredLogLevel(); got != tc.want {
+				t.Fatalf("expected the log level %q, got %q", tc.want, got)
+			}
+			if got := os.Getenv(providerLogEnv); got != "" {
+				t.Fatalf("expected the environment unchanged, got %s=%q", providerLogEnv, got)
+			}
+
+			testOpenEphemeral(ctx, t, server, schemas, "provs_secret", map[string]tftypes.Value{
+				"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+				"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+			})
+			if got := strings.Contains(logs.String(), "Read secret from secret manager"); got != tc.debug {
+				t.Fatalf("expected the debug logs written: %t, got:\n%s", tc.debug, logs.String())
+			}
+		})
+	}
+}
+
+func TestWithSecretsMasked(t *testing.T) {
+	const value = "s3cr3t-value"
+	var logs bytes.Buffer
+	ctx := withSecretsMasked(withLogLevel(tflogtest.RootLogger(context.Background(), &logs), ""), []string{"secret"}, []string{value})
+	fields := map[string]any{
+		"secret_name": "db_password",
+		"secret":      "generated",
+		"detail":      "written " + value,
+		// not secret, whatever its name
+		"value": 4.2,
+	}
+	tflog.Info(ctx, "Writing secret "+value, fields)
+	tflog.SubsystemInfo(ctx, logSubsystem, "Writing secret "+value, fields)
+
+	for _, want := range []string{`"secret_name":"db_password"`, `"value":4.2`, `"secret":"***"`, `"detail":"written ***"`, `"@message":"Writing secret ***"`} {
+		if strings.Count(logs.String(), want) != 2 {
+			t.Fatalf("expected %s logged by both loggers, got:\n%s", want, logs.String())
+		}
+	}
+	if strings.Contains(logs.String(), value) || strings.Contains(logs.String(), "generated") {
+		t.Fatalf("expected the secrets masked in the provider logs:\n%s", logs.String())
+	}
+}
+
+// testSecretsLogServer logs the secret of the resources it reads and reports it in a diagnostic, to check that the
+// secrets server masks it.
+type testSecretsLogServer struct {
+	// ProviderServer is nil, only the methods below are called
+	tfprotov6.ProviderServer
+	secret string
+}
+
+var testSecretsLogSchema = &tfprotov6.Schema{
+	Block: &tfprotov6.SchemaBlock{
+		Attributes: []*tfprotov6.SchemaAttribute{
+			{Name: "id", Type: tftypes.String, Required: true},
+			{Name: "secret", Type: tftypes.String, Required: true, Sensitive: true},
+			{Name: "generator", Optional: true, NestedType: &tfprotov6.SchemaObject{
+				Nesting: tfprotov6.SchemaObjectNestingModeSingle,
+				Attributes: []*tfprotov6.SchemaAttribute{
+					{Name: "seed", Type: tftypes.String, Optional: true, Sensitive: true},
+				},
+			}},
+		},
+	},
+}
+
+func (s testSecretsLogServer) GetProviderSchema(context.Context, *tfprotov6.GetProviderSchemaRequest) (*tfprotov6.GetProviderSchemaResponse, error) {
+	return &tfprotov6.GetProviderSchemaResponse{
+		ResourceSchemas: map[string]*tfprotov6.Schema{"provs_test": testSecretsLogSchema},
+	}, nil
+}
+
+func (s testSecretsLogServer) ReadResource(ctx context.Context, req *tfprotov6.ReadResourceRequest) (*tfprotov6.ReadResourceResponse, error) {
+	tflog.Info(ctx, "Reading secret", map[string]any{"id": "test", "secret": s.secret, "value": "4.2"})
+	return &tfprotov6.ReadResourceResponse{
+		NewState: req.CurrentState,
+		Diagnostics: []*tfprotov6.Diagnostic{{
+			Severity: tfprotov6.DiagnosticSeverityWarning,
+			Summary:  "Secret " + s.secret,
+			Detail:   "Could not check the secret " + s.secret + " generated with the seed n0t-s0-r4nd0m.",
+		}},
+	}, nil
+}
+
+func TestSecretsServer(t *testing.T) {
+	const value = "s3cr3t-value"
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	server := &secretsServer{ProviderServer: testSecretsLogServer{secret: value}}
+
+	objectType := testSecretsLogSchema.ValueType().(tftypes.Object)
+	resp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName: "provs_test",
+		CurrentState: testDynamicValue(t, testSecretsLogSchema, map[string]tftypes.Value{
+			"id":     tftypes.NewValue(tftypes.String, "test"),
+			"secret": tftypes.NewValue(tftypes.String, value),
+			"generator": tftypes.NewValue(objectType.AttributeTypes["generator"], map[string]tftypes.Value{
+				"seed": tftypes.NewValue(tftypes.String, "n0t-s0-r4nd0m"),
+			}),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(logs.String(), "Reading secret") || !strings.Contains(logs.String(), `"secret":"***"`) {
+		t.Fatalf("expected the secret logged masked, got:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), `"value":"4.2"`) {
+		t.Fatalf("expected the fields of the attributes that are not sensitive logged, got:\n%s", logs.String())
+	}
+	if strings.Contains(logs.String(), value) {
+		t.Fatalf("the secret value leaked in the provider logs:\n%s", logs.String())
+	}
+	want := "Secret ***: Could not check the secret *** generated with the seed ***."
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary+": "+resp.Diagnostics[0].Detail != want {
+		t.Fatalf("expected the diagnostic %q, got %v", want, resp.Diagnostics)
+	}
+}
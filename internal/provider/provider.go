@@ -2,16 +2,27 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"os"
+	"strings"
+	"sync/atomic"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
 	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -25,7 +36,63 @@ var (
 
 // provsProviderModel maps provider schema data to a Go type.
 type provsProviderModel struct {
-	Path types.String `tfsdk:"path"`
+	Path              types.String `tfsdk:"path"`
+	Backend           types.String `tfsdk:"backend"`
+	URL               types.String `tfsdk:"url"`
+	Token             types.String `tfsdk:"token"`
+	Timeout           types.String `tfsdk:"timeout"`
+	EncryptionKeyFile types.String `tfsdk:"encryption_key_file"`
+	Principal         types.String `tfsdk:"principal"`
+	SeedCatalog       types.Bool   `tfsdk:"seed_catalog"`
+	DefaultStore      types.String `tfsdk:"default_store"`
+	LogLevel          types.String `tfsdk:"log_level"`
+	ReadOnly          types.Bool   `tfsdk:"read_only"`
+	Stores            types.Map    `tfsdk:"stores"`
+}
+
+// provsStoreModel maps the configuration of a named store.
+type provsStoreModel struct {
+	Type              types.String `tfsdk:"type"`
+	Path              types.String `tfsdk:"path"`
+	URL               types.String `tfsdk:"url"`
+	Token             types.String `tfsdk:"token"`
+	EncryptionKey     types.String `tfsdk:"encryption_key"`
+	EncryptionKeyFile types.String `tfsdk:"encryption_key_file"`
+}
+
+// The backends of the stores
+const (
+	storeTypeFs   = "fs"
+	storeTypeHttp = "http"
+)
+
+// providerData is made available to the data sources, resources and ephemeral resources on Configure.
+// It embeds the storage client so that the components not needing anything else can use it as a client.BackendClient.
+type providerData struct {
+	client.BackendClient
+	// principal is the identity the secret manager policies are evaluated for. Empty when not configured.
+	principal string
+	// audit records the accesses to the secrets
+	audit *audit.Log
+	// stores are the named stores configured in the provider, by name. Shared by the data of all the stores.
+	stores map[string]*providerData
+}
+
+// principalFrom returns the principal configured in the provider, if the provider data carries one.
+func principalFrom(data any) string {
+	if pd, ok := data.(*providerData); ok {
+		return pd.principal
+	}
+	return ""
+}
+
+// auditLogFrom returns the audit log of the store, if the provider data carries one.
+// The nil *audit.Log returned otherwise refuses to record the accesses.
+func auditLogFrom(data any) *audit.Log {
+	if pd, ok := data.(*providerData); ok {
+		return pd.audit
+	}
+	return nil
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -33,6 +100,7 @@ func New(version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &provsProvider{
 			version: version,
+			now:     time.Now,
 		}
 	}
 }
@@ -43,6 +111,18 @@ type provsProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+	// now is the clock handed to the resources that schedule work in time, like secret rotations.
+	// Replaced in tests to move the time forward.
+	now func() time.Time
+	// logLevel is the level of the logs set by the configuration, applied by the protocol server to the requests
+	// following it. Holds an empty string when not set.
+	logLevel atomic.Value
+}
+
+// configuredLogLevel returns the level of the logs set by the configuration, empty when not set
+func (p *provsProvider) configuredLogLevel() string {
+	level, _ := p.logLevel.Load().(string)
+	return level
 }
 
 // Metadata returns the provider type name.
@@ -54,16 +134,128 @@ func (p *provsProvider) Metadata(_ context.Context, _ provider.MetadataRequest,
 // Schema defines the provider-level schema for configuration data.
 func (p *provsProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "The provs provider keeps its objects in stores: a default store, set with path or url, and named stores. " +
+			"Every attribute but stores can be set also with its environment variable, the value of the configuration taking precedence.",
 		Attributes: map[string]schema.Attribute{
 			"path": schema.StringAttribute{
 				Optional: true,
+				Description: "The directory of the default store, for the fs backend. A relative path is resolved against the working directory. " +
+					"Can be set also with the " + envPath + " environment variable.",
+			},
+			"backend": schema.StringAttribute{
+				Optional: true,
+				Description: fmt.Sprintf("The backend of the default store: %q for the directory of path or %q for the shared HTTP store of url. Defaults to %q. ", storeTypeFs, storeTypeHttp, storeTypeFs) +
+					"Can be set also with the " + envBackend + " environment variable.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(storeTypeFs, storeTypeHttp),
+				},
+			},
+			"url": schema.StringAttribute{
+				Optional:    true,
+				Description: "The base URL of the default store, for the http backend. Can be set also with the " + envURL + " environment variable.",
+			},
+			"token": schema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+				Description: "The token authenticating the provider to the default store, for the http backend. It is sent in clear with " +
+					"http URLs: use https URLs for the stores reached through a network. Can be set also with the " + envToken + " environment variable.",
+			},
+			"timeout": schema.StringAttribute{
+				Optional: true,
+				Description: fmt.Sprintf("The timeout of the requests to the http stores, as a duration like \"10s\". Defaults to %s. ", remote.DefaultTimeout) +
+					"Can be set also with the " + envTimeout + " environment variable.",
+			},
+			"encryption_key_file": schema.StringAttribute{
+				Optional: true,
+				Description: fmt.Sprintf("The file holding the base64 encoded %d bytes key encrypting the objects of the default store with AES-256-GCM. ", client.EncryptionKeySize) +
+					"The objects are stored in clear when not set. Can be set also with the " + envEncryptionKeyFile + " environment variable.",
+			},
+			"principal": schema.StringAttribute{
+				Optional:    true,
+				Description: "The identity checked against the secret manager policies. Can be set also with the " + envPrincipal + " environment variable.",
+			},
+			"seed_catalog": schema.BoolAttribute{
+				Optional: true,
+				Description: "Fill the coffee catalog of the default store with demo coffees when it is empty. Defaults to false. " +
+					"Can be set also with the " + envSeedCatalog + " environment variable.",
+			},
+			"default_store": schema.StringAttribute{
+				Optional: true,
+				Description: "The name of the store, among the stores, keeping the objects that do not set their store. " +
+					"Conflicts with the attributes of the default store: path, backend, url, token and encryption_key_file. " +
+					"Can be set also with the " + envDefaultStore + " environment variable.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("path"),
+						path.MatchRoot("backend"),
+						path.MatchRoot("url"),
+						path.MatchRoot("token"),
+						path.MatchRoot("encryption_key_file"),
+					),
+				},
+			},
+			"log_level": schema.StringAttribute{
+				Optional: true,
+				Description: fmt.Sprintf("The level of the logs of the provider, among %s, for the requests following the configuration. ", strings.Join(logLevels, ", ")) +
+					"Can be set also with the " + envLogLevel + " environment variable. Defaults to the " + providerLogEnv + " environment variable of Terraform.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(logLevels...),
+				},
+			},
+			"read_only": schema.BoolAttribute{
+				Optional: true,
+				Description: "Refuse the creations, updates and deletions of objects in all the stores, for the plans and the audits. " +
+					"The resources can still be read, like the data sources and the ephemeral resources. Defaults to false. " +
+					"Can be set also with the " + envReadOnly + " environment variable.",
+			},
+			"stores": schema.MapNestedAttribute{
+				Optional: true,
+				Description: "The named stores, that the resources, data sources and ephemeral resources select with their store attribute. " +
+					"Each store has its own backend and encryption. The stores have no environment variable.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Optional:    true,
+							Description: fmt.Sprintf("The backend of the store: %q for a local directory or %q for a shared HTTP store. Defaults to %q.", storeTypeFs, storeTypeHttp, storeTypeFs),
+							Validators: []validator.String{
+								stringvalidator.OneOf(storeTypeFs, storeTypeHttp),
+							},
+						},
+						"path": schema.StringAttribute{
+							Optional:    true,
+							Description: "The directory of a fs store. A relative path is resolved against the working directory.",
+						},
+						"url": schema.StringAttribute{
+							Optional:    true,
+							Description: "The base URL of an http store.",
+						},
+						"token": schema.StringAttribute{
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The token authenticating the provider to an http store, sent in clear with http URLs.",
+						},
+						"encryption_key": schema.StringAttribute{
+							Optional:  true,
+							Sensitive: true,
+							Description: fmt.Sprintf("The base64 encoded %d bytes key encrypting the objects of the store with AES-256-GCM. "+
+								"The objects are stored in clear when neither it nor encryption_key_file is set.", client.EncryptionKeySize),
+							Validators: []validator.String{
+								stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("encryption_key_file")),
+							},
+						},
+						"encryption_key_file": schema.StringAttribute{
+							Optional:    true,
+							Description: "The file holding the base64 encoded key of the store, instead of encryption_key.",
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
 func (p *provsProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	tflog.Info(ctx, "Configuring provider client")
+	tflog.SubsystemInfo(ctx, logSubsystem, "Configuring provider client")
 	// Retrieve provider data from configuration
 	var config provsProviderModel
 	diags := req.Config.Get(ctx, &config)
@@ -72,93 +264,241 @@ func (p *provsProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
-	// If practitioner provided a configuration value for any of the
-	// attributes, it must be a known value.
-	if config.Path.IsUnknown() {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("path"),
-			"Unknown path for storing provider data",
-			"The provider cannot create the fs client as there is an unknown configuration value for the the storage path. "+
-				"Either target apply the source of the value first, set the value statically in the configuration, or use the PROVS_PATH environment variable.",
-		)
-	}
-
+	// Default values to environment variables, but override with Terraform configuration value if set.
+	// If practitioner provided a configuration value for any of the attributes, it must be a known value.
+	settings, diags := newProviderSettings(config)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Default values to environment variables, but override
-	// with Terraform configuration value if set.
-	storagePath := os.Getenv("PROVS_PATH")
-	if !config.Path.IsNull() {
-		storagePath = config.Path.ValueString()
-	}
-
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
-	if storagePath == "" {
+	// The default store is the store of path or url, or the named store of default_store.
+	// Without any, the objects have to select their store.
+	if settings.defaultStore == nil && settings.defaultStoreName == "" && len(config.Stores.Elements()) == 0 {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("path"),
 			"Missing storage path",
-			"The provider cannot create the Provs API client as there is a missing or empty value for the Provs API host. "+
-				"Set the host value in the configuration or use the PROVS_PATH environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			"The provider cannot create the storage client as there is a missing or empty value for the directory of the default store. "+
+				"Set the path value in the configuration or use the "+envPath+" environment variable. "+
+				"If either is already set, ensure the value is not empty. Alternatively, set the url of an http backend, or configure the stores.",
 		)
+		return
+	}
+
+	p.logLevel.Store(settings.logLevel)
+	ctx = tflog.SubsystemSetField(ctx, logSubsystem, "provs_principal", settings.principal)
+	if settings.defaultStore != nil {
+		ctx = tflog.SubsystemSetField(ctx, logSubsystem, "provs_path", settings.defaultStore.path)
+		ctx = tflog.SubsystemSetField(ctx, logSubsystem, "provs_url", settings.defaultStore.url)
 	}
 
+	tflog.SubsystemDebug(ctx, logSubsystem, "Creating Provs client")
+
+	data := &providerData{
+		principal: settings.principal,
+		stores:    map[string]*providerData{},
+	}
+	stores := map[string]provsStoreModel{}
+	resp.Diagnostics.Append(config.Stores.ElementsAs(ctx, &stores, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for name, store := range stores {
+		storePath := path.Root("stores").AtMapKey(name)
+		var diags diag.Diagnostics
+		cfg := storeConfig{
+			backend:           configString(&diags, storePath.AtName("type"), store.Type, ""),
+			path:              configString(&diags, storePath.AtName("path"), store.Path, ""),
+			url:               configString(&diags, storePath.AtName("url"), store.URL, ""),
+			token:             configString(&diags, storePath.AtName("token"), store.Token, ""),
+			encryptionKey:     configString(&diags, storePath.AtName("encryption_key"), store.EncryptionKey, ""),
+			encryptionKeyFile: configString(&diags, storePath.AtName("encryption_key_file"), store.EncryptionKeyFile, ""),
+		}
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			continue
+		}
+		c, diags := newStoreClient(storePath, cfg, settings.timeout)
+		resp.Diagnostics.Append(diags...)
+		if c == nil {
+			continue
+		}
+		data.stores[name] = &providerData{
+			BackendClient: settings.guard(c),
+			principal:     settings.principal,
+			audit:         audit.NewLog(c, p.now),
+			stores:        data.stores,
+		}
+	}
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	ctx = tflog.SetField(ctx, "provs_path", storagePath)
-
-	tflog.Debug(ctx, "Creating Provs client")
 
 	// Create a new Provs client using the configuration values
-	c, err := filesystem.NewFsClient(storagePath)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to Create storage client",
-			"An unexpected error occurred when creating the Provs API client. "+
-				"If the error is not clear, please contact the provider developers.\n\n"+
-				"Client Error: "+err.Error(),
+	if settings.defaultStore != nil {
+		c, diags := newStoreClient(path.Empty(), *settings.defaultStore, settings.timeout)
+		resp.Diagnostics.Append(diags...)
+		if c == nil {
+			return
+		}
+		data.BackendClient = settings.guard(c)
+		data.audit = audit.NewLog(c, p.now)
+	}
+	if settings.defaultStoreName != "" {
+		store, ok := data.stores[settings.defaultStoreName]
+		if !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("default_store"),
+				"Unknown default store",
+				fmt.Sprintf("There is no store named %s among the stores.", settings.defaultStoreName),
+			)
+			return
+		}
+		data.BackendClient = store.BackendClient
+		data.audit = store.audit
+	}
+
+	if settings.seedCatalog && settings.readOnly {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("seed_catalog"),
+			"Coffee catalog not seeded",
+			"The coffee catalog of the default store is not seeded as the provider is read-only.",
 		)
-		return
+	} else if settings.seedCatalog {
+		if data.BackendClient == nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("seed_catalog"),
+				"Missing default store",
+				"The coffee catalog of the default store cannot be seeded as the provider has no default store. Set the path, the url or the default_store.",
+			)
+			return
+		}
+		tflog.SubsystemDebug(ctx, logSubsystem, "Seeding the coffee catalog")
+		if err := seedCatalog(data.BackendClient); err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to seed the coffee catalog",
+				fmt.Sprintf("Failed to create the demo coffees: %v", err),
+			)
+			return
+		}
 	}
 
-	// Make the client available during DataSource and Resource
+	// Make the client available during DataSource, Resource and EphemeralResource
 	// type Configure methods.
-	resp.DataSourceData = c
-	resp.ResourceData = c
-	tflog.Info(ctx, "Configured Provs client", map[string]any{"success": true})
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.EphemeralResourceData = data
+	tflog.SubsystemInfo(ctx, logSubsystem, "Configured Provs client", map[string]any{"success": true})
+}
+
+// newStoreClient creates the client of a store, at the given path of the provider configuration: the root for the
+// default store.
+func newStoreClient(p path.Path, store storeConfig, timeout time.Duration) (client.BackendClient, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var c client.BackendClient
+	var err error
+	switch store.backend {
+	case "", storeTypeFs:
+		if store.path == "" || store.url != "" || store.token != "" {
+			diags.AddAttributeError(p.AtName("path"), "Invalid store configuration", "A fs store must set its path, and only its path.")
+			return nil, diags
+		}
+		c, err = filesystem.NewFsClient(store.path)
+		if err != nil {
+			diags.AddAttributeError(p.AtName("path"), "Unable to Create storage client", "Client Error: "+err.Error())
+			return nil, diags
+		}
+	case storeTypeHttp:
+		if store.url == "" || store.token == "" || store.path != "" {
+			diags.AddAttributeError(p.AtName("url"), "Invalid store configuration", "An http store must set its url and its token, and not a path.")
+			return nil, diags
+		}
+		c, err = remote.NewHttpClient(store.url, store.token, timeout)
+		if err != nil {
+			diags.AddAttributeError(p.AtName("url"), "Unable to Create storage client", "Client Error: "+err.Error())
+			return nil, diags
+		}
+	}
+
+	key, keyPath := store.encryptionKey, p.AtName("encryption_key")
+	if store.encryptionKeyFile != "" {
+		keyPath = p.AtName("encryption_key_file")
+		content, err := os.ReadFile(store.encryptionKeyFile)
+		if err != nil {
+			diags.AddAttributeError(keyPath, "Unable to read the encryption key", err.Error())
+			return nil, diags
+		}
+		key = strings.TrimSpace(string(content))
+	}
+	if key == "" {
+		return c, diags
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err == nil {
+		c, err = client.NewEncryptedClient(c, decoded)
+	}
+	if err != nil {
+		diags.AddAttributeError(keyPath, "Invalid encryption key", err.Error())
+		return nil, diags
+	}
+	return c, diags
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *provsProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
+		NewAuditLogDataSource,
+		NewCoffeeDataSource,
 		NewCoffeesDataSource,
+		NewCustomerOrdersDataSource,
+		func() datasource.DataSource { return newSecretManagerDataSource(p.now) },
+		func() datasource.DataSource { return newSecretManagersDataSource(p.now) },
+		NewStockDataSource,
+		NewTaxRulesDataSource,
 	}
 }
 
 // Resources defines the resources implemented in the provider.
 func (p *provsProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
-		NewResourceOrder,
+		NewResourceCoffee,
+		NewResourceCustomer,
+		NewResourceIngredient,
+		NewResourceInventory,
+		func() resource.Resource { return newResourceOrder(p.now) },
+		NewResourcePromotion,
 		NewResourceIssue2372,
-		NewResourceSecret,
+		func() resource.Resource { return newResourceSecret(p.now) },
 		NewResourceSecretManager,
+		NewResourceSecretManagerPolicy,
+		NewResourceTaxRule,
 	}
 }
 
 func (p *provsProvider) Functions(context.Context) []func() function.Function {
 	return []func() function.Function{
+		NewFunctionApplyDiscount,
+		NewFunctionComputeRegionTax,
 		NewFunctionComputeTax,
+		NewFunctionConvertCurrency,
+		NewFunctionHashSecret,
+		NewFunctionOrderTotal,
+		NewFunctionParseSecretRef,
+		NewFunctionRoundMoney,
+		NewFunctionSecretRef,
+		NewFunctionSplitBill,
 	}
 }
 
 func (p *provsProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
 	return []func() ephemeral.EphemeralResource{
-		NewEphemeralRandom,
-		NewEphemeralSecret,
+		func() ephemeral.EphemeralResource { return newEphemeralRandom(p.now) },
+		func() ephemeral.EphemeralResource { return newEphemeralPassword(p.now) },
+		func() ephemeral.EphemeralResource { return newEphemeralKeypair(p.now) },
+		func() ephemeral.EphemeralResource { return newEphemeralToken(p.now) },
+		func() ephemeral.EphemeralResource { return newEphemeralSecret(p.now) },
 	}
 }
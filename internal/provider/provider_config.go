@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/remote"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The attributes of the provider can be set also with environment variables, but for the stores. The precedence is:
+//  1. the value of the configuration,
+//  2. the environment variable,
+//  3. the default value.
+//
+// The default store is either the named store of default_store or the store of path, backend, url, token and
+// encryption_key_file. When the configuration sets some of them, the environment variables of the others are ignored,
+// so that a configured default store is never mixed up with the one of the environment. The environment alone sets a
+// default store only with PROVS_PATH or PROVS_URL, so that the other variables, set for every run, do not make one of
+// their own for the configurations with stores only. It cannot set both PROVS_PATH or PROVS_URL and
+// PROVS_DEFAULT_STORE.
+const (
+	envPath              = "PROVS_PATH"
+	envBackend           = "PROVS_BACKEND"
+	envURL               = "PROVS_URL"
+	envToken             = "PROVS_TOKEN"
+	envTimeout           = "PROVS_TIMEOUT"
+	envEncryptionKeyFile = "PROVS_ENCRYPTION_KEY_FILE"
+	envPrincipal         = "PROVS_PRINCIPAL"
+	envSeedCatalog       = "PROVS_SEED_CATALOG"
+	envDefaultStore      = "PROVS_DEFAULT_STORE"
+	envLogLevel          = "PROVS_LOG_LEVEL"
+	envReadOnly          = "PROVS_READ_ONLY"
+)
+
+// providerLogEnv is the environment variable of Terraform setting the level of the provider logs. The plugin SDK reads
+// it, under the name of the provider in its address, for the level of the root provider logger, which the logs of the
+// provider follow when log_level is not set.
+const providerLogEnv = "TF_LOG_PROVIDER_PROVS"
+
+// logLevels are the values of log_level, from the most to the least verbose.
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "off"}
+
+// providerSettings are the values of the provider attributes, resolved from the configuration and the environment.
+type providerSettings struct {
+	// defaultStore is the store of path, backend, url, token and encryption_key_file. Nil when the configuration sets
+	// none of them and the environment neither PROVS_PATH nor PROVS_URL.
+	defaultStore *storeConfig
+	// defaultStoreName is the name of the default store among the stores. Empty when not set.
+	defaultStoreName string
+	// timeout is the timeout of the requests to the http stores
+	timeout     time.Duration
+	principal   string
+	seedCatalog bool
+	// logLevel is the level of the provider logs. Empty when not set.
+	logLevel string
+	// readOnly refuses the writes to the stores
+	readOnly bool
+}
+
+// storeConfig is the configuration of a store, the default store or a named one.
+type storeConfig struct {
+	backend           string
+	path              string
+	url               string
+	token             string
+	encryptionKey     string
+	encryptionKeyFile string
+}
+
+// newProviderSettings resolves the values of the provider attributes, reporting the unknown and invalid ones.
+func newProviderSettings(config provsProviderModel) (providerSettings, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	res := providerSettings{
+		defaultStoreName: configString(&diags, path.Root("default_store"), config.DefaultStore, envDefaultStore),
+		timeout:          remote.DefaultTimeout,
+		principal:        configString(&diags, path.Root("principal"), config.Principal, envPrincipal),
+		seedCatalog:      configBool(&diags, path.Root("seed_catalog"), config.SeedCatalog, envSeedCatalog),
+		logLevel:         strings.ToLower(configString(&diags, path.Root("log_level"), config.LogLevel, envLogLevel)),
+		readOnly:         configBool(&diags, path.Root("read_only"), config.ReadOnly, envReadOnly),
+	}
+	if config.Stores.IsUnknown() {
+		addUnknownError(&diags, path.Root("stores"), "")
+	}
+
+	if timeout := configString(&diags, path.Root("timeout"), config.Timeout, envTimeout); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			diags.AddAttributeError(
+				path.Root("timeout"),
+				"Invalid timeout",
+				fmt.Sprintf("The timeout must be a positive duration, like \"10s\", got %q.", timeout),
+			)
+		}
+		res.timeout = d
+	}
+	if res.logLevel != "" && !slices.Contains(logLevels, res.logLevel) {
+		diags.AddAttributeError(
+			path.Root("log_level"),
+			"Invalid log level",
+			fmt.Sprintf("The log level must be one of %s, got %q.", strings.Join(logLevels, ", "), res.logLevel),
+		)
+	}
+
+	store := storeConfig{
+		backend:           configString(&diags, path.Root("backend"), config.Backend, envBackend),
+		path:              configString(&diags, path.Root("path"), config.Path, envPath),
+		url:               configString(&diags, path.Root("url"), config.URL, envURL),
+		token:             configString(&diags, path.Root("token"), config.Token, envToken),
+		encryptionKeyFile: configString(&diags, path.Root("encryption_key_file"), config.EncryptionKeyFile, envEncryptionKeyFile),
+	}
+	if store.backend != "" && store.backend != storeTypeFs && store.backend != storeTypeHttp {
+		diags.AddAttributeError(
+			path.Root("backend"),
+			"Invalid backend",
+			fmt.Sprintf("The backend must be %q or %q, got %q.", storeTypeFs, storeTypeHttp, store.backend),
+		)
+	}
+	configured := !config.Path.IsNull() || !config.Backend.IsNull() || !config.URL.IsNull() || !config.Token.IsNull() ||
+		!config.EncryptionKeyFile.IsNull()
+	switch {
+	case configured:
+		if config.DefaultStore.IsNull() {
+			// the default store of the configuration takes precedence over the one of PROVS_DEFAULT_STORE
+			res.defaultStoreName = ""
+		}
+		if res.defaultStoreName == "" {
+			res.defaultStore = &store
+		}
+	case store.path == "" && store.url == "":
+		// the other variables of the environment make no store
+	case !config.DefaultStore.IsNull():
+		// the default_store of the configuration takes precedence over the store of the environment
+	case res.defaultStoreName != "":
+		diags.AddAttributeError(
+			path.Root("default_store"),
+			"Conflicting default stores",
+			fmt.Sprintf("Both the %s environment variable and the %s or %s environment variables set the default store. Unset either.",
+				envDefaultStore, envPath, envURL),
+		)
+	default:
+		res.defaultStore = &store
+	}
+	return res, diags
+}
+
+// guard returns the client of a store refusing the writes when the provider is read-only. The audit log of the store
+// is kept with the client itself, as the reads of the secrets are still recorded.
+func (s providerSettings) guard(c client.BackendClient) client.BackendClient {
+	if !s.readOnly {
+		return c
+	}
+	// the ephemeral secrets, that only read the secrets, keep their leases in the store
+	return client.NewReadOnlyClient(c, typeSecretLease)
+}
+
+// configString returns the value of the string attribute at p, or of the environment variable env when the attribute
+// is null. Without environment variable, env is empty.
+func configString(diags *diag.Diagnostics, p path.Path, value types.String, env string) string {
+	switch {
+	case value.IsUnknown():
+		addUnknownError(diags, p, env)
+		return ""
+	case !value.IsNull():
+		return value.ValueString()
+	case env != "":
+		return os.Getenv(env)
+	}
+	return ""
+}
+
+// configBool returns the value of the bool attribute at p, or of the environment variable env when the attribute is
+// null. It is false when neither is set.
+func configBool(diags *diag.Diagnostics, p path.Path, value types.Bool, env string) bool {
+	switch {
+	case value.IsUnknown():
+		addUnknownError(diags, p, env)
+		return false
+	case !value.IsNull():
+		return value.ValueBool()
+	}
+	v := os.Getenv(env)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		diags.AddAttributeError(
+			p,
+			"Invalid "+env,
+			fmt.Sprintf("The %s environment variable must be a boolean, like true or false, got %q.", env, v),
+		)
+	}
+	return b
+}
+
+// addUnknownError reports the unknown value of the attribute at p, the provider not being able to configure itself
+// before the value is known.
+func addUnknownError(diags *diag.Diagnostics, p path.Path, env string) {
+	detail := fmt.Sprintf("The provider cannot be configured as there is an unknown configuration value for %s. ", p)
+	if env == "" {
+		detail += "Either target apply the source of the value first or set the value statically in the configuration."
+	} else {
+		detail += "Either target apply the source of the value first, set the value statically in the configuration, " +
+			"or use the " + env + " environment variable."
+	}
+	diags.AddAttributeError(p, "Unknown "+p.String(), detail)
+}
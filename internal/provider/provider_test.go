@@ -1,10 +1,24 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"terraform-provider-provs/internal/model"
 	"testing"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
 )
 
@@ -12,16 +26,25 @@ import (
 // The factory function is called for each Terraform CLI command to create a provider
 // server that the CLI can connect to and interact with.
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"scaffolding": providerserver.NewProtocol6WithError(New("test")()),
+	"provs": testAccProtoV6ProviderFactory(New("test")()),
 }
 
-// testAccProtoV6ProviderFactoriesWithEcho includes the echo provider alongside the scaffolding provider.
+// testAccProtoV6ProviderFactoriesWithEcho includes the echo provider alongside the provs provider.
 // It allows for testing assertions on data returned by an ephemeral resource during Open.
 // The echoprovider is used to arrange tests by echoing ephemeral data into the Terraform state.
 // This lets the data be referenced in test assertions with state checks.
 var testAccProtoV6ProviderFactoriesWithEcho = map[string]func() (tfprotov6.ProviderServer, error){
-	"scaffolding": providerserver.NewProtocol6WithError(New("test")()),
-	"echo":        echoprovider.NewProviderServer(),
+	"provs": testAccProtoV6ProviderFactory(New("test")()),
+	"echo":  echoprovider.NewProviderServer(),
+}
+
+// testAccProtoV6ProviderFactory returns the factory of the protocol servers of the provider, masking the secrets
+// like the server of the provider binary.
+func testAccProtoV6ProviderFactory(p provider.Provider) func() (tfprotov6.ProviderServer, error) {
+	server := newProtocol6Server(p)
+	return func() (tfprotov6.ProviderServer, error) {
+		return server(), nil
+	}
 }
 
 func testAccPreCheck(t *testing.T) {
@@ -29,3 +52,617 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+// testAccProviderConfig returns the provider block pointing the storage to the given directory.
+// Use t.TempDir() to get a directory that is cleaned up after each test.
+func testAccProviderConfig(storagePath string) string {
+	return fmt.Sprintf(`
+provider "provs" {
+  path = %q
+}
+`, storagePath)
+}
+
+// testProtocolServer returns the provider server configured with the given storage, for the tests calling
+// the provider without Terraform. It sets up the private state of the ephemeral resources like Terraform does.
+func testProtocolServer(ctx context.Context, t *testing.T, storagePath string, now func() time.Time) (tfprotov6.ProviderServer, *tfprotov6.GetProviderSchemaResponse) {
+	t.Helper()
+	server, schemas, resp := testConfigureProvider(ctx, t, now, map[string]tftypes.Value{
+		"path": tftypes.NewValue(tftypes.String, storagePath),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+	return server, schemas
+}
+
+// testConfigureProvider returns the provider server configured with the given attributes, the others being null,
+// and the response of the configuration.
+func testConfigureProvider(ctx context.Context, t *testing.T, now func() time.Time, attrs map[string]tftypes.Value) (tfprotov6.ProviderServer, *tfprotov6.GetProviderSchemaResponse, *tfprotov6.ConfigureProviderResponse) {
+	t.Helper()
+	server := newProtocol6Server(&provsProvider{version: "test", now: now})()
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config: testDynamicValue(t, schemas.Provider, attrs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, schemas, resp
+}
+
+// testDynamicValue builds the value of the schema from the given attributes, the others being null
+func testDynamicValue(t *testing.T, schema *tfprotov6.Schema, attrs map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+	typ := schema.ValueType().(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name, attrType := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(attrType, nil)
+		if v, ok := attrs[name]; ok {
+			vals[name] = v
+		}
+	}
+	dv, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, vals))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dv
+}
+
+func testNoDiagnostics(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+		}
+	}
+}
+
+// testOpenEphemeral opens the ephemeral resource with the given attributes and returns the attributes of its result
+func testOpenEphemeral(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, typeName string, attrs map[string]tftypes.Value) (map[string]tftypes.Value, *tfprotov6.OpenEphemeralResourceResponse) {
+	t.Helper()
+	schema := schemas.EphemeralResourceSchemas[typeName]
+	resp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: typeName,
+		Config:   testDynamicValue(t, schema, attrs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, resp.Diagnostics)
+	result, err := resp.Result.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]tftypes.Value
+	if err := result.As(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res, resp
+}
+
+// testReadDataSource reads the data source with the given configuration, the attributes not given being null, and
+// returns the attributes of its state
+func testReadDataSource(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, typeName string, attrs map[string]tftypes.Value) map[string]tftypes.Value {
+	t.Helper()
+	schema := schemas.DataSourceSchemas[typeName]
+	resp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   testDynamicValue(t, schema, attrs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, resp.Diagnostics)
+	state, err := resp.State.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]tftypes.Value
+	if err := state.As(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// testStringAttr returns the value of the string attribute
+func testStringAttr(t *testing.T, attrs map[string]tftypes.Value, name string) string {
+	t.Helper()
+	var res string
+	if err := attrs[name].As(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestProviderConfigure(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{7}, client.EncryptionKeySize)
+
+	// fixture are the directories and the http store of a case
+	type fixture struct {
+		configPath string
+		envPath    string
+		localPath  string
+		servedPath string
+		url        string
+		keyFile    string
+		cwd        string
+	}
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	unknown := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+
+	for name, tc := range map[string]struct {
+		attrs func(f fixture) map[string]tftypes.Value
+		env   func(f fixture) map[string]string
+		// want are the summaries of the diagnostics
+		want  string
+		check func(t *testing.T, f fixture)
+	}{
+		"path of the configuration": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"path": str(f.configPath), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.configPath, nil)
+			},
+		},
+		"path of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envSeedCatalog: "true"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.envPath, nil)
+			},
+		},
+		"configuration over environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"path": str(f.configPath), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envSeedCatalog: "false"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testNoStore(t, f.envPath)
+				testSeededCatalog(t, f.configPath, nil)
+			},
+		},
+		"relative path": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				// relative to the working directory of the test, that is left unchanged
+				wd, err := os.Getwd()
+				if err != nil {
+					t.Fatal(err)
+				}
+				rel, err := filepath.Rel(wd, filepath.Join(f.cwd, "store"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return map[string]tftypes.Value{"path": str(rel), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, filepath.Join(f.cwd, "store"), nil)
+			},
+		},
+		"http backend of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envBackend: "http", envURL: f.url, envToken: testStoreToken, envTimeout: "5s", envSeedCatalog: "true"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.servedPath, nil)
+			},
+		},
+		"timeout of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"backend": str("http"), "url": str(f.url), "token": str(testStoreToken), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envTimeout: "1ns"}
+			},
+			want: "Failed to seed the coffee catalog",
+		},
+		"encryption key file of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"path": str(f.configPath), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envEncryptionKeyFile: f.keyFile}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.configPath, key)
+			},
+		},
+		"encryption key file of a store": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"stores":        testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath, "encryption_key_file": f.keyFile}}),
+					"default_store": str("local"),
+					"seed_catalog":  tftypes.NewValue(tftypes.Bool, true),
+				}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.localPath, key)
+			},
+		},
+		"default store of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"stores": testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}})}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envDefaultStore: "local", envSeedCatalog: "1"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.localPath, nil)
+			},
+		},
+		"default store and path of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"stores": testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}})}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envDefaultStore: "local", envPath: f.envPath}
+			},
+			want: "Conflicting default stores",
+		},
+		"default store of the configuration over path of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"stores":        testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}}),
+					"default_store": str("local"),
+					"seed_catalog":  tftypes.NewValue(tftypes.Bool, true),
+				}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath}
+			},
+			check: func(t *testing.T, f fixture) {
+				testNoStore(t, f.envPath)
+				testSeededCatalog(t, f.localPath, nil)
+			},
+		},
+		"stores only with the encryption key file and the backend of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"stores": testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}})}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envEncryptionKeyFile: f.keyFile, envBackend: "http"}
+			},
+		},
+		"path of the configuration over default store of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"path":         str(f.configPath),
+					"stores":       testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}}),
+					"seed_catalog": tftypes.NewValue(tftypes.Bool, true),
+				}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envDefaultStore: "local"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.configPath, nil)
+			},
+		},
+		"read only of the environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{"path": str(f.configPath), "seed_catalog": tftypes.NewValue(tftypes.Bool, true)}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envReadOnly: "true"}
+			},
+			want: "Coffee catalog not seeded",
+			check: func(t *testing.T, f fixture) {
+				testNoStore(t, filepath.Join(f.configPath, typeCoffees))
+			},
+		},
+		"read only of the configuration over environment": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"path":         str(f.configPath),
+					"read_only":    tftypes.NewValue(tftypes.Bool, false),
+					"seed_catalog": tftypes.NewValue(tftypes.Bool, true),
+				}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envReadOnly: "true"}
+			},
+			check: func(t *testing.T, f fixture) {
+				testSeededCatalog(t, f.configPath, nil)
+			},
+		},
+		"no store": {
+			want: "Missing storage path",
+		},
+		"empty path of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: ""}
+			},
+			want: "Missing storage path",
+		},
+		"unknown values": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"path":         unknown,
+					"principal":    unknown,
+					"timeout":      unknown,
+					"seed_catalog": tftypes.NewValue(tftypes.Bool, tftypes.UnknownValue),
+				}
+			},
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath}
+			},
+			want: "Unknown principal, Unknown seed_catalog, Unknown timeout, Unknown path",
+		},
+		"unknown store": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				stores := testStoresValue(t, map[string]map[string]string{"local": {"path": f.localPath}})
+				var vals map[string]tftypes.Value
+				if err := stores.As(&vals); err != nil {
+					t.Fatal(err)
+				}
+				var store map[string]tftypes.Value
+				if err := vals["local"].As(&store); err != nil {
+					t.Fatal(err)
+				}
+				store["path"] = unknown
+				vals["local"] = tftypes.NewValue(vals["local"].Type(), store)
+				return map[string]tftypes.Value{"stores": tftypes.NewValue(stores.Type(), vals)}
+			},
+			want: `Unknown stores["local"].path`,
+		},
+		"invalid timeout of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envTimeout: "soon"}
+			},
+			want: "Invalid timeout",
+		},
+		"invalid seed_catalog of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envSeedCatalog: "maybe"}
+			},
+			want: "Invalid " + envSeedCatalog,
+		},
+		"invalid backend of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envBackend: "s3"}
+			},
+			want: "Invalid backend",
+		},
+		"invalid log level of the environment": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envPath: f.envPath, envLogLevel: "verbose"}
+			},
+			want: "Invalid log level",
+		},
+		"http backend without url": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envBackend: "http", envPath: f.envPath}
+			},
+			want: "Invalid store configuration",
+		},
+		"http backend of the environment only": {
+			env: func(f fixture) map[string]string {
+				return map[string]string{envBackend: "http"}
+			},
+			want: "Missing storage path",
+		},
+		"missing encryption key file": {
+			attrs: func(f fixture) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"path":                str(f.configPath),
+					"encryption_key_file": str(filepath.Join(f.cwd, "missing.key")),
+				}
+			},
+			want: "Unable to read the encryption key",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testResetProviderEnv(t)
+
+			f := fixture{
+				configPath: filepath.Join(t.TempDir(), "config"),
+				envPath:    filepath.Join(t.TempDir(), "env"),
+				localPath:  filepath.Join(t.TempDir(), "local"),
+				servedPath: t.TempDir(),
+				keyFile:    filepath.Join(t.TempDir(), "store.key"),
+				cwd:        t.TempDir(),
+			}
+			served, err := filesystem.NewFsClient(f.servedPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			storeServer := httptest.NewServer(remote.NewHandler(served, testStoreToken))
+			defer storeServer.Close()
+			f.url = storeServer.URL
+			if err := os.WriteFile(f.keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			attrs := map[string]tftypes.Value{}
+			if tc.attrs != nil {
+				attrs = tc.attrs(f)
+			}
+			if tc.env != nil {
+				for k, v := range tc.env(f) {
+					t.Setenv(k, v)
+				}
+			}
+			_, _, resp := testConfigureProvider(ctx, t, time.Now, attrs)
+			if got := testDiagnosticSummaries(resp.Diagnostics); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+			if tc.check != nil {
+				tc.check(t, f)
+			}
+		})
+	}
+}
+
+// testResetProviderEnv unsets the environment variables of the provider for the test, so that it configures the
+// provider of the test only, whatever the environment of the test run
+func testResetProviderEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{envPath, envBackend, envURL, envToken, envTimeout, envEncryptionKeyFile, envPrincipal, envSeedCatalog, envDefaultStore, envLogLevel, envReadOnly} {
+		t.Setenv(env, "")
+	}
+	t.Setenv(providerLogEnv, "")
+}
+
+func TestProviderReadOnly(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).Create(&model.Coffee{ID: "espresso", Name: "Espresso"}); err != nil {
+		t.Fatal(err)
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, typeIngredient)
+	if _, err := ingredients.Create(&model.Ingredient{ID: "milk", Name: "Milk", Unit: "ml"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", "v1", false, now)
+	if mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr); err != nil {
+		t.Fatal(err)
+	}
+	// created before the name index existed
+	legacy, err := client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(&model.SecretManager{Name: "legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas, resp := testConfigureProvider(ctx, t, func() time.Time { return now }, map[string]tftypes.Value{
+		"path":      tftypes.NewValue(tftypes.String, storagePath),
+		"read_only": tftypes.NewValue(tftypes.Bool, true),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+
+	// the plans, the data sources and the ephemeral resources read the store
+	testNoDiagnostics(t, testPlanOrder(ctx, t, server, schemas, "espresso", 1, nil).Diagnostics)
+	if rules := testReadTaxRules(ctx, t, server, schemas, ""); len(rules) != 0 {
+		t.Fatalf("expected no tax rule, got %v", rules)
+	}
+	secret, _ := testOpenEphemeral(ctx, t, server, schemas, "provs_secret", map[string]tftypes.Value{
+		"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+		"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+	})
+	if got := testStringAttr(t, secret, "secret"); got != "v1" {
+		t.Fatalf("expected the secret read, got %q", got)
+	}
+	byName := testReadDataSource(ctx, t, server, schemas, "provs_secret_manager", map[string]tftypes.Value{
+		"name": tftypes.NewValue(tftypes.String, "legacy"),
+	})
+	if got := testStringAttr(t, byName, "id"); got != legacy.ID {
+		t.Fatalf("expected the secret manager %s, got %s", legacy.ID, got)
+	}
+
+	// the resources are refreshed
+	refreshed := testReadResource(ctx, t, server, schemas, "provs_secret", map[string]tftypes.Value{
+		"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+		"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+	})
+	if got := testStringAttr(t, refreshed, "secret"); got != "v1" {
+		t.Fatalf("expected the secret refreshed, got %q", got)
+	}
+
+	// the objects cannot be created, updated or deleted
+	schema := schemas.ResourceSchemas["provs_ingredient"]
+	typ := schema.ValueType()
+	prior, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applyResp, err := server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:   "provs_ingredient",
+		PriorState: &prior,
+		PlannedState: testDynamicValue(t, schema, map[string]tftypes.Value{
+			"id":   tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			"name": tftypes.NewValue(tftypes.String, "Milk"),
+			"unit": tftypes.NewValue(tftypes.String, "ml"),
+		}),
+		Config: testDynamicValue(t, schema, map[string]tftypes.Value{
+			"name": tftypes.NewValue(tftypes.String, "Milk"),
+			"unit": tftypes.NewValue(tftypes.String, "ml"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testReadOnlyRefused(t, applyResp.Diagnostics, "creation")
+
+	milk := map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, "milk"),
+		"name": tftypes.NewValue(tftypes.String, "Milk"),
+		"unit": tftypes.NewValue(tftypes.String, "ml"),
+	}
+	oatMilk := map[string]tftypes.Value{
+		"id":   tftypes.NewValue(tftypes.String, "milk"),
+		"name": tftypes.NewValue(tftypes.String, "Oat milk"),
+		"unit": tftypes.NewValue(tftypes.String, "ml"),
+	}
+	applyResp, err = server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "provs_ingredient",
+		PriorState:   testDynamicValue(t, schema, milk),
+		PlannedState: testDynamicValue(t, schema, oatMilk),
+		Config:       testDynamicValue(t, schema, map[string]tftypes.Value{"name": oatMilk["name"], "unit": oatMilk["unit"]}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testReadOnlyRefused(t, applyResp.Diagnostics, "update")
+	testReadOnlyRefused(t, testApplyDestroy(ctx, t, server, schemas, "provs_ingredient", milk).Diagnostics, "deletion")
+
+	all, err := ingredients.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Name != "Milk" {
+		t.Fatalf("expected the ingredients unchanged, got %v", all)
+	}
+	if _, err := backend.ReadAll(typeSecretManager + "_names"); !client.IsNotFound(err) {
+		t.Fatalf("expected no name index, got %v", err)
+	}
+}
+
+// testReadOnlyRefused checks that the diagnostics only report the write refused by the read-only provider
+func testReadOnlyRefused(t *testing.T, diags []*tfprotov6.Diagnostic, write string) {
+	t.Helper()
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail, client.ErrReadOnly.Error()) {
+		t.Fatalf("expected the %s refused as read-only, got %s", write, testDiagnosticSummaries(diags))
+	}
+}
+
+// testSeededCatalog checks that the store of the directory holds the demo coffees, encrypted with the key when not nil
+func testSeededCatalog(t *testing.T, dir string, key []byte) {
+	t.Helper()
+	backend, err := filesystem.NewFsClient(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != nil {
+		if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).GetAll(); err == nil {
+			t.Fatal("expected the coffees encrypted")
+		}
+		if backend, err = client.NewEncryptedClient(backend, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coffees, err := client.NewClient[*model.Coffee](backend, typeCoffees).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coffees) == 0 {
+		t.Fatalf("expected the catalog of %s seeded", dir)
+	}
+}
+
+// testNoStore checks that no store was created in the directory
+func testNoStore(t *testing.T, dir string) {
+	t.Helper()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected no store in %s, got %v", dir, err)
+	}
+}
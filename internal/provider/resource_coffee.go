@@ -0,0 +1,389 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &coffeeResource{}
+	_ resource.ResourceWithConfigure   = &coffeeResource{}
+	_ resource.ResourceWithImportState = &coffeeResource{}
+)
+
+// coffeeResourceModel maps the resource schema data.
+type coffeeResourceModel struct {
+	ID          types.String            `tfsdk:"id"`
+	Name        types.String            `tfsdk:"name"`
+	Teaser      types.String            `tfsdk:"teaser"`
+	Description types.String            `tfsdk:"description"`
+	Price       types.Float64           `tfsdk:"price"`
+	Image       types.String            `tfsdk:"image"`
+	Category    types.String            `tfsdk:"category"`
+	Ingredients []coffeeIngredientModel `tfsdk:"ingredients"`
+	Store       types.String            `tfsdk:"store"`
+}
+
+// coffeeIngredientModel references an ingredient of the coffee.
+type coffeeIngredientModel struct {
+	ID       types.String `tfsdk:"id"`
+	Quantity types.Int64  `tfsdk:"quantity"`
+}
+
+// NewResourceCoffee is a helper function to simplify the provider implementation.
+func NewResourceCoffee() resource.Resource {
+	return &coffeeResource{}
+}
+
+// coffeeResource manages the coffees of the catalog, the ones listed by the provs_coffees data source.
+type coffeeResource struct {
+	client       client.Client[*model.Coffee]
+	ingredients  client.Client[*model.Ingredient]
+	orders       client.Client[*model.Order]
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *coffeeResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeCoffee
+}
+
+// Schema defines the schema for the resource.
+func (r *coffeeResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A coffee of the catalog.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"teaser": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(""),
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(""),
+			},
+			"price": schema.Float64Attribute{
+				Required: true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"image": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "The path or the URL of the image of the coffee.",
+			},
+			"category": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: "The category of the coffee, like \"espresso\" or \"iced\". Promotions can be scoped to categories.",
+			},
+			"ingredients": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "The ingredients the coffee is made with, each one at most once.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Required:    true,
+							Description: "The ID of a provs_ingredient.",
+						},
+						"quantity": schema.Int64Attribute{
+							Required:    true,
+							Description: "The quantity of the ingredient in the coffee, in the unit of the ingredient.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+							},
+						},
+					},
+				},
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *coffeeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan coffeeResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	resp.Diagnostics.Append(r.checkIngredients(plan.Ingredients)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffee := plan.toModel()
+	coffee.ID = uuid.NewString()
+	if _, err := r.client.Create(coffee); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating coffee",
+			"Could not create coffee, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(coffee.ID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *coffeeResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state coffeeResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffee, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Coffee",
+			fmt.Sprintf("Could not read coffee ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state = coffeeFromModel(coffee, state.Store)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *coffeeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan coffeeResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	resp.Diagnostics.Append(r.checkIngredients(plan.Ingredients)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffee := plan.toModel()
+	if err := r.client.Update(coffee); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Coffee",
+			fmt.Sprintf("Could not update coffee ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the coffee, unless orders use it.
+func (r *coffeeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state coffeeResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	orders, err := r.orders.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Orders",
+			err.Error(),
+		)
+		return
+	}
+	var usedBy []string
+	for _, o := range orders {
+		for _, item := range o.Items {
+			if item.Coffee.ID == state.ID.ValueString() {
+				usedBy = append(usedBy, o.ID)
+				break
+			}
+		}
+	}
+	if len(usedBy) > 0 {
+		sort.Strings(usedBy)
+		resp.Diagnostics.AddError(
+			"Coffee in use",
+			fmt.Sprintf("The coffee %s cannot be deleted while orders use it: %s. Delete these orders or remove the coffee from their items first.",
+				state.ID.ValueString(), strings.Join(usedBy, ", ")),
+		)
+		return
+	}
+
+	if err := r.client.Delete(state.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Coffee",
+			fmt.Sprintf("Could not delete coffee ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+func (r *coffeeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *coffeeResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	// typeCoffees because the coffees are stored where the provs_coffees data source reads them
+	r.client = client.NewClient[*model.Coffee](c, typeCoffees)
+	r.ingredients = client.NewClient[*model.Ingredient](c, typeIngredient)
+	r.orders = client.NewClient[*model.Order](c, typeOrder)
+}
+
+// checkIngredients returns an error for every ingredient referenced by the coffee that does not exist
+func (r *coffeeResource) checkIngredients(ingredients []coffeeIngredientModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	seen := map[string]bool{}
+	for i, ingredient := range ingredients {
+		id := ingredient.ID.ValueString()
+		if seen[id] {
+			diags.AddAttributeError(
+				path.Root("ingredients").AtListIndex(i).AtName("id"),
+				"Duplicate coffee ingredient",
+				fmt.Sprintf("The ingredient %q is referenced more than once. Set its total quantity on a single item instead.", id),
+			)
+			continue
+		}
+		seen[id] = true
+		_, err := r.ingredients.GetByID(id)
+		if client.IsNotFound(err) {
+			diags.AddAttributeError(
+				path.Root("ingredients").AtListIndex(i).AtName("id"),
+				"Unknown coffee ingredient",
+				fmt.Sprintf("There is no ingredient with the ID %q. Create it with a provs_ingredient resource first.", id),
+			)
+			continue
+		}
+		if err != nil {
+			diags.AddError(
+				"Error Reading Ingredient",
+				fmt.Sprintf("Could not read ingredient ID %s: %s", id, err),
+			)
+		}
+	}
+	return diags
+}
+
+func (m coffeeResourceModel) toModel() *model.Coffee {
+	res := &model.Coffee{
+		ID:          m.ID.ValueString(),
+		Name:        m.Name.ValueString(),
+		Teaser:      m.Teaser.ValueString(),
+		Description: m.Description.ValueString(),
+		Price:       m.Price.ValueFloat64(),
+		Image:       m.Image.ValueString(),
+		Category:    m.Category.ValueString(),
+	}
+	for _, i := range m.Ingredients {
+		res.Ingredient = append(res.Ingredient, model.CoffeeIngredient{
+			IngredientID: i.ID.ValueString(),
+			Quantity:     int(i.Quantity.ValueInt64()),
+		})
+	}
+	return res
+}
+
+func coffeeFromModel(coffee *model.Coffee, store types.String) coffeeResourceModel {
+	res := coffeeResourceModel{
+		ID:          types.StringValue(coffee.ID),
+		Name:        types.StringValue(coffee.Name),
+		Teaser:      types.StringValue(coffee.Teaser),
+		Description: types.StringValue(coffee.Description),
+		Price:       types.Float64Value(coffee.Price),
+		Image:       types.StringValue(coffee.Image),
+		Category:    types.StringValue(coffee.Category),
+		Store:       store,
+	}
+	for _, i := range coffee.Ingredient {
+		res.Ingredients = append(res.Ingredients, coffeeIngredientModel{
+			ID:       types.StringValue(i.IngredientID),
+			Quantity: types.Int64Value(int64(i.Quantity)),
+		})
+	}
+	return res
+}
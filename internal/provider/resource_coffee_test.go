@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccCoffeeResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCoffeeResourceConfig(storagePath, "Espresso", 2.5),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("provs_coffee.test", "id"),
+					resource.TestCheckResourceAttr("provs_coffee.test", "name", "Espresso"),
+					resource.TestCheckResourceAttr("provs_coffee.test", "price", "2.5"),
+					resource.TestCheckResourceAttr("provs_coffee.test", "teaser", ""),
+					resource.TestCheckResourceAttr("provs_coffee.test", "ingredients.#", "1"),
+					resource.TestCheckResourceAttrPair("provs_coffee.test", "ingredients.0.id", "provs_ingredient.beans", "id"),
+					resource.TestCheckResourceAttr("provs_coffee.test", "ingredients.0.quantity", "7"),
+				),
+			},
+			{
+				ResourceName:      "provs_coffee.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccCoffeeResourceConfig(storagePath, "Double espresso", 3.2) + `
+data "provs_coffees" "all" {
+  depends_on = [provs_coffee.test]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_coffees.all", tfjsonpath.New("coffees"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"name":  knownvalue.StringExact("Double espresso"),
+							"price": knownvalue.Float64Exact(3.2),
+						}),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestAccCoffeeResource_unknownIngredient(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_coffee" "test" {
+  name  = "Espresso"
+  price = 2.5
+  ingredients = [{
+    id       = "missing"
+    quantity = 7
+  }]
+}
+`,
+				ExpectError: regexp.MustCompile(`There is no ingredient with the ID "missing"`),
+			},
+		},
+	})
+}
+
+func TestAccCoffeesDataSource_seedCatalog(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// nothing is seeded unless asked for
+				Config: testAccProviderConfig(storagePath) + `
+data "provs_coffees" "all" {}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_coffees.all", tfjsonpath.New("coffees"), knownvalue.Null()),
+				},
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "provs" {
+  path         = %q
+  seed_catalog = true
+}
+
+data "provs_coffees" "all" {}
+`, storagePath),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_coffees.all", tfjsonpath.New("coffees"), knownvalue.ListSizeExact(9)),
+				},
+			},
+		},
+	})
+}
+
+func TestCoffeeResource_deleteInUse(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coffees := client.NewClient[*model.Coffee](backend, typeCoffees)
+	orders := client.NewClient[*model.Order](backend, typeOrder)
+	if _, err := coffees.Create(&model.Coffee{ID: "espresso", Name: "Espresso"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orders.Create(&model.Order{
+		ID:    "1",
+		Items: []model.OrderItem{{Coffee: model.Coffee{ID: "espresso"}, Quantity: 1}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := map[string]tftypes.Value{"id": tftypes.NewValue(tftypes.String, "espresso")}
+
+	resp := testApplyDestroy(ctx, t, server, schemas, "provs_coffee", state)
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Coffee in use" {
+		t.Fatalf("expected the deletion refused, got %v", resp.Diagnostics)
+	}
+	if _, err := coffees.GetByID("espresso"); err != nil {
+		t.Fatalf("expected the coffee kept, got %v", err)
+	}
+
+	if err := orders.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, testApplyDestroy(ctx, t, server, schemas, "provs_coffee", state).Diagnostics)
+	if _, err := coffees.GetByID("espresso"); !client.IsNotFound(err) {
+		t.Fatalf("expected the coffee deleted, got %v", err)
+	}
+}
+
+func testAccCoffeeResourceConfig(storagePath string, name string, price float64) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_ingredient" "beans" {
+  name = "Coffee beans"
+  unit = "g"
+}
+
+resource "provs_coffee" "test" {
+  name  = %q
+  price = %v
+  ingredients = [{
+    id       = provs_ingredient.beans.id
+    quantity = 7
+  }]
+}
+`, name, price)
+}
+
+// TestSeedCatalog_unreadableIngredient stops the seeding at an ingredient that cannot be read.
+func TestSeedCatalog_unreadableIngredient(t *testing.T) {
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.CreateWithId(typeIngredient, "3", strings.NewReader("not json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := seedCatalog(backend); err == nil {
+		t.Fatal("expected an error reading the ingredient")
+	}
+	if _, err := backend.ReadAll(typeCoffees); !client.IsNotFound(err) {
+		t.Fatalf("expected no coffee seeded, got %v", err)
+	}
+}
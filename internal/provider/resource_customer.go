@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &customerResource{}
+	_ resource.ResourceWithConfigure   = &customerResource{}
+	_ resource.ResourceWithImportState = &customerResource{}
+)
+
+// customerEmailRegex only checks the shape of an email address, its domain is not resolved
+var customerEmailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// customerResourceModel maps the resource schema data.
+type customerResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Email       types.String `tfsdk:"email"`
+	LoyaltyTier types.String `tfsdk:"loyalty_tier"`
+	Store       types.String `tfsdk:"store"`
+}
+
+// NewResourceCustomer is a helper function to simplify the provider implementation.
+func NewResourceCustomer() resource.Resource {
+	return &customerResource{}
+}
+
+// customerResource manages the customers placing the orders.
+type customerResource struct {
+	client       client.Client[*model.Customer]
+	orders       client.Client[*model.Order]
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *customerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeCustomer
+}
+
+// Schema defines the schema for the resource.
+func (r *customerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A customer placing orders. It cannot be deleted while it has orders.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"email": schema.StringAttribute{
+				Required: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(customerEmailRegex, "value must be an email address"),
+				},
+			},
+			"loyalty_tier": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(string(model.LoyaltyTierNone)),
+				Description: fmt.Sprintf("One of: %s. Defaults to %s. The higher tiers discount the orders of the customer, "+
+					"from the next time they are placed or updated.", strings.Join(model.LoyaltyTiers(), ", "), model.LoyaltyTierNone),
+				Validators: []validator.String{
+					stringvalidator.OneOf(model.LoyaltyTiers()...),
+				},
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *customerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan customerResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	customer := plan.toModel()
+	customer.ID = uuid.NewString()
+	if _, err := r.client.Create(customer); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating customer",
+			"Could not create customer, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(customer.ID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *customerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state customerResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	customer, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Customer",
+			fmt.Sprintf("Could not read customer ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state = customerFromModel(customer, state.Store)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *customerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan customerResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.Update(plan.toModel()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Customer",
+			fmt.Sprintf("Could not update customer ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the customer, unless it has orders.
+func (r *customerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state customerResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orders, err := customerOrders(r.orders, state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read Orders",
+			err.Error(),
+		)
+		return
+	}
+	if len(orders) > 0 {
+		var ids []string
+		for _, o := range orders {
+			ids = append(ids, o.ID)
+		}
+		resp.Diagnostics.AddError(
+			"Customer has orders",
+			fmt.Sprintf("The customer %s cannot be deleted while it has orders: %s. Delete these orders first.",
+				state.ID.ValueString(), strings.Join(ids, ", ")),
+		)
+		return
+	}
+
+	if err := r.client.Delete(state.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Customer",
+			fmt.Sprintf("Could not delete customer ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+func (r *customerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *customerResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.Customer](c, typeCustomer)
+	r.orders = client.NewClient[*model.Order](c, typeOrder)
+}
+
+// customerOrders returns the orders placed by the customer, the oldest first
+func customerOrders(orders client.Client[*model.Order], customerID string) ([]*model.Order, error) {
+	all, err := orders.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		return nil, err
+	}
+	var res []*model.Order
+	for _, o := range all {
+		if o.CustomerID == customerID {
+			res = append(res, o)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].PlacedAt.Equal(res[j].PlacedAt) {
+			return res[i].PlacedAt.Before(res[j].PlacedAt)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (m customerResourceModel) toModel() *model.Customer {
+	return &model.Customer{
+		ID:          m.ID.ValueString(),
+		Name:        m.Name.ValueString(),
+		Email:       m.Email.ValueString(),
+		LoyaltyTier: model.LoyaltyTier(m.LoyaltyTier.ValueString()),
+	}
+}
+
+func customerFromModel(customer *model.Customer, store types.String) customerResourceModel {
+	return customerResourceModel{
+		ID:          types.StringValue(customer.ID),
+		Name:        types.StringValue(customer.Name),
+		Email:       types.StringValue(customer.Email),
+		LoyaltyTier: types.StringValue(string(customer.LoyaltyTier)),
+		Store:       store,
+	}
+}
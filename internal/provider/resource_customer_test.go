@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccCustomerResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCustomerOrderConfig(storagePath, "none"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("provs_customer.test", "id"),
+					resource.TestCheckResourceAttr("provs_customer.test", "loyalty_tier", "none"),
+					resource.TestCheckResourceAttrPair("provs_order.test", "customer_id", "provs_customer.test", "id"),
+					resource.TestCheckResourceAttr("provs_order.test", "items.0.coffee.name", "Espresso"),
+					resource.TestCheckResourceAttr("provs_order.test", "subtotal", "8"),
+					resource.TestCheckResourceAttr("provs_order.test", "discount", "0"),
+					resource.TestCheckResourceAttr("provs_order.test", "total", "8"),
+				),
+			},
+			{
+				ResourceName:      "provs_customer.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// the new tier applies to the orders placed or updated from now on
+				Config: testAccCustomerOrderConfig(storagePath, "gold"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_customer.test", "loyalty_tier", "gold"),
+					resource.TestCheckResourceAttr("provs_order.test", "total", "8"),
+					resource.TestCheckResourceAttr("provs_order.second", "subtotal", "4"),
+					resource.TestCheckResourceAttr("provs_order.second", "discount", "0.6"),
+					resource.TestCheckResourceAttr("provs_order.second", "total", "3.4"),
+				),
+			},
+			{
+				Config: testAccCustomerOrderConfig(storagePath, "gold") + `
+data "provs_customer_orders" "test" {
+  customer_id = provs_customer.test.id
+  depends_on  = [provs_order.test, provs_order.second]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_customer_orders.test", tfjsonpath.New("loyalty_tier"), knownvalue.StringExact("gold")),
+					statecheck.ExpectKnownValue("data.provs_customer_orders.test", tfjsonpath.New("order_count"), knownvalue.Int64Exact(2)),
+					statecheck.ExpectKnownValue("data.provs_customer_orders.test", tfjsonpath.New("lifetime_spend"), knownvalue.Float64Exact(11.4)),
+				},
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_order" "test" {
+  customer_id = "missing"
+  items = [{
+    coffee = {
+      id = "missing"
+    }
+    quantity = 1
+  }]
+}
+`,
+				ExpectError: regexp.MustCompile(`Unknown customer`),
+			},
+		},
+	})
+}
+
+func TestAccCustomerResource_invalid(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_customer" "test" {
+  name  = "Ada"
+  email = "not an email"
+}
+`,
+				ExpectError: regexp.MustCompile(`value must be an email address`),
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_customer" "test" {
+  name         = "Ada"
+  email        = "ada@example.com"
+  loyalty_tier = "platinum"
+}
+`,
+				ExpectError: regexp.MustCompile(`loyalty_tier`),
+			},
+		},
+	})
+}
+
+// testAccCustomerOrderConfig returns a customer with an order of 2 espressos, and a second order of 1 espresso
+// once the customer is gold
+func testAccCustomerOrderConfig(storagePath string, tier string) string {
+	config := testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_coffee" "espresso" {
+  name  = "Espresso"
+  price = 4
+}
+
+resource "provs_customer" "test" {
+  name         = "Ada"
+  email        = "ada@example.com"
+  loyalty_tier = %q
+}
+
+resource "provs_order" "test" {
+  customer_id = provs_customer.test.id
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = 2
+  }]
+}
+`, tier)
+	if tier == "gold" {
+		config += `
+resource "provs_order" "second" {
+  customer_id = provs_customer.test.id
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = 1
+  }]
+}
+`
+	}
+	return config
+}
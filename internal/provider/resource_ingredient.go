@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &ingredientResource{}
+	_ resource.ResourceWithConfigure   = &ingredientResource{}
+	_ resource.ResourceWithImportState = &ingredientResource{}
+)
+
+// ingredientResourceModel maps the resource schema data.
+type ingredientResourceModel struct {
+	ID    types.String `tfsdk:"id"`
+	Name  types.String `tfsdk:"name"`
+	Unit  types.String `tfsdk:"unit"`
+	Store types.String `tfsdk:"store"`
+}
+
+// NewResourceIngredient is a helper function to simplify the provider implementation.
+func NewResourceIngredient() resource.Resource {
+	return &ingredientResource{}
+}
+
+// catalogMu serializes the checks of the references to the ingredients with the writes they guard: the ingredients
+// are deleted, and the coffees and inventories referencing them written, while holding it, so that no coffee or
+// inventory is left referencing a deleted ingredient.
+var catalogMu sync.Mutex
+
+// ingredientResource manages the ingredients the coffees of the catalog reference.
+type ingredientResource struct {
+	client       client.Client[*model.Ingredient]
+	coffees      client.Client[*model.Coffee]
+	inventories  client.Client[*model.Inventory]
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *ingredientResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeIngredient
+}
+
+// Schema defines the schema for the resource.
+func (r *ingredientResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "An ingredient of the coffees. It cannot be deleted while a coffee is made with it or it has an inventory.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"unit": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: `The unit of the quantities of the ingredient in the coffees, like "g" or "ml".`,
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *ingredientResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan ingredientResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ingredient := &model.Ingredient{
+		ID:   uuid.NewString(),
+		Name: plan.Name.ValueString(),
+		Unit: plan.Unit.ValueString(),
+	}
+	if _, err := r.client.Create(ingredient); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating ingredient",
+			"Could not create ingredient, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(ingredient.ID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *ingredientResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state ingredientResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ingredient, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Ingredient",
+			fmt.Sprintf("Could not read ingredient ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state.Name = types.StringValue(ingredient.Name)
+	state.Unit = types.StringValue(ingredient.Unit)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *ingredientResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan ingredientResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ingredient := &model.Ingredient{
+		ID:   plan.ID.ValueString(),
+		Name: plan.Name.ValueString(),
+		Unit: plan.Unit.ValueString(),
+	}
+	if err := r.client.Update(ingredient); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Ingredient",
+			fmt.Sprintf("Could not update ingredient ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the ingredient, unless a coffee is made with it.
+func (r *ingredientResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state ingredientResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	coffees, err := r.coffees.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Coffees",
+			err.Error(),
+		)
+		return
+	}
+	var usedBy []string
+	for _, coffee := range coffees {
+		if coffee.UsesIngredient(state.ID.ValueString()) {
+			usedBy = append(usedBy, fmt.Sprintf("%q (%s)", coffee.Name, coffee.ID))
+		}
+	}
+	if len(usedBy) > 0 {
+		resp.Diagnostics.AddError(
+			"Ingredient in use",
+			fmt.Sprintf("The ingredient %s cannot be deleted while coffees are made with it: %s. Remove it from these coffees first.",
+				state.ID.ValueString(), strings.Join(usedBy, ", ")),
+		)
+		return
+	}
+	if _, err := r.inventories.GetByID(state.ID.ValueString()); err == nil {
+		resp.Diagnostics.AddError(
+			"Ingredient in use",
+			fmt.Sprintf("The ingredient %s cannot be deleted while it has an inventory. Delete its provs_inventory first.",
+				state.ID.ValueString()),
+		)
+		return
+	} else if !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Reading Inventory",
+			fmt.Sprintf("Could not read inventory ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+
+	if err := r.client.Delete(state.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Ingredient",
+			fmt.Sprintf("Could not delete ingredient ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+func (r *ingredientResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *ingredientResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.Ingredient](c, typeIngredient)
+	r.coffees = client.NewClient[*model.Coffee](c, typeCoffees)
+	r.inventories = client.NewClient[*model.Inventory](c, typeInventory)
+}
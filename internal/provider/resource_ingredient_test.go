@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIngredientResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIngredientResourceConfig(storagePath, "Milk", "ml"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("provs_ingredient.test", "id"),
+					resource.TestCheckResourceAttr("provs_ingredient.test", "name", "Milk"),
+					resource.TestCheckResourceAttr("provs_ingredient.test", "unit", "ml"),
+				),
+			},
+			{
+				ResourceName:      "provs_ingredient.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccIngredientResourceConfig(storagePath, "Oat milk", "cl"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_ingredient.test", "name", "Oat milk"),
+					resource.TestCheckResourceAttr("provs_ingredient.test", "unit", "cl"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIngredientResource_inUse(t *testing.T) {
+	storagePath := t.TempDir()
+	const coffeeID = "latte"
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIngredientResourceConfig(storagePath, "Milk", "ml"),
+			},
+			{
+				// a coffee made with the ingredient, managed outside of this configuration
+				PreConfig: func() {
+					backend, err := filesystem.NewFsClient(storagePath)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ingredients, err := client.NewClient[*model.Ingredient](backend, typeIngredient).GetAll()
+					if err != nil || len(ingredients) != 1 {
+						t.Fatalf("expected one ingredient, got %d (%v)", len(ingredients), err)
+					}
+					if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).Create(&model.Coffee{
+						ID:         coffeeID,
+						Name:       "Latte",
+						Ingredient: []model.CoffeeIngredient{{IngredientID: ingredients[0].ID, Quantity: 200}},
+					}); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      testAccProviderConfig(storagePath),
+				ExpectError: regexp.MustCompile(`Ingredient in use`),
+			},
+			{
+				PreConfig: func() {
+					backend, err := filesystem.NewFsClient(storagePath)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := client.NewClient[*model.Coffee](backend, typeCoffees).Delete(coffeeID); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccProviderConfig(storagePath),
+			},
+		},
+	})
+}
+
+func testAccIngredientResourceConfig(storagePath string, name string, unit string) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_ingredient" "test" {
+  name = %q
+  unit = %q
+}
+`, name, unit)
+}
+
+func TestIngredientResource_deleteWithInventory(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, typeIngredient)
+	inventories := client.NewClient[*model.Inventory](backend, typeInventory)
+	if _, err := ingredients.Create(&model.Ingredient{ID: "milk", Name: "Milk"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inventories.Create(&model.Inventory{ID: "milk", Quantity: 10}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := map[string]tftypes.Value{"id": tftypes.NewValue(tftypes.String, "milk")}
+
+	resp := testApplyDestroy(ctx, t, server, schemas, "provs_ingredient", state)
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Ingredient in use" {
+		t.Fatalf("expected the deletion refused, got %v", resp.Diagnostics)
+	}
+	if _, err := ingredients.GetByID("milk"); err != nil {
+		t.Fatalf("expected the ingredient kept, got %v", err)
+	}
+
+	if err := inventories.Delete("milk"); err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, testApplyDestroy(ctx, t, server, schemas, "provs_ingredient", state).Diagnostics)
+	if _, err := ingredients.GetByID("milk"); !client.IsNotFound(err) {
+		t.Fatalf("expected the ingredient deleted, got %v", err)
+	}
+}
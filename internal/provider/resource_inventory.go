@@ -0,0 +1,304 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &inventoryResource{}
+	_ resource.ResourceWithConfigure   = &inventoryResource{}
+	_ resource.ResourceWithImportState = &inventoryResource{}
+)
+
+// inventoryResourceModel maps the resource schema data.
+type inventoryResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	IngredientID types.String `tfsdk:"ingredient_id"`
+	Quantity     types.Int64  `tfsdk:"quantity"`
+	Reserved     types.Int64  `tfsdk:"reserved"`
+	Available    types.Int64  `tfsdk:"available"`
+	Store        types.String `tfsdk:"store"`
+}
+
+// NewResourceInventory is a helper function to simplify the provider implementation.
+func NewResourceInventory() resource.Resource {
+	return &inventoryResource{}
+}
+
+// inventoryResource manages the stock of an ingredient, reserved by the orders.
+type inventoryResource struct {
+	client       client.Client[*model.Inventory]
+	ingredients  client.Client[*model.Ingredient]
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *inventoryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeInventory
+}
+
+// Schema defines the schema for the resource.
+func (r *inventoryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The stock of an ingredient. The orders reserve the stock of the ingredients of their coffees, " +
+			"and release it once deleted. The ingredients without an inventory are not tracked.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the ingredient.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ingredient_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of a provs_ingredient. An ingredient has at most one inventory.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"quantity": schema.Int64Attribute{
+				Required:    true,
+				Description: "The stock of the ingredient, in the unit of the ingredient. It cannot be lower than the quantity reserved.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"reserved": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The quantity reserved by the orders.",
+			},
+			"available": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The quantity not reserved by any order.",
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *inventoryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan inventoryResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	id := plan.IngredientID.ValueString()
+	_, err := r.ingredients.GetByID(id)
+	if client.IsNotFound(err) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ingredient_id"),
+			"Unknown ingredient",
+			fmt.Sprintf("There is no ingredient with the ID %q. Create it with a provs_ingredient resource first.", id),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Ingredient",
+			fmt.Sprintf("Could not read ingredient ID %s: %s", id, err),
+		)
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	_, err = r.client.GetByID(id)
+	if err == nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ingredient_id"),
+			"Inventory already exists",
+			fmt.Sprintf("The ingredient %s already has an inventory. Import it instead.", id),
+		)
+		return
+	}
+	if !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Reading Inventory",
+			fmt.Sprintf("Could not read inventory ID %s: %s", id, err),
+		)
+		return
+	}
+
+	inventory := &model.Inventory{
+		ID:       id,
+		Quantity: int(plan.Quantity.ValueInt64()),
+	}
+	if _, err := r.client.Create(inventory); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating inventory",
+			"Could not create inventory, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Set state to fully populated data
+	plan = inventoryFromModel(inventory, plan.Store)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *inventoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state inventoryResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	inventory, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Inventory",
+			fmt.Sprintf("Could not read inventory ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state = inventoryFromModel(inventory, state.Store)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update changes the stock, keeping the reservations of the orders.
+func (r *inventoryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan inventoryResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	inventory, err := r.client.GetByID(plan.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Inventory",
+			fmt.Sprintf("Could not read inventory ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+	inventory.Quantity = int(plan.Quantity.ValueInt64())
+	if inventory.Available() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("quantity"),
+			"Insufficient stock",
+			fmt.Sprintf("The quantity %d is lower than the %d reserved by the orders. Delete or change these orders first.",
+				inventory.Quantity, inventory.Reserved()),
+		)
+		return
+	}
+	if err := r.client.Update(inventory); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Inventory",
+			fmt.Sprintf("Could not update inventory ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	plan = inventoryFromModel(inventory, plan.Store)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+// The ingredient is not tracked anymore, the orders can use as much of it as they need.
+func (r *inventoryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state inventoryResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	if err := r.client.Delete(state.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Inventory",
+			fmt.Sprintf("Could not delete inventory ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the inventory of the ingredient with the given ID.
+func (r *inventoryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *inventoryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.Inventory](c, typeInventory)
+	r.ingredients = client.NewClient[*model.Ingredient](c, typeIngredient)
+}
+
+func inventoryFromModel(inventory *model.Inventory, store types.String) inventoryResourceModel {
+	return inventoryResourceModel{
+		ID:           types.StringValue(inventory.ID),
+		IngredientID: types.StringValue(inventory.ID),
+		Quantity:     types.Int64Value(int64(inventory.Quantity)),
+		Reserved:     types.Int64Value(int64(inventory.Reserved())),
+		Available:    types.Int64Value(int64(inventory.Available())),
+		Store:        store,
+	}
+}
@@ -3,17 +3,22 @@ package provider
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"terraform-provider-provs/internal/client"
 	"terraform-provider-provs/internal/model"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -22,13 +27,39 @@ var (
 	_ resource.Resource                = &orderResource{}
 	_ resource.ResourceWithConfigure   = &orderResource{}
 	_ resource.ResourceWithImportState = &orderResource{}
+	_ resource.ResourceWithModifyPlan  = &orderResource{}
 )
 
+// ordersMu serializes the changes of the orders and of what they hold: the stock reserved from the inventories
+// and the redemptions of the promotions. It is held from the reading of these objects to the commit of the
+// transaction changing them, so that concurrent orders cannot reserve the same stock or redemption.
+var ordersMu sync.Mutex
+
 // orderResourceModel maps the resource schema data.
 type orderResourceModel struct {
-	ID          types.String     `tfsdk:"id"`
-	Items       []orderItemModel `tfsdk:"items"`
-	LastUpdated types.String     `tfsdk:"last_updated"`
+	ID                types.String     `tfsdk:"id"`
+	Items             []orderItemModel `tfsdk:"items"`
+	CustomerID        types.String     `tfsdk:"customer_id"`
+	PromoCode         types.String     `tfsdk:"promo_code"`
+	Region            types.String     `tfsdk:"region"`
+	Subtotal          types.Float64    `tfsdk:"subtotal"`
+	PromotionDiscount types.Float64    `tfsdk:"promotion_discount"`
+	Discount          types.Float64    `tfsdk:"discount"`
+	Tax               types.Float64    `tfsdk:"tax"`
+	TaxBreakdown      []orderTaxModel  `tfsdk:"tax_breakdown"`
+	Total             types.Float64    `tfsdk:"total"`
+	LastUpdated       types.String     `tfsdk:"last_updated"`
+	Store             types.String     `tfsdk:"store"`
+}
+
+// orderTaxModel maps the tax of an order item.
+type orderTaxModel struct {
+	CoffeeID types.String  `tfsdk:"coffee_id"`
+	Category types.String  `tfsdk:"category"`
+	Amount   types.Float64 `tfsdk:"amount"`
+	Exempt   types.Bool    `tfsdk:"exempt"`
+	Rate     types.Float64 `tfsdk:"rate"`
+	Tax      types.Float64 `tfsdk:"tax"`
 }
 
 // orderItemModel maps order item data.
@@ -47,14 +78,28 @@ type orderItemCoffeeModel struct {
 	Image       types.String  `tfsdk:"image"`
 }
 
-// NewResourceOrder is a helper function to simplify the provider implementation.
-func NewResourceOrder() resource.Resource {
-	return &orderResource{}
+// newResourceOrder returns the resource placing the orders, and checking the validity of their promotions, with the
+// given clock.
+func newResourceOrder(now func() time.Time) resource.Resource {
+	return &orderResource{
+		now: now,
+	}
 }
 
 // orderResource is the resource implementation.
+// The orders reserve the stock of the ingredients of their coffees, see provs_inventory, and redeem their promotion,
+// see provs_promotion.
 type orderResource struct {
-	client client.Client[*model.Order]
+	backend      client.BackendClient
+	client       client.Client[*model.Order]
+	coffees      client.Client[*model.Coffee]
+	customers    client.Client[*model.Customer]
+	inventories  client.Client[*model.Inventory]
+	promotions   client.Client[*model.Promotion]
+	promoCodes   client.NameIndex
+	taxRules     client.Client[*model.TaxRule]
+	now          func() time.Time
+	providerData any
 }
 
 // Metadata returns the resource type name.
@@ -65,6 +110,7 @@ func (r *orderResource) Metadata(_ context.Context, req resource.MetadataRequest
 // Schema defines the schema for the resource.
 func (r *orderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Description: "An order of coffees of the catalog. The coffees are ordered at their price when the order is placed or updated.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -75,12 +121,78 @@ func (r *orderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 			"last_updated": schema.StringAttribute{
 				Computed: true,
 			},
+			"customer_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "The ID of the provs_customer placing the order. The order is discounted according to the loyalty tier of the customer.",
+			},
+			"promo_code": schema.StringAttribute{
+				Optional: true,
+				Description: "The code of a provs_promotion discounting the order. The promotion must be active when the order " +
+					"is placed, and apply to at least one of its coffees.",
+			},
+			"subtotal": schema.Float64Attribute{
+				Computed:    true,
+				Description: "The price of the items.",
+			},
+			"promotion_discount": schema.Float64Attribute{
+				Computed:    true,
+				Description: "The discount granted by the promotion, on the items it applies to.",
+			},
+			"discount": schema.Float64Attribute{
+				Computed: true,
+				Description: "The discount granted by the promotion, then by the loyalty tier of the customer " +
+					"on what remains.",
+			},
+			"region": schema.StringAttribute{
+				Optional:    true,
+				Description: "The region of the provs_tax_rule taxing the order. The order is not taxed when not set.",
+			},
+			"tax": schema.Float64Attribute{
+				Computed:    true,
+				Description: "The tax of the order, the sum of the taxes of the tax_breakdown.",
+			},
+			"tax_breakdown": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The tax of each item, in the order of the items. Empty when the order has no region.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"coffee_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"category": schema.StringAttribute{
+							Computed: true,
+						},
+						"amount": schema.Float64Attribute{
+							Computed: true,
+							Description: "The price of the item once discounted, the discounts being spread over the items " +
+								"they apply to in proportion to their price.",
+						},
+						"exempt": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the category of the coffee is exempt from the tax.",
+						},
+						"rate": schema.Float64Attribute{
+							Computed:    true,
+							Description: "The rate applied, 0 when exempt.",
+						},
+						"tax": schema.Float64Attribute{
+							Computed:    true,
+							Description: "The tax of the item, included in the amount when the tax rule is inclusive.",
+						},
+					},
+				},
+			},
+			"total": schema.Float64Attribute{
+				Computed:    true,
+				Description: "The subtotal minus the discount, plus the tax unless the prices include it.",
+			},
 			"items": schema.ListNestedAttribute{
 				Required: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"quantity": schema.Int64Attribute{
-							Required: true,
+							Required:   true,
+							Validators: []validator.Int64{int64validator.AtLeast(1)},
 						},
 						"coffee": schema.SingleNestedAttribute{
 							Required: true,
@@ -108,6 +220,7 @@ func (r *orderResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 					},
 				},
 			},
+			"store": resourceStoreSchema(),
 		},
 	}
 }
@@ -118,27 +231,35 @@ func (r *orderResource) Create(ctx context.Context, req resource.CreateRequest,
 	var plan orderResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Generate API request body from plan
-	var items []model.OrderItem
-	for _, item := range plan.Items {
-		items = append(items, model.OrderItem{
-			Coffee: model.Coffee{
-				ID: item.Coffee.ID.ValueString(),
-			},
-			Quantity: int(item.Quantity.ValueInt64()),
-		})
+	// Create new order, reserving its stock
+	o := plan.toModel()
+	o.ID = uuid.NewString()
+	o.PlacedAt = r.now()
+	resp.Diagnostics.Append(r.resolve(o)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	// Create new order
-	o := &model.Order{
-		ID:    uuid.NewString(),
-		Items: items,
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	tx := client.NewTx(r.backend)
+	resp.Diagnostics.Append(r.reserveStock(tx, o.ID, o.Items)...)
+	resp.Diagnostics.Append(r.redeemPromotion(tx, o.ID, "", o.PromoCode)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if _, err := client.NewClient[*model.Order](tx, typeOrder).Create(o); err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating order",
+			"Could not create order, unexpected error: "+err.Error(),
+		)
+		return
 	}
-	if _, err := r.client.Create(o); err != nil {
+	if err := tx.Commit(); err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating order",
 			"Could not create order, unexpected error: "+err.Error(),
@@ -147,20 +268,7 @@ func (r *orderResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	plan.ID = types.StringValue(o.ID)
-	for orderItemIndex, orderItem := range o.Items {
-		plan.Items[orderItemIndex] = orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.StringValue(orderItem.Coffee.ID),
-				Name:        types.StringValue(orderItem.Coffee.Name),
-				Teaser:      types.StringValue(orderItem.Coffee.Teaser),
-				Description: types.StringValue(orderItem.Coffee.Description),
-				Price:       types.Float64Value(orderItem.Coffee.Price),
-				Image:       types.StringValue(orderItem.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(orderItem.Quantity)),
-		}
-	}
+	plan = orderFromModel(o, plan.Store)
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	// Set state to fully populated data
@@ -177,6 +285,7 @@ func (r *orderResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	var state orderResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -191,21 +300,10 @@ func (r *orderResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	// Overwrite items with refreshed state
-	state.Items = []orderItemModel{}
-	for _, item := range order.Items {
-		state.Items = append(state.Items, orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.StringValue(item.Coffee.ID),
-				Name:        types.StringValue(item.Coffee.Name),
-				Teaser:      types.StringValue(item.Coffee.Teaser),
-				Description: types.StringValue(item.Coffee.Description),
-				Price:       types.Float64Value(item.Coffee.Price),
-				Image:       types.StringValue(item.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(item.Quantity)),
-		})
-	}
+	// Overwrite the order with refreshed state
+	lastUpdated := state.LastUpdated
+	state = orderFromModel(order, state.Store)
+	state.LastUpdated = lastUpdated
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -220,60 +318,52 @@ func (r *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	var plan orderResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Generate API request body from plan
-	var items []model.OrderItem
-	for _, item := range plan.Items {
-		items = append(items, model.OrderItem{
-			Coffee: model.Coffee{
-				ID: item.Coffee.ID.ValueString(),
-			},
-			Quantity: int(item.Quantity.ValueInt64()),
-		})
+	o := plan.toModel()
+	resp.Diagnostics.Append(r.resolve(o)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	o := &model.Order{
-		ID:    plan.ID.ValueString(),
-		Items: items,
+	prior, err := r.client.GetByID(o.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Order",
+			"Could not read order ID "+o.ID+": "+err.Error(),
+		)
+		return
 	}
-	// Update existing order
-	if err := r.client.Update(o); err != nil {
+	o.PlacedAt = prior.PlacedAt
+
+	// Update existing order, adjusting its stock and its promotion
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	tx := client.NewTx(r.backend)
+	resp.Diagnostics.Append(r.reserveStock(tx, o.ID, o.Items)...)
+	resp.Diagnostics.Append(r.redeemPromotion(tx, o.ID, prior.PromoCode, o.PromoCode)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := client.NewClient[*model.Order](tx, typeOrder).Update(o); err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating Order",
 			"Could not update order, unexpected error: "+err.Error(),
 		)
 		return
 	}
-
-	// Fetch updated items from GetOrder as UpdateOrder items are not
-	// populated.
-	order, err := r.client.GetByID(plan.ID.ValueString())
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		resp.Diagnostics.AddError(
-			"Error Reading Order",
-			"Could not read order ID "+plan.ID.ValueString()+": "+err.Error(),
+			"Error Updating Order",
+			"Could not update order, unexpected error: "+err.Error(),
 		)
 		return
 	}
 
 	// Update resource state with updated items and timestamp
-	plan.Items = []orderItemModel{}
-	for _, item := range order.Items {
-		plan.Items = append(plan.Items, orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.StringValue(item.Coffee.ID),
-				Name:        types.StringValue(item.Coffee.Name),
-				Teaser:      types.StringValue(item.Coffee.Teaser),
-				Description: types.StringValue(item.Coffee.Description),
-				Price:       types.Float64Value(item.Coffee.Price),
-				Image:       types.StringValue(item.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(item.Quantity)),
-		})
-	}
+	plan = orderFromModel(o, plan.Store)
 	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	diags = resp.State.Set(ctx, plan)
@@ -289,23 +379,145 @@ func (r *orderResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	var state orderResourceModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if err := r.client.Delete(state.ID.ValueString()); err != nil {
+	// Delete the order, releasing its stock and its promotion
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	tx := client.NewTx(r.backend)
+	resp.Diagnostics.Append(r.reserveStock(tx, state.ID.ValueString(), nil)...)
+	resp.Diagnostics.Append(r.redeemPromotion(tx, state.ID.ValueString(), state.PromoCode.ValueString(), "")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := client.NewClient[*model.Order](tx, typeOrder).Delete(state.ID.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting Order",
 			"Could not delete order, unexpected error: "+err.Error(),
 		)
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Order",
+			"Could not delete order, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// ModifyPlan checks that the coffees, the customer, the promotion and the tax rule of the order exist, that the
+// promotion can be used, and fails the plan when there is not enough stock for the order. All of it is checked again
+// on apply.
+func (r *orderResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing is reserved on destroy, and nothing can be checked before the provider is configured
+	if req.Plan.Raw.IsNull() || r.backend == nil {
+		return
+	}
+
+	var id, customerID, promoCode, region, store types.String
+	var items types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("store"), &store)...)
+	if resp.Diagnostics.HasError() || store.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, store)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("id"), &id)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("customer_id"), &customerID)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("promo_code"), &promoCode)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("region"), &region)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("items"), &items)...)
+	if resp.Diagnostics.HasError() || items.IsUnknown() {
+		return
+	}
+	for _, item := range items.Elements() {
+		if item.IsUnknown() {
+			return
+		}
+	}
+	var planItems []orderItemModel
+	resp.Diagnostics.Append(items.ElementsAs(ctx, &planItems, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// an unchanged order is not checked again: the coffees, customer or promotion deleted since it was placed must not
+	// fail the plans of everything else in the configuration
+	if !req.State.Raw.IsNull() {
+		var state orderResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.Store.Equal(store) && state.CustomerID.Equal(customerID) && state.PromoCode.Equal(promoCode) &&
+			state.Region.Equal(region) && sameOrderItems(state.Items, planItems) {
+			return
+		}
+	}
+
+	// the ID is unknown on create, when the order reserves nothing yet
+	o := &model.Order{
+		ID: id.ValueString(),
+	}
+	if !customerID.IsUnknown() {
+		o.CustomerID = customerID.ValueString()
+	}
+	if !promoCode.IsUnknown() {
+		o.PromoCode = promoCode.ValueString()
+	}
+	if !region.IsUnknown() {
+		o.Region = region.ValueString()
+	}
+	for _, item := range planItems {
+		// checked on apply, once known
+		if item.Coffee.ID.IsUnknown() || item.Quantity.IsUnknown() {
+			return
+		}
+		o.Items = append(o.Items, model.OrderItem{
+			Coffee:   model.Coffee{ID: item.Coffee.ID.ValueString()},
+			Quantity: int(item.Quantity.ValueInt64()),
+		})
+	}
+	resp.Diagnostics.Append(r.resolve(o)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	inventories, err := r.inventories.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Inventories",
+			err.Error(),
+		)
+		return
+	}
+	if _, shortages := planStock(inventories, o.ID, orderNeeds(o.Items)); len(shortages) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("items"),
+			"Insufficient stock",
+			insufficientStockDetail(shortages),
+		)
+	}
+}
 
+// sameOrderItems reports whether the items order the same quantities of the same coffees, in the same order
+func sameOrderItems(a, b []orderItemModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Coffee.ID.Equal(b[i].Coffee.ID) || !a[i].Quantity.Equal(b[i].Quantity) {
+			return false
+		}
+	}
+	return true
 }
 
 func (r *orderResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
 }
 
 // Configure adds the provider configured client to the resource.
@@ -327,5 +539,289 @@ func (r *orderResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
+	r.providerData = req.ProviderData
+	r.backend = c
 	r.client = client.NewClient[*model.Order](c, typeOrder)
+	r.coffees = client.NewClient[*model.Coffee](c, typeCoffees)
+	r.customers = client.NewClient[*model.Customer](c, typeCustomer)
+	r.inventories = client.NewClient[*model.Inventory](c, typeInventory)
+	r.promotions = client.NewClient[*model.Promotion](c, typePromotion)
+	r.promoCodes = newPromotionCodeIndex(c)
+	r.taxRules = client.NewClient[*model.TaxRule](c, typeTaxRule)
+}
+
+// resolve fills the items of the order with the coffees of the catalog and the loyalty tier of the customer,
+// then computes the total of the order with its promotion and its tax rule. An error is returned for every coffee,
+// customer, promotion or tax rule that does not exist, and for a promotion the order cannot use.
+func (r *orderResource) resolve(o *model.Order) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, item := range o.Items {
+		coffee, err := r.coffees.GetByID(item.Coffee.ID)
+		if client.IsNotFound(err) {
+			diags.AddAttributeError(
+				path.Root("items").AtListIndex(i).AtName("coffee").AtName("id"),
+				"Unknown coffee",
+				fmt.Sprintf("There is no coffee with the ID %q in the catalog.", item.Coffee.ID),
+			)
+			continue
+		}
+		if err != nil {
+			diags.AddError(
+				"Error Reading Coffee",
+				fmt.Sprintf("Could not read coffee ID %s: %s", item.Coffee.ID, err),
+			)
+			continue
+		}
+		o.Items[i].Coffee = *coffee
+	}
+
+	o.LoyaltyTier = model.LoyaltyTierNone
+	if o.CustomerID != "" {
+		customer, err := r.customers.GetByID(o.CustomerID)
+		if client.IsNotFound(err) {
+			diags.AddAttributeError(
+				path.Root("customer_id"),
+				"Unknown customer",
+				fmt.Sprintf("There is no customer with the ID %q. Create it with a provs_customer resource first.", o.CustomerID),
+			)
+		} else if err != nil {
+			diags.AddError(
+				"Error Reading Customer",
+				fmt.Sprintf("Could not read customer ID %s: %s", o.CustomerID, err),
+			)
+		} else {
+			o.LoyaltyTier = customer.LoyaltyTier
+		}
+	}
+
+	var taxRule *model.TaxRule
+	if o.Region != "" {
+		var err error
+		taxRule, err = r.taxRules.GetByID(o.Region)
+		if client.IsNotFound(err) {
+			diags.AddAttributeError(
+				path.Root("region"),
+				"Unknown region",
+				fmt.Sprintf("There is no tax rule for the region %q. Create it with a provs_tax_rule resource first.", o.Region),
+			)
+		} else if err != nil {
+			diags.AddError(
+				"Error Reading Tax Rule",
+				fmt.Sprintf("Could not read tax rule ID %s: %s", o.Region, err),
+			)
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	var promotion *model.Promotion
+	if o.PromoCode != "" {
+		var promotionDiags diag.Diagnostics
+		promotion, promotionDiags = r.resolvePromotion(o)
+		diags.Append(promotionDiags...)
+	}
+	o.ComputeTotal(promotion, taxRule)
+	return diags
+}
+
+// resolvePromotion returns the promotion of the order, once checked the order can use it. The validity window and
+// the usage limit are only checked when the order does not use the promotion yet: the orders keep their promotion
+// once placed.
+func (r *orderResource) resolvePromotion(o *model.Order) (*model.Promotion, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	promotion, err := lookupPromotionByCode(r.promotions, r.promoCodes, o.PromoCode)
+	if client.IsNotFound(err) {
+		diags.AddAttributeError(
+			path.Root("promo_code"),
+			"Unknown promo code",
+			fmt.Sprintf("There is no promotion with the code %q.", o.PromoCode),
+		)
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(
+			"Error Reading Promotion",
+			fmt.Sprintf("Could not read the promotion with the code %q: %s", o.PromoCode, err),
+		)
+		return nil, diags
+	}
+	if !promotion.RedeemedBy(o.ID) {
+		if now := r.now(); !promotion.ActiveAt(now) {
+			diags.AddAttributeError(
+				path.Root("promo_code"),
+				"Promotion not active",
+				fmt.Sprintf("The promotion %s is not active at %s.", o.PromoCode, now.UTC().Format(time.RFC3339)),
+			)
+		}
+		if promotion.Exhausted() {
+			diags.AddAttributeError(
+				path.Root("promo_code"),
+				"Promotion exhausted",
+				fmt.Sprintf("The promotion %s was already used by %d orders, its maximum.", o.PromoCode, promotion.MaxRedemptions),
+			)
+		}
+	}
+	if !slices.ContainsFunc(o.Items, func(item model.OrderItem) bool { return promotion.Applies(&item.Coffee) }) {
+		diags.AddAttributeError(
+			path.Root("promo_code"),
+			"Promotion does not apply",
+			fmt.Sprintf("The promotion %s does not apply to any coffee of the order.", o.PromoCode),
+		)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+	return promotion, diags
+}
+
+// redeemPromotion adds the change of the promotion used by the order, from the prior code to the new one, to the
+// transaction. A code is empty when the order uses no promotion.
+func (r *orderResource) redeemPromotion(tx *client.Tx, orderID string, priorCode string, code string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	promotions := client.NewClient[*model.Promotion](tx, typePromotion)
+	if priorCode != "" && priorCode != code {
+		promotion, err := lookupPromotionByCode(promotions, r.promoCodes, priorCode)
+		if err != nil && !client.IsNotFound(err) {
+			diags.AddError(
+				"Unable to Release Promotion",
+				fmt.Sprintf("Could not read the promotion with the code %q: %s", priorCode, err),
+			)
+			return diags
+		}
+		if err == nil {
+			promotion.Redemptions = slices.DeleteFunc(promotion.Redemptions, func(id string) bool { return id == orderID })
+			if err := promotions.Update(promotion); err != nil {
+				diags.AddError(
+					"Unable to Release Promotion",
+					fmt.Sprintf("Could not update the promotion with the code %q: %s", priorCode, err),
+				)
+				return diags
+			}
+		}
+	}
+	if code == "" {
+		return diags
+	}
+
+	// read again, another order may have redeemed the promotion since it was resolved
+	promotion, err := lookupPromotionByCode(promotions, r.promoCodes, code)
+	if err != nil {
+		diags.AddError(
+			"Unable to Redeem Promotion",
+			fmt.Sprintf("Could not read the promotion with the code %q: %s", code, err),
+		)
+		return diags
+	}
+	if promotion.RedeemedBy(orderID) {
+		return diags
+	}
+	if promotion.Exhausted() {
+		diags.AddAttributeError(
+			path.Root("promo_code"),
+			"Promotion exhausted",
+			fmt.Sprintf("The promotion %s was already used by %d orders, its maximum.", code, promotion.MaxRedemptions),
+		)
+		return diags
+	}
+	promotion.Redemptions = append(promotion.Redemptions, orderID)
+	if err := promotions.Update(promotion); err != nil {
+		diags.AddError(
+			"Unable to Redeem Promotion",
+			fmt.Sprintf("Could not update the promotion with the code %q: %s", code, err),
+		)
+	}
+	return diags
+}
+
+// reserveStock adds the reservation of the stock needed by the items to the transaction.
+// The stock reserved by the order is released when there are no items.
+func (r *orderResource) reserveStock(tx *client.Tx, orderID string, items []model.OrderItem) diag.Diagnostics {
+	var diags diag.Diagnostics
+	shortages, err := reserveStock(tx, orderID, orderNeeds(items))
+	if err != nil {
+		diags.AddError(
+			"Unable to Reserve Stock",
+			fmt.Sprintf("Could not update the inventories for order ID %s: %s", orderID, err),
+		)
+		return diags
+	}
+	if len(shortages) > 0 {
+		diags.AddAttributeError(
+			path.Root("items"),
+			"Insufficient stock",
+			insufficientStockDetail(shortages),
+		)
+	}
+	return diags
+}
+
+func (m orderResourceModel) toModel() *model.Order {
+	res := &model.Order{
+		ID:         m.ID.ValueString(),
+		CustomerID: m.CustomerID.ValueString(),
+		PromoCode:  m.PromoCode.ValueString(),
+		Region:     m.Region.ValueString(),
+	}
+	for _, item := range m.Items {
+		res.Items = append(res.Items, model.OrderItem{
+			Coffee: model.Coffee{
+				ID: item.Coffee.ID.ValueString(),
+			},
+			Quantity: int(item.Quantity.ValueInt64()),
+		})
+	}
+	return res
+}
+
+// orderFromModel maps the order kept in the given store to the resource model, leaving last_updated null
+func orderFromModel(order *model.Order, store types.String) orderResourceModel {
+	res := orderResourceModel{
+		ID:                types.StringValue(order.ID),
+		Items:             []orderItemModel{},
+		CustomerID:        types.StringNull(),
+		PromoCode:         types.StringNull(),
+		Region:            types.StringNull(),
+		Subtotal:          types.Float64Value(order.Subtotal),
+		PromotionDiscount: types.Float64Value(order.PromotionDiscount),
+		Discount:          types.Float64Value(order.Discount),
+		Tax:               types.Float64Value(order.Tax),
+		TaxBreakdown:      []orderTaxModel{},
+		Total:             types.Float64Value(order.Total),
+		LastUpdated:       types.StringNull(),
+		Store:             store,
+	}
+	if order.CustomerID != "" {
+		res.CustomerID = types.StringValue(order.CustomerID)
+	}
+	if order.PromoCode != "" {
+		res.PromoCode = types.StringValue(order.PromoCode)
+	}
+	if order.Region != "" {
+		res.Region = types.StringValue(order.Region)
+	}
+	for _, line := range order.TaxLines {
+		res.TaxBreakdown = append(res.TaxBreakdown, orderTaxModel{
+			CoffeeID: types.StringValue(line.CoffeeID),
+			Category: types.StringValue(line.Category),
+			Amount:   types.Float64Value(line.Amount),
+			Exempt:   types.BoolValue(line.Exempt),
+			Rate:     types.Float64Value(line.Rate),
+			Tax:      types.Float64Value(line.Tax),
+		})
+	}
+	for _, item := range order.Items {
+		res.Items = append(res.Items, orderItemModel{
+			Coffee: orderItemCoffeeModel{
+				ID:          types.StringValue(item.Coffee.ID),
+				Name:        types.StringValue(item.Coffee.Name),
+				Teaser:      types.StringValue(item.Coffee.Teaser),
+				Description: types.StringValue(item.Coffee.Description),
+				Price:       types.Float64Value(item.Coffee.Price),
+				Image:       types.StringValue(item.Coffee.Image),
+			},
+			Quantity: types.Int64Value(int64(item.Quantity)),
+		})
+	}
+	return res
 }
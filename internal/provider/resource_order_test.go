@@ -0,0 +1,381 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccOrderResource_stock(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrderStockConfig(storagePath, 20, 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_inventory.beans", "quantity", "20"),
+					resource.TestCheckResourceAttr("provs_inventory.beans", "reserved", "0"),
+				),
+			},
+			{
+				// the reservations of the order show once refreshed
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_inventory.beans", "reserved", "14"),
+					resource.TestCheckResourceAttr("provs_inventory.beans", "available", "6"),
+				),
+			},
+			{
+				Config:      testAccOrderStockConfig(storagePath, 20, 3),
+				ExpectError: regexp.MustCompile(`Insufficient stock`),
+			},
+			{
+				Config: testAccOrderStockConfig(storagePath, 20, 1) + `
+data "provs_stock" "current" {
+  depends_on = [provs_order.test]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.provs_stock.current", tfjsonpath.New("levels"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"name":      knownvalue.StringExact("Coffee beans"),
+							"quantity":  knownvalue.Int64Exact(20),
+							"reserved":  knownvalue.Int64Exact(7),
+							"available": knownvalue.Int64Exact(13),
+						}),
+					})),
+				},
+			},
+			{
+				Config:      testAccOrderStockConfig(storagePath, 5, 1),
+				ExpectError: regexp.MustCompile(`lower than the 7 reserved by the orders`),
+			},
+		},
+	})
+}
+
+func TestOrderResource_planInsufficientStock(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).Create(&model.Coffee{
+		ID:         "espresso",
+		Name:       "Espresso",
+		Ingredient: []model.CoffeeIngredient{{IngredientID: "beans", Quantity: 7}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	inventories := client.NewClient[*model.Inventory](backend, typeInventory)
+	if _, err := inventories.Create(&model.Inventory{
+		ID:           "beans",
+		Quantity:     20,
+		Reservations: map[string]int{"other": 7},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+
+	testNoDiagnostics(t, testPlanOrder(ctx, t, server, schemas, "espresso", 1, nil).Diagnostics)
+
+	resp := testPlanOrder(ctx, t, server, schemas, "espresso", 2, nil)
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Insufficient stock" {
+		t.Fatalf("expected an insufficient stock error, got %v", resp.Diagnostics)
+	}
+	if !strings.Contains(resp.Diagnostics[0].Detail, "the ingredient beans is needed 14 times, 13 available") {
+		t.Fatalf("unexpected detail: %s", resp.Diagnostics[0].Detail)
+	}
+
+	// planning reserves nothing
+	inv, err := inventories.GetByID("beans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Reserved() != 7 {
+		t.Fatalf("expected the reservations unchanged, got %v", inv.Reservations)
+	}
+}
+
+func TestOrderResource_invalidQuantity(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).Create(&model.Coffee{
+		ID:         "espresso",
+		Name:       "Espresso",
+		Ingredient: []model.CoffeeIngredient{{IngredientID: "beans", Quantity: 7}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	inventories := client.NewClient[*model.Inventory](backend, typeInventory)
+	if _, err := inventories.Create(&model.Inventory{
+		ID:           "beans",
+		Quantity:     20,
+		Reservations: map[string]int{"other": 7},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	schema := schemas.ResourceSchemas["provs_order"]
+
+	for _, quantity := range []int{0, -1} {
+		resp, err := server.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{
+			TypeName: "provs_order",
+			Config: testDynamicValue(t, schema, map[string]tftypes.Value{
+				"items": testOrderItems(schema, "espresso", quantity),
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Invalid Attribute Value" {
+			t.Fatalf("expected the quantity %d rejected, got %v", quantity, resp.Diagnostics)
+		}
+	}
+
+	inv, err := inventories.GetByID("beans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Reserved() != 7 {
+		t.Fatalf("expected the reservations unchanged, got %v", inv.Reservations)
+	}
+}
+
+func TestOrderResource_planUnknownReferences(t *testing.T) {
+	ctx := context.Background()
+	server, schemas := testProtocolServer(ctx, t, t.TempDir(), time.Now)
+
+	resp := testPlanOrder(ctx, t, server, schemas, "missing", 1, map[string]string{
+		"customer_id": "nobody",
+		"region":      "nowhere",
+	})
+	var summaries []string
+	for _, d := range resp.Diagnostics {
+		summaries = append(summaries, d.Summary)
+	}
+	if got := strings.Join(summaries, ", "); got != "Unknown coffee, Unknown customer, Unknown region" {
+		t.Fatalf("expected the coffee, the customer and the region reported, got %s", got)
+	}
+}
+
+func TestOrderResource_planPromotion(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](backend, typeCoffees).Create(&model.Coffee{
+		ID:       "espresso",
+		Name:     "Espresso",
+		Category: "espresso",
+		Price:    4,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+	promotions := client.NewClient[*model.Promotion](backend, typePromotion)
+	codes := newPromotionCodeIndex(backend)
+	for _, p := range []*model.Promotion{
+		{ID: "1", Code: "WELCOME", StartsAt: &before, EndsAt: &after},
+		{ID: "2", Code: "LATER", StartsAt: &after},
+		{ID: "3", Code: "ENDED", EndsAt: &before},
+		{ID: "4", Code: "ONCE", MaxRedemptions: 1, Redemptions: []string{"other"}},
+		{ID: "5", Code: "TEA", Categories: []string{"tea"}},
+	} {
+		p.Kind, p.Value = model.DiscountKindPercentage, 10
+		if _, err := promotions.Create(p); err != nil {
+			t.Fatal(err)
+		}
+		if err := codes.Reserve(p.Code, p.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, func() time.Time { return now })
+
+	testNoDiagnostics(t, testPlanOrder(ctx, t, server, schemas, "espresso", 1, map[string]string{"promo_code": "WELCOME"}).Diagnostics)
+	for code, want := range map[string]string{
+		"MISSING": "Unknown promo code",
+		"LATER":   "Promotion not active",
+		"ENDED":   "Promotion not active",
+		"ONCE":    "Promotion exhausted",
+		"TEA":     "Promotion does not apply",
+	} {
+		resp := testPlanOrder(ctx, t, server, schemas, "espresso", 1, map[string]string{"promo_code": code})
+		if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != want {
+			t.Errorf("%s: expected %q, got %v", code, want, resp.Diagnostics)
+		}
+	}
+}
+
+func TestOrderResource_planDeletedCoffee(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the coffee of the order was deleted from the store, out of the provider
+	if _, err := client.NewClient[*model.Order](backend, typeOrder).Create(&model.Order{
+		ID:    "1",
+		Items: []model.OrderItem{{Coffee: model.Coffee{ID: "espresso"}, Quantity: 1}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	prior := map[string]tftypes.Value{
+		"id":    tftypes.NewValue(tftypes.String, "1"),
+		"items": testOrderItems(schemas.ResourceSchemas["provs_order"], "espresso", 1),
+	}
+
+	// the unchanged order plans, without its references being checked again
+	testNoDiagnostics(t, testPlanOrderChange(ctx, t, server, schemas, prior, "espresso", 1, nil).Diagnostics)
+
+	resp := testPlanOrderChange(ctx, t, server, schemas, prior, "espresso", 2, nil)
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary != "Unknown coffee" {
+		t.Fatalf("expected the changed order checked, got %v", resp.Diagnostics)
+	}
+}
+
+func TestPlanStock(t *testing.T) {
+	inventories := func() []*model.Inventory {
+		return []*model.Inventory{
+			{ID: "beans", Quantity: 20, Reservations: map[string]int{"a": 7}},
+			{ID: "milk", Quantity: 100, Reservations: map[string]int{"a": 100}},
+		}
+	}
+
+	changed, shortages := planStock(inventories(), "b", map[string]int{"beans": 13, "sugar": 50})
+	if len(shortages) != 0 || len(changed) != 1 || changed[0].Reservations["b"] != 13 {
+		t.Fatalf("expected the beans reserved, sugar not being tracked, got %v %v", changed, shortages)
+	}
+
+	_, shortages = planStock(inventories(), "b", map[string]int{"beans": 14, "milk": 1})
+	want := []stockShortage{{IngredientID: "beans", Needed: 14, Available: 13}, {IngredientID: "milk", Needed: 1, Available: 0}}
+	if fmt.Sprint(shortages) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, shortages)
+	}
+
+	// an order can keep what it reserved
+	changed, shortages = planStock(inventories(), "a", map[string]int{"beans": 20, "milk": 100})
+	if len(shortages) != 0 || len(changed) != 1 || changed[0].Available() != 0 {
+		t.Fatalf("expected the beans of the order raised, got %v %v", changed, shortages)
+	}
+
+	changed, shortages = planStock(inventories(), "a", nil)
+	if len(shortages) != 0 || len(changed) != 2 || changed[0].Reserved() != 0 || changed[1].Reserved() != 0 {
+		t.Fatalf("expected the reservations released, got %v %v", changed, shortages)
+	}
+}
+
+// testPlanOrder plans the creation of an order of the coffee, with the given customer_id, promo_code or region
+func testPlanOrder(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, coffeeID string, quantity int, optional map[string]string) *tfprotov6.PlanResourceChangeResponse {
+	t.Helper()
+	return testPlanOrderChange(ctx, t, server, schemas, nil, coffeeID, quantity, optional)
+}
+
+// testPlanOrderChange plans the change of the order of the given prior state, nil on create, to an order of the coffee
+func testPlanOrderChange(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, prior map[string]tftypes.Value, coffeeID string, quantity int, optional map[string]string) *tfprotov6.PlanResourceChangeResponse {
+	t.Helper()
+	schema := schemas.ResourceSchemas["provs_order"]
+	attrs := map[string]tftypes.Value{
+		"items": testOrderItems(schema, coffeeID, quantity),
+	}
+	for name, value := range optional {
+		attrs[name] = tftypes.NewValue(tftypes.String, value)
+	}
+	config := testDynamicValue(t, schema, attrs)
+	proposed := config
+	if prior != nil {
+		// the computed attributes are kept from the prior state
+		for name, value := range prior {
+			if _, ok := attrs[name]; !ok {
+				attrs[name] = value
+			}
+		}
+		proposed = testDynamicValue(t, schema, attrs)
+	}
+	resp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         "provs_order",
+		PriorState:       testDynamicValue(t, schema, prior),
+		ProposedNewState: proposed,
+		Config:           config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// testOrderItems returns the items of an order of the coffee
+func testOrderItems(schema *tfprotov6.Schema, coffeeID string, quantity int) tftypes.Value {
+	itemsType := schema.ValueType().(tftypes.Object).AttributeTypes["items"].(tftypes.List)
+	itemType := itemsType.ElementType.(tftypes.Object)
+	coffeeType := itemType.AttributeTypes["coffee"].(tftypes.Object)
+	coffee := map[string]tftypes.Value{}
+	for name, typ := range coffeeType.AttributeTypes {
+		coffee[name] = tftypes.NewValue(typ, nil)
+	}
+	coffee["id"] = tftypes.NewValue(tftypes.String, coffeeID)
+	return tftypes.NewValue(itemsType, []tftypes.Value{
+		tftypes.NewValue(itemType, map[string]tftypes.Value{
+			"quantity": tftypes.NewValue(tftypes.Number, quantity),
+			"coffee":   tftypes.NewValue(coffeeType, coffee),
+		}),
+	})
+}
+
+func testAccOrderStockConfig(storagePath string, stock int, quantity int) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_ingredient" "beans" {
+  name = "Coffee beans"
+  unit = "g"
+}
+
+resource "provs_inventory" "beans" {
+  ingredient_id = provs_ingredient.beans.id
+  quantity      = %d
+}
+
+resource "provs_coffee" "espresso" {
+  name  = "Espresso"
+  price = 2.5
+  ingredients = [{
+    id       = provs_ingredient.beans.id
+    quantity = 7
+  }]
+}
+
+resource "provs_order" "test" {
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = %d
+  }]
+  depends_on = [provs_inventory.beans]
+}
+`, stock, quantity)
+}
@@ -0,0 +1,501 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                     = &promotionResource{}
+	_ resource.ResourceWithConfigure        = &promotionResource{}
+	_ resource.ResourceWithImportState      = &promotionResource{}
+	_ resource.ResourceWithConfigValidators = &promotionResource{}
+)
+
+// promotionResourceModel maps the resource schema data.
+type promotionResourceModel struct {
+	ID             types.String   `tfsdk:"id"`
+	Code           types.String   `tfsdk:"code"`
+	Kind           types.String   `tfsdk:"kind"`
+	Value          types.Float64  `tfsdk:"value"`
+	CoffeeIDs      []types.String `tfsdk:"coffee_ids"`
+	Categories     []types.String `tfsdk:"categories"`
+	StartsAt       types.String   `tfsdk:"starts_at"`
+	EndsAt         types.String   `tfsdk:"ends_at"`
+	MaxRedemptions types.Int64    `tfsdk:"max_redemptions"`
+	Redemptions    types.Int64    `tfsdk:"redemptions"`
+	Store          types.String   `tfsdk:"store"`
+}
+
+// NewResourcePromotion is a helper function to simplify the provider implementation.
+func NewResourcePromotion() resource.Resource {
+	return &promotionResource{}
+}
+
+// promotionResource manages the promotions discounting the orders using their code.
+type promotionResource struct {
+	client       client.Client[*model.Promotion]
+	codes        client.NameIndex
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *promotionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typePromotion
+}
+
+// Schema defines the schema for the resource.
+func (r *promotionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A promotion discounting the orders using its code, see the promo_code of provs_order. " +
+			"It cannot be deleted while orders use it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"code": schema.StringAttribute{
+				Required:    true,
+				Description: "The code the orders use. Promotion codes must be unique within a store.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"kind": schema.StringAttribute{
+				Required: true,
+				Description: fmt.Sprintf("One of: %s. A percentage discounts value percents of the eligible items, "+
+					"a fixed discount discounts value from them.", strings.Join(model.DiscountKinds(), ", ")),
+				Validators: []validator.String{
+					stringvalidator.OneOf(model.DiscountKinds()...),
+				},
+			},
+			"value": schema.Float64Attribute{
+				Required:    true,
+				Description: "The percentage, at most 100, or the amount discounted.",
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"coffee_ids": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The IDs of the coffees discounted. All the coffees are discounted when neither coffee_ids nor categories are set.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"categories": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The categories of the coffees discounted.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"starts_at": schema.StringAttribute{
+				Optional:    true,
+				Description: "When the promotion starts, in RFC 3339 format. The orders can use it from this time on.",
+				Validators: []validator.String{
+					timestampValidator{},
+				},
+			},
+			"ends_at": schema.StringAttribute{
+				Optional:    true,
+				Description: "When the promotion ends, in RFC 3339 format. The orders placed before keep their discount.",
+				Validators: []validator.String{
+					timestampValidator{},
+				},
+			},
+			"max_redemptions": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The number of orders that can use the promotion. Unlimited when not set.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"redemptions": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The number of orders using the promotion.",
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// ConfigValidators checks that the percentages are at most 100, and that the promotion ends after it starts.
+func (r *promotionResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		promotionPercentageValidator{},
+		promotionWindowValidator{},
+	}
+}
+
+// Create a new resource.
+func (r *promotionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan promotionResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	promotion := plan.toModel()
+	promotion.ID = uuid.NewString()
+	if err := r.codes.Reserve(promotion.Code, promotion.ID); err != nil {
+		addReserveCodeDiag(&resp.Diagnostics, promotion.Code, err)
+		return
+	}
+	if _, err := r.client.Create(promotion); err != nil {
+		_ = r.codes.Release(promotion.Code, promotion.ID)
+		resp.Diagnostics.AddError(
+			"Error creating promotion",
+			"Could not create promotion, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(promotion.ID)
+	plan.Redemptions = types.Int64Value(0)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *promotionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state promotionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	promotion, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Promotion",
+			fmt.Sprintf("Could not read promotion ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state = promotionFromModel(promotion, state)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update changes the promotion, keeping its redemptions. The orders already using it keep their discount until
+// they are updated.
+func (r *promotionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan promotionResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	prior, err := r.client.GetByID(plan.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Promotion",
+			fmt.Sprintf("Could not read promotion ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+	promotion := plan.toModel()
+	promotion.Redemptions = prior.Redemptions
+	if err := r.client.Update(promotion); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Promotion",
+			fmt.Sprintf("Could not update promotion ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	plan.Redemptions = types.Int64Value(int64(len(promotion.Redemptions)))
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the promotion, unless orders use it.
+func (r *promotionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state promotionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	promotion, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Promotion",
+			fmt.Sprintf("Could not read promotion ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	if len(promotion.Redemptions) > 0 {
+		resp.Diagnostics.AddError(
+			"Promotion in use",
+			fmt.Sprintf("The promotion %s cannot be deleted while orders use it: %s. Delete these orders or remove their promo_code first.",
+				promotion.Code, strings.Join(promotion.Redemptions, ", ")),
+		)
+		return
+	}
+
+	if err := r.client.Delete(promotion.ID); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Promotion",
+			fmt.Sprintf("Could not delete promotion ID %s: %s", promotion.ID, err),
+		)
+		return
+	}
+	if err := r.codes.Release(promotion.Code, promotion.ID); err != nil {
+		resp.Diagnostics.AddWarning(
+			"Error releasing Promotion code",
+			fmt.Sprintf("Could not release the code %q of the deleted promotion ID %s: %s", promotion.Code, promotion.ID, err),
+		)
+	}
+}
+
+func (r *promotionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *promotionResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.Promotion](c, typePromotion)
+	r.codes = newPromotionCodeIndex(c)
+}
+
+// newPromotionCodeIndex returns the index of the promotion codes
+func newPromotionCodeIndex(c client.BackendClient) client.NameIndex {
+	return client.NewNameIndex(c, typePromotion, func(p *model.Promotion) string { return p.Code })
+}
+
+// lookupPromotionByCode returns the promotion with the given code, or a not found error.
+func lookupPromotionByCode(c client.Client[*model.Promotion], codes client.NameIndex, code string) (*model.Promotion, error) {
+	id, err := codes.Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetByID(id)
+}
+
+func addReserveCodeDiag(diags *diag.Diagnostics, code string, err error) {
+	if errors.Is(err, client.ErrNameConflict) {
+		diags.AddAttributeError(
+			path.Root("code"),
+			"Promotion code already in use",
+			fmt.Sprintf("The code %q is already used by another promotion. Promotion codes must be unique within a store: %s", code, err),
+		)
+		return
+	}
+	diags.AddError(
+		"Error reserving Promotion code",
+		fmt.Sprintf("Could not reserve the code %q: %s", code, err),
+	)
+}
+
+func (m promotionResourceModel) toModel() *model.Promotion {
+	res := &model.Promotion{
+		ID:             m.ID.ValueString(),
+		Code:           m.Code.ValueString(),
+		Kind:           model.DiscountKind(m.Kind.ValueString()),
+		Value:          m.Value.ValueFloat64(),
+		MaxRedemptions: int(m.MaxRedemptions.ValueInt64()),
+	}
+	for _, id := range m.CoffeeIDs {
+		res.CoffeeIDs = append(res.CoffeeIDs, id.ValueString())
+	}
+	for _, category := range m.Categories {
+		res.Categories = append(res.Categories, category.ValueString())
+	}
+	// the timestamps are validated
+	if !m.StartsAt.IsNull() {
+		t, _ := time.Parse(time.RFC3339, m.StartsAt.ValueString())
+		res.StartsAt = &t
+	}
+	if !m.EndsAt.IsNull() {
+		t, _ := time.Parse(time.RFC3339, m.EndsAt.ValueString())
+		res.EndsAt = &t
+	}
+	return res
+}
+
+// promotionFromModel maps the stored promotion. The timestamps from the prior state are kept when they tell the same
+// instants, to not report a difference only because of their format, like "2025-01-01T00:00:00.000Z" or "+00:00".
+func promotionFromModel(promotion *model.Promotion, prior promotionResourceModel) promotionResourceModel {
+	res := promotionResourceModel{
+		ID:             types.StringValue(promotion.ID),
+		Code:           types.StringValue(promotion.Code),
+		Kind:           types.StringValue(string(promotion.Kind)),
+		Value:          types.Float64Value(promotion.Value),
+		StartsAt:       types.StringNull(),
+		EndsAt:         types.StringNull(),
+		MaxRedemptions: types.Int64Null(),
+		Redemptions:    types.Int64Value(int64(len(promotion.Redemptions))),
+		Store:          prior.Store,
+	}
+	for _, id := range promotion.CoffeeIDs {
+		res.CoffeeIDs = append(res.CoffeeIDs, types.StringValue(id))
+	}
+	for _, category := range promotion.Categories {
+		res.Categories = append(res.Categories, types.StringValue(category))
+	}
+	if promotion.StartsAt != nil {
+		res.StartsAt = promotionTimestamp(*promotion.StartsAt, prior.StartsAt)
+	}
+	if promotion.EndsAt != nil {
+		res.EndsAt = promotionTimestamp(*promotion.EndsAt, prior.EndsAt)
+	}
+	if promotion.MaxRedemptions > 0 {
+		res.MaxRedemptions = types.Int64Value(int64(promotion.MaxRedemptions))
+	}
+	return res
+}
+
+// promotionTimestamp returns the prior timestamp when it tells the instant t, or t formatted otherwise
+func promotionTimestamp(t time.Time, prior types.String) types.String {
+	if p, err := time.Parse(time.RFC3339, prior.ValueString()); err == nil && p.Equal(t) {
+		return prior
+	}
+	return types.StringValue(t.Format(time.RFC3339Nano))
+}
+
+// promotionPercentageValidator refuses the percentages above 100
+type promotionPercentageValidator struct{}
+
+func (v promotionPercentageValidator) Description(_ context.Context) string {
+	return "value must be at most 100 when the kind is percentage"
+}
+
+func (v promotionPercentageValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v promotionPercentageValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var kind types.String
+	var value types.Float64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("kind"), &kind)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("value"), &value)...)
+	if resp.Diagnostics.HasError() || kind.IsNull() || kind.IsUnknown() || value.IsNull() || value.IsUnknown() {
+		return
+	}
+	if kind.ValueString() == string(model.DiscountKindPercentage) && value.ValueFloat64() > 100 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("value"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("%s, got %v.", v.Description(ctx), value.ValueFloat64()),
+		)
+	}
+}
+
+// promotionWindowValidator refuses the promotions ending before they start
+type promotionWindowValidator struct{}
+
+func (v promotionWindowValidator) Description(_ context.Context) string {
+	return "ends_at must be after starts_at"
+}
+
+func (v promotionWindowValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v promotionWindowValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var startsAt, endsAt types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("starts_at"), &startsAt)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ends_at"), &endsAt)...)
+	if resp.Diagnostics.HasError() || startsAt.IsNull() || startsAt.IsUnknown() || endsAt.IsNull() || endsAt.IsUnknown() {
+		return
+	}
+	// invalid timestamps are reported by the attribute validators
+	start, err := time.Parse(time.RFC3339, startsAt.ValueString())
+	if err != nil {
+		return
+	}
+	end, err := time.Parse(time.RFC3339, endsAt.ValueString())
+	if err != nil {
+		return
+	}
+	if !end.After(start) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ends_at"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("%s, got %s and %s.", v.Description(ctx), startsAt.ValueString(), endsAt.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPromotionResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPromotionOrderConfig(storagePath, 2, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("provs_promotion.test", "id"),
+					resource.TestCheckResourceAttr("provs_promotion.test", "redemptions", "0"),
+					// 10% of the espressos, then 10% of silver on what remains
+					resource.TestCheckResourceAttr("provs_order.test", "subtotal", "10"),
+					resource.TestCheckResourceAttr("provs_order.test", "promotion_discount", "0.8"),
+					resource.TestCheckResourceAttr("provs_order.test", "discount", "1.72"),
+					resource.TestCheckResourceAttr("provs_order.test", "total", "8.28"),
+					resource.TestCheckOutput("preview", "7.2"),
+				),
+			},
+			{
+				// the redemptions show once refreshed
+				RefreshState: true,
+				Check:        resource.TestCheckResourceAttr("provs_promotion.test", "redemptions", "1"),
+			},
+			{
+				ResourceName:      "provs_promotion.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccPromotionOrderConfig(storagePath, 1, true),
+				ExpectError: regexp.MustCompile(`Promotion exhausted`),
+			},
+			{
+				Config:      testAccPromotionOrderConfig(storagePath, 2, false) + testAccPromotionDuplicateConfig,
+				ExpectError: regexp.MustCompile(`Promotion code already in use`),
+			},
+		},
+	})
+}
+
+func TestAccPromotionResource_invalid(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_promotion" "test" {
+  code  = "HALF"
+  kind  = "percentage"
+  value = 150
+}
+`,
+				ExpectError: regexp.MustCompile(`value must be at most 100 when the kind is percentage`),
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_promotion" "test" {
+  code      = "HALF"
+  kind      = "fixed"
+  value     = 1
+  starts_at = "2025-02-01T00:00:00Z"
+  ends_at   = "2025-01-01T00:00:00Z"
+}
+`,
+				ExpectError: regexp.MustCompile(`ends_at must be after starts_at`),
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_promotion" "test" {
+  code      = "HALF"
+  kind      = "fixed"
+  value     = 1
+  starts_at = "tomorrow"
+}
+`,
+				ExpectError: regexp.MustCompile(`RFC 3339`),
+			},
+		},
+	})
+}
+
+const testAccPromotionDuplicateConfig = `
+resource "provs_promotion" "duplicate" {
+  code  = "ESPRESSO10"
+  kind  = "fixed"
+  value = 1
+}
+`
+
+// testAccPromotionOrderConfig returns an order of 2 espressos and a tea from a silver customer using a promotion
+// of the espressos, with a second order when second is set
+func testAccPromotionOrderConfig(storagePath string, maxRedemptions int, second bool) string {
+	config := testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_coffee" "espresso" {
+  name     = "Espresso"
+  category = "espresso"
+  price    = 4
+}
+
+resource "provs_coffee" "tea" {
+  name  = "Tea"
+  price = 2
+}
+
+resource "provs_customer" "test" {
+  name         = "Ada"
+  email        = "ada@example.com"
+  loyalty_tier = "silver"
+}
+
+resource "provs_promotion" "test" {
+  code            = "ESPRESSO10"
+  kind            = "percentage"
+  value           = 10
+  categories      = ["espresso"]
+  starts_at       = "2020-01-01T00:00:00Z"
+  max_redemptions = %d
+}
+
+resource "provs_order" "test" {
+  customer_id = provs_customer.test.id
+  promo_code  = provs_promotion.test.code
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = 2
+  }, {
+    coffee = {
+      id = provs_coffee.tea.id
+    }
+    quantity = 1
+  }]
+}
+
+output "preview" {
+  value = provider::provs::apply_discount(8, "percentage", 10)
+}
+`, maxRedemptions)
+	if second {
+		config += `
+resource "provs_order" "second" {
+  promo_code = provs_promotion.test.code
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = 1
+  }]
+}
+`
+	}
+	return config
+}
+
+// TestPromotionResource_readTimestamps keeps the format of the timestamps of the state.
+func TestPromotionResource_readTimestamps(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startsAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := client.NewClient[*model.Promotion](backend, typePromotion).Create(&model.Promotion{
+		ID:       "1",
+		Code:     "WINTER",
+		Kind:     model.DiscountKindPercentage,
+		Value:    10,
+		StartsAt: &startsAt,
+		EndsAt:   &endsAt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+
+	for name, tc := range map[string]struct {
+		startsAt, endsAt         string
+		wantStartsAt, wantEndsAt string
+	}{
+		"same instants": {
+			startsAt:     "2025-01-01T00:00:00.000Z",
+			endsAt:       "2025-02-01T01:00:00+01:00",
+			wantStartsAt: "2025-01-01T00:00:00.000Z",
+			wantEndsAt:   "2025-02-01T01:00:00+01:00",
+		},
+		"changed instants": {
+			startsAt:     "2024-12-01T00:00:00Z",
+			endsAt:       "2025-02-01T00:00:00+01:00",
+			wantStartsAt: "2025-01-01T00:00:00Z",
+			wantEndsAt:   "2025-02-01T00:00:00Z",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state := testReadResource(ctx, t, server, schemas, "provs_promotion", map[string]tftypes.Value{
+				"id":        tftypes.NewValue(tftypes.String, "1"),
+				"starts_at": tftypes.NewValue(tftypes.String, tc.startsAt),
+				"ends_at":   tftypes.NewValue(tftypes.String, tc.endsAt),
+			})
+			if got := testStringAttr(t, state, "starts_at"); got != tc.wantStartsAt {
+				t.Fatalf("expected starts_at %s, got %s", tc.wantStartsAt, got)
+			}
+			if got := testStringAttr(t, state, "ends_at"); got != tc.wantEndsAt {
+				t.Fatalf("expected ends_at %s, got %s", tc.wantEndsAt, got)
+			}
+		})
+	}
+}
@@ -2,16 +2,28 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/audit"
 	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/generator"
 	"terraform-provider-provs/internal/model"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int32validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -19,24 +31,70 @@ var (
 	_ resource.Resource                = &secretResource{}
 	_ resource.ResourceWithConfigure   = &secretResource{}
 	_ resource.ResourceWithImportState = &secretResource{}
+	_ resource.ResourceWithModifyPlan  = &secretResource{}
 )
 
 // orderResourceModel maps the resource schema data.
 type secretModel struct {
-	SecretManagerID types.String `tfsdk:"secret_manager_id"`
-	SecretName      types.String `tfsdk:"secret_name"`
-	Secret          types.String `tfsdk:"secret"`
-	SecretWO        types.String `tfsdk:"secret_wo"`
-	HasSecretWO     types.Bool   `tfsdk:"has_secret_wo"`
-	SecretWOVersion types.Int32  `tfsdk:"secret_wo_version"`
+	SecretManagerID types.String         `tfsdk:"secret_manager_id"`
+	SecretName      types.String         `tfsdk:"secret_name"`
+	Secret          types.String         `tfsdk:"secret"`
+	SecretWO        types.String         `tfsdk:"secret_wo"`
+	HasSecretWO     types.Bool           `tfsdk:"has_secret_wo"`
+	SecretWOVersion types.Int32          `tfsdk:"secret_wo_version"`
+	VersionID       types.String         `tfsdk:"version_id"`
+	Rotation        *secretRotationModel `tfsdk:"rotation"`
+	NextRotationAt  types.String         `tfsdk:"next_rotation_at"`
+	Store           types.String         `tfsdk:"store"`
+}
+
+// secretRotationModel maps the rotation policy of a secret.
+type secretRotationModel struct {
+	Interval  types.String         `tfsdk:"interval"`
+	Generator secretGeneratorModel `tfsdk:"generator"`
+}
+
+// secretGeneratorModel maps the configuration of the generator used to rotate a secret.
+type secretGeneratorModel struct {
+	Type       types.String `tfsdk:"type"`
+	Length     types.Int64  `tfsdk:"length"`
+	Prefix     types.String `tfsdk:"prefix"`
+	Upper      types.Bool   `tfsdk:"upper"`
+	Lower      types.Bool   `tfsdk:"lower"`
+	Numeric    types.Bool   `tfsdk:"numeric"`
+	Special    types.Bool   `tfsdk:"special"`
+	MinUpper   types.Int64  `tfsdk:"min_upper"`
+	MinLower   types.Int64  `tfsdk:"min_lower"`
+	MinNumeric types.Int64  `tfsdk:"min_numeric"`
+	MinSpecial types.Int64  `tfsdk:"min_special"`
+	// Charset, OverrideSpecial and Exclude are null when not set
+	Charset         types.String `tfsdk:"charset"`
+	OverrideSpecial types.String `tfsdk:"override_special"`
+	Exclude         types.String `tfsdk:"exclude"`
 }
 
 func NewResourceSecret() resource.Resource {
-	return &secretResource{}
+	return newResourceSecret(time.Now)
+}
+
+// newResourceSecret returns the resource scheduling the rotations with the given clock.
+func newResourceSecret(now func() time.Time) resource.Resource {
+	return &secretResource{
+		now: now,
+	}
 }
 
 type secretResource struct {
 	client client.Client[*model.SecretManager]
+	leases client.Client[*model.SecretLease]
+	// now is the clock used to schedule rotations. Replaced in tests.
+	now func() time.Time
+	// principal is checked against the policy of the secret manager on every access
+	principal string
+	// audit records every access to the secret
+	audit *audit.Log
+	// providerData is the data the store of the secret is selected in
+	providerData any
 }
 
 // Metadata returns the resource type name.
@@ -55,17 +113,19 @@ func (r *secretResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Required: true,
 			},
 			"secret": schema.StringAttribute{
-				Optional: true,
+				Optional:  true,
+				Sensitive: true,
 				Validators: []validator.String{
 					stringvalidator.PreferWriteOnlyAttribute(path.MatchRoot("secret_wo")),
-					stringvalidator.ExactlyOneOf(path.MatchRoot("secret_wo")),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("secret_wo"), path.MatchRoot("rotation")),
 				},
 			},
 			"secret_wo": schema.StringAttribute{
 				Optional:  true,
 				WriteOnly: true,
+				Sensitive: true,
 				Validators: []validator.String{
-					stringvalidator.ExactlyOneOf(path.MatchRoot("secret")),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("secret"), path.MatchRoot("rotation")),
 					//stringvalidator.AlsoRequires(path.MatchRoot("has_attr_wo")),
 					stringvalidator.AlsoRequires(path.MatchRoot("secret_wo_version")),
 				},
@@ -74,8 +134,112 @@ func (r *secretResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Computed: true,
 			},
 			"secret_wo_version": schema.Int32Attribute{
-				Optional: true,
+				Optional:    true,
+				Description: "Change it to write the value configured in secret_wo. Changes of secret_wo alone are not detected.",
+				Validators: []validator.Int32{
+					int32validator.AlsoRequires(path.MatchRoot("secret_wo")),
+				},
+			},
+			"version_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of the current version of the secret. Every change of the value of the secret creates a new version.",
 			},
+			"rotation": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Generate the value of the secret and rotate it periodically. The generated values are never stored in the state.",
+				Validators: []validator.Object{
+					objectvalidator.ExactlyOneOf(path.MatchRoot("secret"), path.MatchRoot("secret_wo")),
+				},
+				Attributes: map[string]schema.Attribute{
+					"interval": schema.StringAttribute{
+						Required:    true,
+						Description: `How often the secret is rotated, as a duration like "720h".`,
+						Validators: []validator.String{
+							durationValidator{},
+						},
+					},
+					"generator": schema.SingleNestedAttribute{
+						Required: true,
+						Attributes: map[string]schema.Attribute{
+							"type": schema.StringAttribute{
+								Required:    true,
+								Description: "One of: " + strings.Join(generator.Kinds(), ", "),
+								Validators: []validator.String{
+									stringvalidator.OneOf(generator.Kinds()...),
+								},
+							},
+							"length": schema.Int64Attribute{
+								Optional:    true,
+								Computed:    true,
+								Default:     int64default.StaticInt64(generator.DefaultRandomBytesLength),
+								Description: "The number of bytes for random_bytes or the number of characters for password.",
+								Validators: []validator.Int64{
+									int64validator.AtLeast(1),
+								},
+							},
+							"prefix": schema.StringAttribute{
+								Optional:    true,
+								Description: `Prepended to every generated value, followed by a "-".`,
+							},
+							"upper":       secretGeneratorCharsetAttribute("Include upper case letters in passwords."),
+							"lower":       secretGeneratorCharsetAttribute("Include lower case letters in passwords."),
+							"numeric":     secretGeneratorCharsetAttribute("Include digits in passwords."),
+							"special":     secretGeneratorCharsetAttribute("Include special characters in passwords."),
+							"min_upper":   secretGeneratorMinAttribute("The minimum number of upper case letters in passwords."),
+							"min_lower":   secretGeneratorMinAttribute("The minimum number of lower case letters in passwords."),
+							"min_numeric": secretGeneratorMinAttribute("The minimum number of digits in passwords."),
+							"min_special": secretGeneratorMinAttribute("The minimum number of special characters in passwords."),
+							"charset": schema.StringAttribute{
+								Optional:    true,
+								Description: "The characters of the passwords, replacing the upper case letters, the lower case letters and the digits.",
+								Validators: []validator.String{
+									stringvalidator.LengthAtLeast(1),
+								},
+							},
+							"override_special": schema.StringAttribute{
+								Optional:    true,
+								Description: "Replaces the special characters of the passwords, " + generator.CharsetSpecial + " by default.",
+								Validators: []validator.String{
+									stringvalidator.LengthAtLeast(1),
+								},
+							},
+							"exclude": schema.StringAttribute{
+								Optional:    true,
+								Description: `The characters that never appear in the passwords, like "0O1l".`,
+								Validators: []validator.String{
+									stringvalidator.LengthAtLeast(1),
+								},
+							},
+						},
+					},
+				},
+			},
+			"next_rotation_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "RFC3339 timestamp of the moment the secret becomes due for rotation.",
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+func secretGeneratorCharsetAttribute(description string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(true),
+		Description: description,
+	}
+}
+
+func secretGeneratorMinAttribute(description string) schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Optional:    true,
+		Computed:    true,
+		Default:     int64default.StaticInt64(0),
+		Description: description,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
 		},
 	}
 }
@@ -91,6 +255,7 @@ func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 	diags = req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -103,17 +268,36 @@ func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest,
 		)
 		return
 	}
-	if mgr.Secrets == nil {
-		mgr.Secrets = map[string]string{}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessWrite, plan.SecretName.ValueString(), model.AuditOperationCreate)
+	if access == nil {
+		return
 	}
-	mgr.Secrets[plan.SecretName.ValueString()] = plan.Secret.ValueString()
+	defer access.done(&resp.Diagnostics)
 	// Generate API request body from plan
+	now := r.now()
+	value := plan.Secret.ValueString()
 	plan.HasSecretWO = types.BoolValue(false)
 	// Secret attributes should be read only from the req.config, not from req.plan
-	if !config.SecretWOVersion.IsNull() {
-		mgr.Secrets[plan.SecretName.ValueString()] = config.SecretWO.ValueString()
+	if !config.SecretWO.IsNull() {
+		value = config.SecretWO.ValueString()
 		plan.HasSecretWO = types.BoolValue(true)
 	}
+	var rotation *model.SecretRotation
+	if plan.Rotation != nil {
+		rotation, value, err = plan.Rotation.rotate(now)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rotation"),
+				"Error generating secret",
+				fmt.Sprintf("Could not generate the value of the secret %q: %s", plan.SecretName.ValueString(), err),
+			)
+			return
+		}
+	}
+	version := mgr.PutSecret(plan.SecretName.ValueString(), value, plan.HasSecretWO.ValueBool(), now)
+	mgr.Secrets[plan.SecretName.ValueString()].Rotation = rotation
+	plan.VersionID = types.StringValue(version.ID)
+	plan.NextRotationAt = rotationTimestamp(rotation)
 
 	if err := r.client.Update(mgr); err != nil {
 		resp.Diagnostics.AddError(
@@ -122,6 +306,10 @@ func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest,
 		)
 		return
 	}
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
+	resp.Diagnostics.Append(setSecretWOWrite(ctx, resp.Private, plan.HasSecretWO.ValueBool(), version.ID)...)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -137,6 +325,7 @@ func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, res
 	var state secretModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -150,6 +339,11 @@ func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, res
 		)
 		return
 	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessRead, state.SecretName.ValueString(), model.AuditOperationRead)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
 	addMissingSecretDiag := func() {
 		resp.Diagnostics.AddError(
 			"Error Reading secret from secret manager",
@@ -160,20 +354,58 @@ func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, res
 			),
 		)
 	}
-	if mgr.Secrets == nil {
-		addMissingSecretDiag()
-		return
-	}
 	secret, ok := mgr.Secrets[state.SecretName.ValueString()]
-	if !ok {
+	if !ok || secret.Current() == nil {
 		addMissingSecretDiag()
 		return
 	}
-	state.Secret = types.StringValue(secret)
+	// The prior state knows the secrets written as write-only before the secret manager recorded it: keep it.
+	// Rely on the secret manager on import, since there is no prior state to take this from.
+	state.HasSecretWO = types.BoolValue(secret.WriteOnly || state.HasSecretWO.ValueBool())
+	state.VersionID = types.StringValue(secret.Current().ID)
+	state.Secret = types.StringValue(secret.Current().Value)
 	if state.HasSecretWO.ValueBool() { // DO NOT RETURN WRITE ONLY VALUES
 		state.Secret = types.StringNull()
+		// the hash kept by the previous versions of the provider is dropped. The changes are detected from the next write.
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, legacySecretWOFingerprintKey, nil)...)
+		written, diags := getSecretWOWrite(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// Nothing written after import. It is recorded on the next write.
+		if written != nil && !written.Drifted && written.VersionID != secret.Current().ID {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("secret_wo"),
+				"Write-only secret changed outside of Terraform",
+				fmt.Sprintf(
+					"The current version of the secret %q from secret manager %q is not the one last written by Terraform. "+
+						"The configured value will be written again on the next apply.",
+					state.SecretName.ValueString(),
+					state.SecretManagerID.ValueString(),
+				),
+			)
+			written.Drifted = true
+			resp.Diagnostics.Append(written.set(ctx, resp.Private)...)
+		}
+	}
+	state.Rotation = secretRotationFromModel(secret.Rotation, state.Rotation)
+	state.NextRotationAt = rotationTimestamp(secret.Rotation)
+	if secret.Rotation != nil {
+		// generated values are not returned either
+		state.Secret = types.StringNull()
+		if secret.Rotation.Due(r.now()) {
+			tflog.SubsystemInfo(ctx, logSubsystem, "Secret is overdue for rotation", map[string]any{
+				"secret_manager_id": state.SecretManagerID.ValueString(),
+				"secret_name":       state.SecretName.ValueString(),
+				"next_rotation_at":  state.NextRotationAt.ValueString(),
+			})
+		}
 	}
 
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -187,6 +419,7 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 	var plan, config secretModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -204,6 +437,11 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 		)
 		return
 	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessWrite, plan.SecretName.ValueString(), model.AuditOperationUpdate)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
 
 	addMissingSecretDiag := func() {
 		resp.Diagnostics.AddError(
@@ -215,20 +453,56 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 			),
 		)
 	}
-	if mgr.Secrets == nil {
-		addMissingSecretDiag()
-		return
-	}
-	if _, ok := mgr.Secrets[plan.SecretName.ValueString()]; !ok {
+	secret, ok := mgr.Secrets[plan.SecretName.ValueString()]
+	if !ok || secret.Current() == nil {
 		addMissingSecretDiag()
 		return
 	}
-	mgr.Secrets[plan.SecretName.ValueString()] = plan.Secret.ValueString()
+	now := r.now()
+	value := plan.Secret.ValueString()
 	plan.HasSecretWO = types.BoolValue(false)
+	// The write-only value is always null in the plan. Changes of it alone do not trigger an update, so getting here
+	// means that the secret_wo_version changed or that a drift was detected, and the value must be written again.
 	if !config.SecretWO.IsNull() {
-		mgr.Secrets[plan.SecretName.ValueString()] = plan.SecretWO.ValueString()
+		value = config.SecretWO.ValueString()
 		plan.HasSecretWO = types.BoolValue(true)
 	}
+	version := secret.Current()
+	var rotation *model.SecretRotation
+	if plan.Rotation != nil {
+		rotation, err = plan.Rotation.toModel()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("rotation"), "Invalid rotation", err.Error())
+			return
+		}
+		// A change of the interval alone reschedules the next rotation without generating a new value
+		if secret.Rotation != nil && !secret.Rotation.Due(now) && secret.Rotation.Generator == rotation.Generator {
+			rotation.NextRotationAt = version.CreatedAt.Add(rotation.Interval)
+		} else {
+			rotation, value, err = plan.Rotation.rotate(now)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("rotation"),
+					"Error generating secret",
+					fmt.Sprintf("Could not generate the value of the secret %q: %s", plan.SecretName.ValueString(), err),
+				)
+				return
+			}
+			if !r.checkNoActiveLeases(&resp.Diagnostics, mgr, "write a new version of", plan.SecretName.ValueString(), now) {
+				return
+			}
+			version = mgr.PutSecret(plan.SecretName.ValueString(), value, false, now)
+		}
+	} else {
+		// writing the current value again adds no version, so the leases do not prevent it
+		if value != version.Value && !r.checkNoActiveLeases(&resp.Diagnostics, mgr, "write a new version of", plan.SecretName.ValueString(), now) {
+			return
+		}
+		version = mgr.PutSecret(plan.SecretName.ValueString(), value, plan.HasSecretWO.ValueBool(), now)
+	}
+	secret.Rotation = rotation
+	plan.VersionID = types.StringValue(version.ID)
+	plan.NextRotationAt = rotationTimestamp(rotation)
 
 	// Update existing res
 	if err := r.client.Update(mgr); err != nil {
@@ -238,6 +512,10 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 		)
 		return
 	}
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
+	resp.Diagnostics.Append(setSecretWOWrite(ctx, resp.Private, plan.HasSecretWO.ValueBool(), version.ID)...)
 
 	// Here is not needed to handle unsetting the secret field since the provider framework is already doing it based on the schema
 	diags = resp.State.Set(ctx, plan)
@@ -247,12 +525,61 @@ func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 }
 
+// ModifyPlan plans the rotation of the secret once it is overdue, even if its configuration did not change.
+func (r *secretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to rotate on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var state, plan secretModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Rotation != nil && !state.NextRotationAt.IsNull() {
+		next, err := time.Parse(time.RFC3339, state.NextRotationAt.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("next_rotation_at"),
+				"Invalid next rotation timestamp",
+				fmt.Sprintf("Could not parse the next rotation timestamp from the state: %s", err),
+			)
+			return
+		}
+		if r.now().Before(next) {
+			return
+		}
+		tflog.SubsystemInfo(ctx, logSubsystem, "Planning the rotation of the secret", map[string]any{
+			"secret_manager_id": state.SecretManagerID.ValueString(),
+			"secret_name":       state.SecretName.ValueString(),
+			"next_rotation_at":  state.NextRotationAt.ValueString(),
+		})
+	} else if state.HasSecretWO.ValueBool() && !plan.SecretWOVersion.IsNull() {
+		written, diags := getSecretWOWrite(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() || written == nil || !written.Drifted {
+			return
+		}
+		tflog.SubsystemInfo(ctx, logSubsystem, "Planning to write the write-only secret again", map[string]any{
+			"secret_manager_id": state.SecretManagerID.ValueString(),
+			"secret_name":       state.SecretName.ValueString(),
+		})
+	} else {
+		return
+	}
+	plan.VersionID = types.StringUnknown()
+	plan.NextRotationAt = types.StringUnknown()
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 // Delete deletes the resource and removes the Terraform state on success.
 func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	// Retrieve values from plan
 	var state secretModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -265,7 +592,16 @@ func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		)
 		return
 	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessWrite, state.SecretName.ValueString(), model.AuditOperationDelete)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
+	if !r.checkNoActiveLeases(&resp.Diagnostics, mgr, "delete", state.SecretName.ValueString(), r.now()) {
+		return
+	}
 
+	// Deleting the secret removes all of its versions
 	delete(mgr.Secrets, state.SecretName.ValueString())
 	// Update existing res
 	if err := r.client.Update(mgr); err != nil {
@@ -275,11 +611,50 @@ func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		)
 		return
 	}
+	access.succeeded(&resp.Diagnostics)
 }
 
+// ImportState expects an identifier in the format <secret_manager_id>/<secret_name>, or a secret reference returned
+// by the secret_ref function.
+// The rest of the attributes, including has_secret_wo, are populated by the Read that follows the import,
+// which also checks that the principal is allowed to read the secret.
 func (r *secretResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp) // TODO this might be wrong
+	// the store is configured first, the import being recorded in the audit log of the store of the secret
+	storeName, id := cutImportStore(r.providerData, req.ID)
+	if !importResourceStore(ctx, r, r.providerData, storeName, resp) {
+		return
+	}
+
+	var mgrID, secretName string
+	if strings.HasPrefix(id, model.SecretRefPrefix) {
+		ref, err := model.ParseSecretRef(id)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unexpected Import Identifier",
+				err.Error(),
+			)
+			return
+		}
+		mgrID, secretName = ref.SecretManagerID, ref.SecretName
+	} else {
+		var ok bool
+		mgrID, secretName, ok = strings.Cut(id, "/")
+		if !ok || mgrID == "" || secretName == "" {
+			resp.Diagnostics.AddError(
+				"Unexpected Import Identifier",
+				fmt.Sprintf("Expected import identifier with format: <secret_manager_id>/<secret_name> or %s<secret_manager_id>/<secret_name>, "+
+					"prefixed with <store>/ for the secrets of a named store. Got: %q",
+					model.SecretRefPrefix, req.ID),
+			)
+			return
+		}
+	}
+
+	if !newSecretAccess(r.audit, r.principal, mgrID, secretName, model.AuditOperationImport).succeeded(&resp.Diagnostics) {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("secret_manager_id"), mgrID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("secret_name"), secretName)...)
 }
 
 // Configure adds the provider configured client to the resource.
@@ -301,6 +676,201 @@ func (r *secretResource) Configure(_ context.Context, req resource.ConfigureRequ
 		return
 	}
 
+	r.providerData = req.ProviderData
 	// typeSecretManager because it updates that data
 	r.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	r.leases = client.NewClient[*model.SecretLease](c, typeSecretLease)
+	r.principal = principalFrom(req.ProviderData)
+	r.audit = auditLogFrom(req.ProviderData)
+}
+
+// checkNoActiveLeases adds an error diagnostic and returns false when the secret manager refuses to write a new
+// version of the secret, or to delete it, while it is leased by open readers. action tells which one is refused.
+func (r *secretResource) checkNoActiveLeases(diags *diag.Diagnostics, mgr *model.SecretManager, action string, secretName string, now time.Time) bool {
+	if !mgr.DenyRotationWithActiveLeases {
+		return true
+	}
+	leases, err := r.leases.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		diags.AddError(
+			"Error reading the leases of the secret",
+			fmt.Sprintf("Could not read the leases of the secret %q: %s", secretName, err),
+		)
+		return false
+	}
+	active := model.ActiveLeases(leases, mgr.ID, secretName, now)
+	if len(active) == 0 {
+		return true
+	}
+	diags.AddError(
+		"Secret is leased",
+		fmt.Sprintf(
+			"The secret manager %q refuses to %s the secret %q while it has active leases. "+
+				"%d lease(s) are open, the last one expiring at %s. Retry after the readers are closed.",
+			mgr.ID, action, secretName, len(active), latestLeaseExpiry(active).UTC().Format(time.RFC3339),
+		),
+	)
+	return false
+}
+
+func latestLeaseExpiry(leases []*model.SecretLease) time.Time {
+	var res time.Time
+	for _, l := range leases {
+		if l.ExpiresAt.After(res) {
+			res = l.ExpiresAt
+		}
+	}
+	return res
+}
+
+// toModel returns the rotation policy without scheduling the next rotation
+func (m *secretRotationModel) toModel() (*model.SecretRotation, error) {
+	interval, err := time.ParseDuration(m.Interval.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid rotation interval: %w", err)
+	}
+	g := m.Generator
+	return &model.SecretRotation{
+		Interval: interval,
+		Generator: generator.Spec{
+			Kind:   generator.Kind(g.Type.ValueString()),
+			Length: int(g.Length.ValueInt64()),
+			Prefix: g.Prefix.ValueString(),
+			Password: generator.PasswordPolicy{
+				Length:          int(g.Length.ValueInt64()),
+				Upper:           g.Upper.ValueBool(),
+				Lower:           g.Lower.ValueBool(),
+				Numeric:         g.Numeric.ValueBool(),
+				Special:         g.Special.ValueBool(),
+				MinUpper:        int(g.MinUpper.ValueInt64()),
+				MinLower:        int(g.MinLower.ValueInt64()),
+				MinNumeric:      int(g.MinNumeric.ValueInt64()),
+				MinSpecial:      int(g.MinSpecial.ValueInt64()),
+				Charset:         g.Charset.ValueString(),
+				OverrideSpecial: g.OverrideSpecial.ValueString(),
+				Exclude:         g.Exclude.ValueString(),
+			},
+		},
+	}, nil
+}
+
+// rotate generates a new value for the secret and schedules the next rotation relative to now
+func (m *secretRotationModel) rotate(now time.Time) (*model.SecretRotation, string, error) {
+	rotation, err := m.toModel()
+	if err != nil {
+		return nil, "", err
+	}
+	value, err := generator.Generate(rotation.Generator)
+	if err != nil {
+		return nil, "", err
+	}
+	rotation.NextRotationAt = now.Add(rotation.Interval)
+	return rotation, value, nil
+}
+
+// secretRotationFromModel maps the stored rotation policy. The interval from the prior state is kept when it
+// describes the same duration, to not report a difference only because of its format.
+func secretRotationFromModel(rotation *model.SecretRotation, prior *secretRotationModel) *secretRotationModel {
+	if rotation == nil {
+		return nil
+	}
+	interval := types.StringValue(rotation.Interval.String())
+	if prior != nil {
+		if d, err := time.ParseDuration(prior.Interval.ValueString()); err == nil && d == rotation.Interval {
+			interval = prior.Interval
+		}
+	}
+	g := rotation.Generator
+	length := g.Length
+	if g.Kind == generator.KindPassword {
+		length = g.Password.Length
+	}
+	return &secretRotationModel{
+		Interval: interval,
+		Generator: secretGeneratorModel{
+			Type:            types.StringValue(string(g.Kind)),
+			Length:          types.Int64Value(int64(length)),
+			Prefix:          optionalString(g.Prefix),
+			Upper:           types.BoolValue(g.Password.Upper),
+			Lower:           types.BoolValue(g.Password.Lower),
+			Numeric:         types.BoolValue(g.Password.Numeric),
+			Special:         types.BoolValue(g.Password.Special),
+			MinUpper:        types.Int64Value(int64(g.Password.MinUpper)),
+			MinLower:        types.Int64Value(int64(g.Password.MinLower)),
+			MinNumeric:      types.Int64Value(int64(g.Password.MinNumeric)),
+			MinSpecial:      types.Int64Value(int64(g.Password.MinSpecial)),
+			Charset:         optionalString(g.Password.Charset),
+			OverrideSpecial: optionalString(g.Password.OverrideSpecial),
+			Exclude:         optionalString(g.Password.Exclude),
+		},
+	}
+}
+
+// optionalString returns the value of an optional attribute, null when empty
+func optionalString(v string) types.String {
+	if v == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(v)
+}
+
+func rotationTimestamp(rotation *model.SecretRotation) types.String {
+	if rotation == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(rotation.NextRotationAt.UTC().Format(time.RFC3339))
+}
+
+// secretWOWriteKey is the private state key holding the version of the last written write-only value
+const secretWOWriteKey = "secret_wo_write"
+
+// legacySecretWOFingerprintKey is the private state key of the hash of the last written write-only value, which was
+// kept before the version was. The hash could be checked against guesses of the value: it is removed from the state.
+const legacySecretWOFingerprintKey = "secret_wo_fingerprint"
+
+// secretWOWrite allows detecting changes of write-only values without storing them, nor anything derived from
+// them, in the state: the value of a secret only changes with a new version.
+type secretWOWrite struct {
+	VersionID string `json:"version_id"`
+	// Drifted is set once the current version is found to differ from the last written one
+	Drifted bool `json:"drifted,omitempty"`
+}
+
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+func getSecretWOWrite(ctx context.Context, private privateState) (*secretWOWrite, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, secretWOWriteKey)
+	if diags.HasError() || len(raw) == 0 {
+		return nil, diags
+	}
+	var res secretWOWrite
+	if err := json.Unmarshal(raw, &res); err != nil {
+		diags.AddError("Invalid private state", fmt.Sprintf("Could not read the last write of the write-only secret: %s", err))
+		return nil, diags
+	}
+	return &res, diags
+}
+
+func (w *secretWOWrite) set(ctx context.Context, private privateState) diag.Diagnostics {
+	raw, err := json.Marshal(w)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Invalid private state", fmt.Sprintf("Could not write the last write of the write-only secret: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, secretWOWriteKey, raw)
+}
+
+// setSecretWOWrite records the version of the written value, or removes it when the value is not write-only
+func setSecretWOWrite(ctx context.Context, private privateState, writeOnly bool, versionID string) diag.Diagnostics {
+	diags := private.SetKey(ctx, legacySecretWOFingerprintKey, nil)
+	if !writeOnly {
+		diags.Append(private.SetKey(ctx, secretWOWriteKey, nil)...)
+		return diags
+	}
+	diags.Append((&secretWOWrite{VersionID: versionID}).set(ctx, private)...)
+	return diags
 }
@@ -2,27 +2,44 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"terraform-provider-provs/internal/audit"
 	"terraform-provider-provs/internal/client"
 	"terraform-provider-provs/internal/model"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &secretManagerResource{}
-	_ resource.ResourceWithConfigure = &secretManagerResource{}
+	_ resource.Resource                = &secretManagerResource{}
+	_ resource.ResourceWithConfigure   = &secretManagerResource{}
+	_ resource.ResourceWithImportState = &secretManagerResource{}
 )
 
+// secretManagerImportNamePrefix is the prefix of the import identifiers referencing a secret manager by its name
+const secretManagerImportNamePrefix = "name:"
+
 // orderResourceModel maps the resource schema data.
 type secretManagerModel struct {
-	ID   types.String `tfsdk:"id"`
-	Name types.String `tfsdk:"name"`
+	ID                           types.String `tfsdk:"id"`
+	Name                         types.String `tfsdk:"name"`
+	VersionRetention             types.Int64  `tfsdk:"version_retention"`
+	DenyRotationWithActiveLeases types.Bool   `tfsdk:"deny_rotation_with_active_leases"`
+	Store                        types.String `tfsdk:"store"`
 }
 
 func NewResourceSecretManager() resource.Resource {
@@ -32,6 +49,12 @@ func NewResourceSecretManager() resource.Resource {
 // This resource is for managing the existence of a secret manager. For handling the secret stored, check ephemeral/secret_manager.go
 type secretManagerResource struct {
 	client client.Client[*model.SecretManager]
+	names  client.NameIndex
+	// principal must be granted the manage access by the policy to change or delete the secret manager
+	principal string
+	// audit records the changes of the secret manager and their denials
+	audit        *audit.Log
+	providerData any
 }
 
 // Metadata returns the resource type name.
@@ -45,10 +68,29 @@ func (r *secretManagerResource) Schema(_ context.Context, _ resource.SchemaReque
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Required: true,
 			},
+			"version_retention": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(model.DefaultSecretVersionRetention),
+				Description: "The number of versions of each secret that keep their value. Older versions are destroyed.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"deny_rotation_with_active_leases": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Refuse to write new versions of the secrets, or to delete them, while they are leased by open provs_secret ephemeral resources.",
+			},
+			"store": resourceStoreSchema(),
 		},
 	}
 }
@@ -59,17 +101,25 @@ func (r *secretManagerResource) Create(ctx context.Context, req resource.CreateR
 	var plan secretManagerModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Generate API request body from plan
 	item := model.SecretManager{
-		ID:   uuid.NewString(),
-		Name: plan.Name.ValueString(),
+		ID:                           uuid.NewString(),
+		Name:                         plan.Name.ValueString(),
+		VersionRetention:             int(plan.VersionRetention.ValueInt64()),
+		DenyRotationWithActiveLeases: plan.DenyRotationWithActiveLeases.ValueBool(),
 	}
 
+	if err := r.names.Reserve(item.Name, item.ID); err != nil {
+		addReserveNameDiag(&resp.Diagnostics, item.Name, err)
+		return
+	}
 	if _, err := r.client.Create(&item); err != nil {
+		_ = r.names.Release(item.Name, item.ID)
 		resp.Diagnostics.AddError(
 			"Error creating secret manager resource",
 			fmt.Sprintf("Could not create secret manager: %s ", err),
@@ -94,6 +144,7 @@ func (r *secretManagerResource) Read(ctx context.Context, req resource.ReadReque
 	var state secretManagerModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -107,7 +158,20 @@ func (r *secretManagerResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
+	// the secret managers created before the name index existed are added to it, as the lookups by name never write.
+	// A read-only provider leaves them to the lookups searching for them.
+	if _, err := r.names.Lookup(mgr.Name); client.IsNotFound(err) {
+		if err := r.names.Reserve(mgr.Name, mgr.ID); err != nil && !errors.Is(err, client.ErrReadOnly) {
+			resp.Diagnostics.AddWarning(
+				"Error indexing SecretManager name",
+				fmt.Sprintf("Could not add the name %q of the SecretManager ID %s to the name index: %s", mgr.Name, mgr.ID, err),
+			)
+		}
+	}
+
 	state.Name = types.StringValue(mgr.Name)
+	state.VersionRetention = types.Int64Value(int64(mgr.Retention()))
+	state.DenyRotationWithActiveLeases = types.BoolValue(mgr.DenyRotationWithActiveLeases)
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -121,6 +185,7 @@ func (r *secretManagerResource) Update(ctx context.Context, req resource.UpdateR
 	var plan secretManagerModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -133,14 +198,46 @@ func (r *secretManagerResource) Update(ctx context.Context, req resource.UpdateR
 		)
 		return
 	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessManage, "", model.AuditOperationUpdate)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
+	oldName := mgr.Name
 	mgr.Name = plan.Name.ValueString()
+	// The lowered retention is applied on the next write of each secret
+	mgr.VersionRetention = int(plan.VersionRetention.ValueInt64())
+	mgr.DenyRotationWithActiveLeases = plan.DenyRotationWithActiveLeases.ValueBool()
+	if err := r.names.Reserve(mgr.Name, mgr.ID); err != nil {
+		addReserveNameDiag(&resp.Diagnostics, mgr.Name, err)
+		return
+	}
+	// the new name is released when the update fails, so that it does not stay taken by this secret manager
+	releaseNewName := func() {
+		if oldName != mgr.Name {
+			_ = r.names.Release(mgr.Name, mgr.ID)
+		}
+	}
 	if err := r.client.Update(mgr); err != nil {
+		releaseNewName()
 		resp.Diagnostics.AddError(
 			"Error Updating SecretManager",
 			fmt.Sprintf("Could not update SecretManager ID %s: %s", plan.ID.ValueString(), err),
 		)
 		return
 	}
+	if !access.succeeded(&resp.Diagnostics) {
+		releaseNewName()
+		return
+	}
+	if oldName != mgr.Name {
+		if err := r.names.Release(oldName, mgr.ID); err != nil {
+			resp.Diagnostics.AddWarning(
+				"Error releasing SecretManager name",
+				fmt.Sprintf("Could not release the old name %q of SecretManager ID %s: %s", oldName, mgr.ID, err),
+			)
+		}
+	}
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -155,10 +252,24 @@ func (r *secretManagerResource) Delete(ctx context.Context, req resource.DeleteR
 	var state secretManagerModel
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	mgr, err := r.client.GetByID(state.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting SecretManager",
+			fmt.Sprintf("Failed to read the secret manager with id %q: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessManage, "", model.AuditOperationDelete)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
 	if err := r.client.Delete(state.ID.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting SecretManager",
@@ -166,11 +277,88 @@ func (r *secretManagerResource) Delete(ctx context.Context, req resource.DeleteR
 		)
 		return
 	}
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
+	if err := r.names.Release(state.Name.ValueString(), state.ID.ValueString()); err != nil {
+		resp.Diagnostics.AddWarning(
+			"Error releasing SecretManager name",
+			fmt.Sprintf("Could not release the name %q of the deleted secret manager with id %q: %s", state.Name.ValueString(), state.ID.ValueString(), err),
+		)
+	}
 }
 
+// ImportState accepts either the ID of the secret manager or its name in the format name:<name>.
 func (r *secretManagerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	storeName, id := cutImportStore(r.providerData, req.ID)
+	name, byName := strings.CutPrefix(id, secretManagerImportNamePrefix)
+	if !byName {
+		// Retrieve import ID and save to id attribute
+		importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+		return
+	}
+	if !importResourceStore(ctx, r, r.providerData, storeName, resp) {
+		return
+	}
+
+	id, err := lookupSecretManagerByName(r.client, r.names, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing SecretManager",
+			fmt.Sprintf("Could not find the secret manager named %q: %s", name, err),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+// newSecretManagerNameIndex returns the index of the secret manager names
+func newSecretManagerNameIndex(c client.BackendClient) client.NameIndex {
+	return client.NewNameIndex(c, typeSecretManager, func(m *model.SecretManager) string { return m.Name })
+}
+
+// lookupSecretManagerByName returns the ID of the secret manager with the given name.
+// Secret managers created before the name index existed are searched for. The lookup never writes, as the data
+// sources use it: they are added to the index on their next refresh.
+func lookupSecretManagerByName(c client.Client[*model.SecretManager], names client.NameIndex, name string) (string, error) {
+	id, err := names.Lookup(name)
+	if err == nil || !client.IsNotFound(err) {
+		return id, err
+	}
+
+	mgrs, err := c.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		return "", err
+	}
+	var found []string
+	for _, mgr := range mgrs {
+		if mgr.Name == name {
+			found = append(found, mgr.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no such secret manager")
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("the name is used by multiple secret managers: %s", strings.Join(found, ", "))
+	}
+}
+
+func addReserveNameDiag(diags *diag.Diagnostics, name string, err error) {
+	if errors.Is(err, client.ErrNameConflict) {
+		diags.AddAttributeError(
+			path.Root("name"),
+			"Secret manager name already in use",
+			fmt.Sprintf("The name %q is already used by another secret manager. Secret manager names must be unique within a store: %s", name, err),
+		)
+		return
+	}
+	diags.AddError(
+		"Error reserving SecretManager name",
+		fmt.Sprintf("Could not reserve the name %q: %s", name, err),
+	)
 }
 
 // Configure adds the provider configured client to the resource.
@@ -192,5 +380,9 @@ func (r *secretManagerResource) Configure(_ context.Context, req resource.Config
 		return
 	}
 
+	r.providerData = req.ProviderData
 	r.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	r.names = newSecretManagerNameIndex(c)
+	r.principal = principalFrom(req.ProviderData)
+	r.audit = auditLogFrom(req.ProviderData)
 }
@@ -0,0 +1,344 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &secretManagerPolicyResource{}
+	_ resource.ResourceWithConfigure   = &secretManagerPolicyResource{}
+	_ resource.ResourceWithImportState = &secretManagerPolicyResource{}
+)
+
+// secretManagerPolicyModel maps the resource schema data.
+type secretManagerPolicyModel struct {
+	SecretManagerID types.String       `tfsdk:"secret_manager_id"`
+	Grants          []secretGrantModel `tfsdk:"grants"`
+	Store           types.String       `tfsdk:"store"`
+}
+
+// secretGrantModel maps a grant of the policy.
+type secretGrantModel struct {
+	Principals  []types.String `tfsdk:"principals"`
+	Access      types.String   `tfsdk:"access"`
+	SecretNames []types.String `tfsdk:"secret_names"`
+}
+
+func NewResourceSecretManagerPolicy() resource.Resource {
+	return &secretManagerPolicyResource{}
+}
+
+// This resource manages the policy stored inside a secret manager. A secret manager has at most one policy.
+type secretManagerPolicyResource struct {
+	client client.Client[*model.SecretManager]
+	// principal must be granted the manage access by the current policy to change it
+	principal string
+	// audit records the changes of the policy and their denials
+	audit        *audit.Log
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *secretManagerPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeSecretManagerPolicy
+}
+
+// Schema defines the schema for the resource.
+func (r *secretManagerPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Restricts the access to the secrets of a secret manager to the granted principals. " +
+			"Once a secret manager has a policy, the secrets can be accessed only by the principal configured in the provider, if granted.",
+		Attributes: map[string]schema.Attribute{
+			"secret_manager_id": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"grants": schema.ListNestedAttribute{
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"principals": schema.ListAttribute{
+							Required:    true,
+							ElementType: types.StringType,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+							},
+						},
+						"access": schema.StringAttribute{
+							Required: true,
+							Description: "One of: read, write, manage. The write access allows reading the secrets too. " +
+								"The manage access allows changing and deleting the secret manager and this policy, besides reading and writing all the secrets: " +
+								`its secret_names must be ["*"].`,
+							Validators: []validator.String{
+								stringvalidator.OneOf(model.SecretAccesses()...),
+							},
+						},
+						"secret_names": schema.ListAttribute{
+							Required:    true,
+							ElementType: types.StringType,
+							Description: `Patterns of the secret names the grant applies to, like "db_*".`,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+							},
+						},
+					},
+				},
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *secretManagerPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan secretManagerPolicyModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mgr, err := r.client.GetByID(plan.SecretManagerID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating secret manager policy resource",
+			fmt.Sprintf("Could not find secret manager for id %q: %s ", plan.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	if mgr.Policy != nil {
+		resp.Diagnostics.AddError(
+			"Error creating secret manager policy resource",
+			fmt.Sprintf("The secret manager %q has a policy already. Import it to manage it.", plan.SecretManagerID.ValueString()),
+		)
+		return
+	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessManage, "", model.AuditOperationCreatePolicy)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
+	mgr.Policy = plan.toModel()
+	if err := mgr.Policy.Validate(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("grants"), "Invalid secret manager policy", err.Error())
+		return
+	}
+	if err := r.client.Update(mgr); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating secret manager resource",
+			"Could not update secret manager, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *secretManagerPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state secretManagerPolicyModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mgr, err := r.client.GetByID(state.SecretManagerID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Secret manager",
+			fmt.Sprintf("Could not read secret manager with id %q: %s", state.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	if mgr.Policy == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	state.Grants = secretGrantsFromModel(mgr.Policy)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *secretManagerPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan secretManagerPolicyModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mgr, err := r.client.GetByID(plan.SecretManagerID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating secret manager policy resource",
+			fmt.Sprintf("Could not find secret manager for id %q: %s ", plan.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	// the current policy decides who can change it
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessManage, "", model.AuditOperationUpdatePolicy)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
+	mgr.Policy = plan.toModel()
+	if err := mgr.Policy.Validate(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("grants"), "Invalid secret manager policy", err.Error())
+		return
+	}
+	if err := r.client.Update(mgr); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Secret Manager",
+			fmt.Sprintf("Could not update SecretManager with id %q: %s", plan.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	if !access.succeeded(&resp.Diagnostics) {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete removes the policy, allowing every access to the secrets of the secret manager again.
+func (r *secretManagerPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state secretManagerPolicyModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mgr, err := r.client.GetByID(state.SecretManagerID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting secret manager policy resource",
+			fmt.Sprintf("Could not find secret manager for id %q: %s ", state.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	access := checkSecretAccess(&resp.Diagnostics, r.audit, mgr, r.principal, model.SecretAccessManage, "", model.AuditOperationDeletePolicy)
+	if access == nil {
+		return
+	}
+	defer access.done(&resp.Diagnostics)
+	mgr.Policy = nil
+	if err := r.client.Update(mgr); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Secret Manager",
+			fmt.Sprintf("Could not update SecretManager with id %q: %s", state.SecretManagerID.ValueString(), err),
+		)
+		return
+	}
+	access.succeeded(&resp.Diagnostics)
+}
+
+// ImportState expects the ID of the secret manager owning the policy.
+func (r *secretManagerPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("secret_manager_id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *secretManagerPolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	// typeSecretManager because the policy is stored inside the secret manager
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.SecretManager](c, typeSecretManager)
+	r.principal = principalFrom(req.ProviderData)
+	r.audit = auditLogFrom(req.ProviderData)
+}
+
+func (m secretManagerPolicyModel) toModel() *model.SecretManagerPolicy {
+	res := &model.SecretManagerPolicy{Grants: []model.SecretGrant{}}
+	for _, g := range m.Grants {
+		res.Grants = append(res.Grants, model.SecretGrant{
+			Principals:  stringValues(g.Principals),
+			Access:      model.SecretAccess(g.Access.ValueString()),
+			SecretNames: stringValues(g.SecretNames),
+		})
+	}
+	return res
+}
+
+func secretGrantsFromModel(policy *model.SecretManagerPolicy) []secretGrantModel {
+	res := []secretGrantModel{}
+	for _, g := range policy.Grants {
+		item := secretGrantModel{
+			Access: types.StringValue(string(g.Access)),
+		}
+		for _, p := range g.Principals {
+			item.Principals = append(item.Principals, types.StringValue(p))
+		}
+		for _, n := range g.SecretNames {
+			item.SecretNames = append(item.SecretNames, types.StringValue(n))
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+func stringValues(in []types.String) []string {
+	res := make([]string, 0, len(in))
+	for _, v := range in {
+		res = append(res, v.ValueString())
+	}
+	return res
+}
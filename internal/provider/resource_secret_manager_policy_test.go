@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccSecretManagerPolicyResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretManagerPolicyConfig(storagePath, "admin", "s3cr3t"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret_manager_policy.test", "grants.#", "2"),
+					resource.TestCheckResourceAttr("provs_secret_manager_policy.test", "grants.1.access", "read"),
+				),
+			},
+			{
+				ResourceName:                         "provs_secret_manager_policy.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccSecretManagerIDFunc("provs_secret_manager.test"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "secret_manager_id",
+			},
+			{
+				// ci can read the secret, so refreshing it works, but cannot write it
+				Config:      testAccSecretManagerPolicyConfig(storagePath, "ci", "changed"),
+				ExpectError: regexp.MustCompile(`The principal "ci" is not allowed to write the secret "db_password"`),
+			},
+			{
+				// ci cannot remove the policy restricting it
+				Config: fmt.Sprintf(`
+provider "provs" {
+  path      = %q
+  principal = "ci"
+}
+
+resource "provs_secret_manager" "test" {
+  name = "test"
+}
+`, storagePath),
+				ExpectError: regexp.MustCompile(`The principal "ci" is not allowed to manage the secret manager`),
+			},
+			{
+				Config:      testAccSecretManagerPolicyConfig(storagePath, "", "s3cr3t"),
+				ExpectError: regexp.MustCompile(`No principal is configured in the provider`),
+			},
+			{
+				// leave the state in a shape that the admin can destroy
+				Config: testAccSecretManagerPolicyConfig(storagePath, "admin", "s3cr3t"),
+			},
+		},
+	})
+}
+
+func testAccSecretManagerPolicyConfig(storagePath string, principal string, secret string) string {
+	return fmt.Sprintf(`
+provider "provs" {
+  path      = %q
+  principal = %q
+}
+
+resource "provs_secret_manager" "test" {
+  name = "test"
+}
+
+resource "provs_secret_manager_policy" "test" {
+  secret_manager_id = provs_secret_manager.test.id
+  grants = [
+    {
+      principals   = ["admin"]
+      access       = "manage"
+      secret_names = ["*"]
+    },
+    {
+      principals   = ["ci"]
+      access       = "read"
+      secret_names = ["db_*"]
+    },
+  ]
+}
+
+resource "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager_policy.test.secret_manager_id
+  secret_name       = "db_password"
+  secret            = %q
+}
+`, storagePath, principal, secret)
+}
+
+// testAccSecretManagerIDFunc returns the ID of the secret manager as import identifier.
+func testAccSecretManagerIDFunc(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", resourceName)
+		}
+		return rs.Primary.ID, nil
+	}
+}
+
+func TestSecretManagerPolicy_manage(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgrs := client.NewClient[*model.SecretManager](backend, typeSecretManager)
+	mgr, err := mgrs.Create(&model.SecretManager{Name: "test", Policy: &model.SecretManagerPolicy{Grants: []model.SecretGrant{
+		{Principals: []string{"admin"}, Access: model.SecretAccessManage, SecretNames: []string{"*"}},
+		{Principals: []string{"ci"}, Access: model.SecretAccessWrite, SecretNames: []string{"*"}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := map[string]tftypes.Value{
+		"id":                tftypes.NewValue(tftypes.String, mgr.ID),
+		"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+		"name":              tftypes.NewValue(tftypes.String, "test"),
+	}
+
+	// ci can write the secrets, but can neither remove the policy nor delete the secret manager
+	server, schemas, resp := testConfigureProvider(ctx, t, time.Now, map[string]tftypes.Value{
+		"path":      tftypes.NewValue(tftypes.String, storagePath),
+		"principal": tftypes.NewValue(tftypes.String, "ci"),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+	for _, typeName := range []string{"provs_secret_manager_policy", "provs_secret_manager"} {
+		if got := testDiagnosticSummaries(testApplyDestroy(ctx, t, server, schemas, typeName, id).Diagnostics); got != "Access denied to secret" {
+			t.Fatalf("expected the deletion of %s denied, got %s", typeName, got)
+		}
+	}
+	if got, err := mgrs.GetByID(mgr.ID); err != nil || got.Policy == nil {
+		t.Fatalf("expected the policy kept, got %v (%v)", got, err)
+	}
+	entries, err := audit.Recent(backend, audit.Filter{Principal: "ci"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Outcome != model.AuditOutcomeDenied || entries[0].Operation != model.AuditOperationDelete ||
+		entries[1].Operation != model.AuditOperationDeletePolicy {
+		t.Fatalf("expected the denials recorded, got %+v", entries)
+	}
+
+	// admin can
+	server, schemas, resp = testConfigureProvider(ctx, t, time.Now, map[string]tftypes.Value{
+		"path":      tftypes.NewValue(tftypes.String, storagePath),
+		"principal": tftypes.NewValue(tftypes.String, "admin"),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+	testNoDiagnostics(t, testApplyDestroy(ctx, t, server, schemas, "provs_secret_manager_policy", id).Diagnostics)
+	if got, err := mgrs.GetByID(mgr.ID); err != nil || got.Policy != nil {
+		t.Fatalf("expected the policy removed, got %v (%v)", got, err)
+	}
+}
+
+// testApplyDestroy destroys the resource with the given state, the attributes not given being null
+func testApplyDestroy(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, typeName string, state map[string]tftypes.Value) *tfprotov6.ApplyResourceChangeResponse {
+	t.Helper()
+	schema := schemas.ResourceSchemas[typeName]
+	attrs := map[string]tftypes.Value{}
+	for name := range schema.ValueType().(tftypes.Object).AttributeTypes {
+		if v, ok := state[name]; ok {
+			attrs[name] = v
+		}
+	}
+	planned, err := tfprotov6.NewDynamicValue(schema.ValueType(), tftypes.NewValue(schema.ValueType(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     typeName,
+		PriorState:   testDynamicValue(t, schema, attrs),
+		PlannedState: &planned,
+		Config:       &planned,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSecretManagerResource_importByName(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretManagerResourceConfig(storagePath, "payments"),
+			},
+			{
+				ResourceName:      "provs_secret_manager.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "provs_secret_manager.test",
+				ImportState:       true,
+				ImportStateId:     "name:payments",
+				ImportStateVerify: true,
+			},
+			{
+				// renaming must free the old name
+				Config: testAccSecretManagerResourceConfig(storagePath, "billing"),
+			},
+			{
+				ResourceName:      "provs_secret_manager.test",
+				ImportState:       true,
+				ImportStateId:     "name:billing",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "provs_secret_manager.test",
+				ImportState:   true,
+				ImportStateId: "name:payments",
+				ExpectError:   regexp.MustCompile(`Could not find the secret manager named "payments"`),
+			},
+		},
+	})
+}
+
+func TestAccSecretManagerResource_nameConflict(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretManagerResourceConfig(storagePath, "payments") + `
+resource "provs_secret_manager" "duplicate" {
+  name       = "payments"
+  depends_on = [provs_secret_manager.test]
+}
+`,
+				ExpectError: regexp.MustCompile(`Secret manager name already in use`),
+			},
+		},
+	})
+}
+
+func TestSecretManagerResource_renameFailed(t *testing.T) {
+	ctx := context.Background()
+	backend, err := filesystem.NewFsClient(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(&model.SecretManager{Name: "payments"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := newSecretManagerNameIndex(backend)
+	if err := names.Reserve("payments", mgr.ID); err != nil {
+		t.Fatal(err)
+	}
+	storeServer := httptest.NewServer(remote.NewHandler(&testUpdateFailingBackend{BackendClient: backend}, testStoreToken))
+	defer storeServer.Close()
+	server, schemas, configResp := testConfigureProvider(ctx, t, time.Now, map[string]tftypes.Value{
+		"path": tftypes.NewValue(tftypes.String, t.TempDir()),
+		"stores": testStoresValue(t, map[string]map[string]string{
+			"shared": {"type": "http", "url": storeServer.URL, "token": testStoreToken},
+		}),
+	})
+	testNoDiagnostics(t, configResp.Diagnostics)
+
+	schema := schemas.ResourceSchemas["provs_secret_manager"]
+	state := map[string]tftypes.Value{
+		"id":                               tftypes.NewValue(tftypes.String, mgr.ID),
+		"name":                             tftypes.NewValue(tftypes.String, "payments"),
+		"version_retention":                tftypes.NewValue(tftypes.Number, model.DefaultSecretVersionRetention),
+		"deny_rotation_with_active_leases": tftypes.NewValue(tftypes.Bool, false),
+		"store":                            tftypes.NewValue(tftypes.String, "shared"),
+	}
+	planned := map[string]tftypes.Value{}
+	for name, value := range state {
+		planned[name] = value
+	}
+	planned["name"] = tftypes.NewValue(tftypes.String, "billing")
+	resp, err := server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "provs_secret_manager",
+		PriorState:   testDynamicValue(t, schema, state),
+		PlannedState: testDynamicValue(t, schema, planned),
+		Config: testDynamicValue(t, schema, map[string]tftypes.Value{
+			"name":  planned["name"],
+			"store": planned["store"],
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testDiagnosticSummaries(resp.Diagnostics); got != "Error Updating SecretManager" {
+		t.Fatalf("expected the update failed, got %s", got)
+	}
+
+	// the new name is free again, the old one still taken
+	if err := names.Reserve("billing", "other"); err != nil {
+		t.Fatalf("expected the new name released, got %v", err)
+	}
+	if id, err := names.Lookup("payments"); err != nil || id != mgr.ID {
+		t.Fatalf("expected the old name kept for %s, got %s (%v)", mgr.ID, id, err)
+	}
+}
+
+// testUpdateFailingBackend fails the updates of the secret managers
+type testUpdateFailingBackend struct {
+	client.BackendClient
+}
+
+func (b *testUpdateFailingBackend) Update(resType string, resId string, newContent io.Reader) error {
+	if resType == typeSecretManager {
+		return errors.New("disk full")
+	}
+	return b.BackendClient.Update(resType, resId, newContent)
+}
+
+func testAccSecretManagerResourceConfig(storagePath string, name string) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_secret_manager" "test" {
+  name = %q
+}
+`, name)
+}
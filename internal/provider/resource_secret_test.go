@@ -0,0 +1,567 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSecretResource_import(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "s3cr3t"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "secret", "s3cr3t"),
+					resource.TestCheckResourceAttr("provs_secret.test", "has_secret_wo", "false"),
+				),
+			},
+			{
+				ResourceName:                         "provs_secret.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccSecretImportStateIdFunc("provs_secret.test"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "secret_name",
+			},
+		},
+	})
+}
+
+func TestAccSecretResource_importWriteOnly(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `
+  secret_wo         = "s3cr3t"
+  secret_wo_version = 1`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("provs_secret.test", "secret"),
+					resource.TestCheckResourceAttr("provs_secret.test", "has_secret_wo", "true"),
+				),
+			},
+			{
+				ResourceName:                         "provs_secret.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccSecretImportStateIdFunc("provs_secret.test"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "secret_name",
+				// The version of a write-only value is known only by the configuration
+				ImportStateVerifyIgnore: []string{"secret_wo_version"},
+			},
+		},
+	})
+}
+
+func TestAccSecretResource_importRef(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// the fingerprint of the write-only value is kept in state, not the value
+				Config: testAccSecretResourceConfig(storagePath, `
+  secret_wo         = "s3cr3t"
+  secret_wo_version = 1`) + `
+output "ref" {
+  value = provider::provs::secret_ref(provs_secret.test.secret_manager_id, provs_secret.test.secret_name)
+}
+
+output "name" {
+  value = provider::provs::parse_secret_ref(provider::provs::secret_ref(provs_secret_manager.test.id, "db_password")).name
+}
+
+output "fingerprint" {
+  value = provider::provs::hash_secret("s3cr3t", "sha256", "")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("name", "db_password"),
+					resource.TestCheckOutput("fingerprint", "4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"),
+					resource.TestMatchOutput("ref", regexp.MustCompile(`^provs://[0-9a-f-]+/db_password$`)),
+				),
+			},
+			{
+				ResourceName:                         "provs_secret.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccSecretImportStateRefFunc("provs_secret.test"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "secret_name",
+				ImportStateVerifyIgnore:              []string{"secret_wo_version"},
+			},
+		},
+	})
+}
+
+func TestAccSecretResource_importInvalidID(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "s3cr3t"`),
+			},
+			{
+				ResourceName:  "provs_secret.test",
+				ImportState:   true,
+				ImportStateId: "missing-separator",
+				ExpectError:   regexp.MustCompile(`Expected import identifier with format`),
+			},
+		},
+	})
+}
+
+func TestAccSecretResource_rotation(t *testing.T) {
+	storagePath := t.TempDir()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	rotation := `
+  rotation = {
+    interval  = "24h"
+    generator = {
+      type   = "password"
+      length = 16
+    }
+  }`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"provs": testAccProtoV6ProviderFactory(&provsProvider{version: "test", now: clock}),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, rotation),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("provs_secret.test", "secret"),
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "1"),
+					resource.TestCheckResourceAttr("provs_secret.test", "next_rotation_at", "2025-01-02T00:00:00Z"),
+				),
+			},
+			{
+				// Not due yet
+				PreConfig: func() { now = now.Add(23 * time.Hour) },
+				Config:    testAccSecretResourceConfig(storagePath, rotation),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				PreConfig: func() { now = now.Add(2 * time.Hour) },
+				Config:    testAccSecretResourceConfig(storagePath, rotation),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("provs_secret.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "2"),
+					resource.TestCheckResourceAttr("provs_secret.test", "next_rotation_at", "2025-01-03T01:00:00Z"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccSecretResource_writeOnly switches between secret and secret_wo and checks when the value is written.
+func TestAccSecretResource_writeOnly(t *testing.T) {
+	storagePath := t.TempDir()
+	writeOnly := func(value string, version int) string {
+		return fmt.Sprintf(`
+  secret_wo         = %q
+  secret_wo_version = %d`, value, version)
+	}
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "v1"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "secret", "v1"),
+					resource.TestCheckResourceAttr("provs_secret.test", "has_secret_wo", "false"),
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "1"),
+					testAccCheckSecretValue(storagePath, "db_password", "v1"),
+				),
+			},
+			{
+				Config: testAccSecretResourceConfig(storagePath, writeOnly("v2", 1)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("provs_secret.test", "secret"),
+					resource.TestCheckResourceAttr("provs_secret.test", "has_secret_wo", "true"),
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "2"),
+					testAccCheckSecretValue(storagePath, "db_password", "v2"),
+				),
+			},
+			{
+				// Without a change of the version, the new value is not written
+				Config: testAccSecretResourceConfig(storagePath, writeOnly("v3", 1)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: testAccCheckSecretValue(storagePath, "db_password", "v2"),
+			},
+			{
+				Config: testAccSecretResourceConfig(storagePath, writeOnly("v3", 2)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "3"),
+					testAccCheckSecretValue(storagePath, "db_password", "v3"),
+				),
+			},
+			{
+				// A change outside of Terraform is detected from the new version and the configured value is written back
+				PreConfig: func() { testAccPutSecret(t, storagePath, "db_password", "changed") },
+				Config:    testAccSecretResourceConfig(storagePath, writeOnly("v3", 2)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("provs_secret.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "version_id", "5"),
+					testAccCheckSecretValue(storagePath, "db_password", "v3"),
+				),
+			},
+			{
+				Config: testAccSecretResourceConfig(storagePath, `secret = "v4"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "secret", "v4"),
+					resource.TestCheckResourceAttr("provs_secret.test", "has_secret_wo", "false"),
+					resource.TestCheckNoResourceAttr("provs_secret.test", "secret_wo_version"),
+					testAccCheckSecretValue(storagePath, "db_password", "v4"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSecretResourceConfig(storagePath string, secretAttrs string) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_secret_manager" "test" {
+  name = "test"
+}
+
+resource "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager.test.id
+  secret_name       = "db_password"
+  %s
+}
+`, secretAttrs)
+}
+
+// testAccSecretImportStateIdFunc builds the <secret_manager_id>/<secret_name> import identifier of a provs_secret.
+func testAccSecretImportStateIdFunc(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", resourceName)
+		}
+		return rs.Primary.Attributes["secret_manager_id"] + "/" + rs.Primary.Attributes["secret_name"], nil
+	}
+}
+
+// testAccSecretImportStateRefFunc builds the secret reference of a provs_secret, as import identifier.
+func testAccSecretImportStateRefFunc(resourceName string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource not found: %s", resourceName)
+		}
+		return model.SecretRef{
+			SecretManagerID: rs.Primary.Attributes["secret_manager_id"],
+			SecretName:      rs.Primary.Attributes["secret_name"],
+		}.String(), nil
+	}
+}
+
+// testAccSecretManagers returns the client reading the secret managers from the storage of the provider.
+func testAccSecretManagers(storagePath string) (client.Client[*model.SecretManager], error) {
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClient[*model.SecretManager](backend, typeSecretManager), nil
+}
+
+// testAccCheckSecretValue checks the current value of the secret directly in the storage.
+func testAccCheckSecretValue(storagePath string, name string, expected string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		c, err := testAccSecretManagers(storagePath)
+		if err != nil {
+			return err
+		}
+		mgrs, err := c.GetAll()
+		if err != nil {
+			return err
+		}
+		for _, mgr := range mgrs {
+			if secret, ok := mgr.Secrets[name]; ok && secret.Current() != nil {
+				if got := secret.Current().Value; got != expected {
+					return fmt.Errorf("expected secret %q to be %q, got %q", name, expected, got)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("secret %q not found", name)
+	}
+}
+
+// testAccPutSecret writes a new version of the secret bypassing Terraform.
+func testAccPutSecret(t *testing.T, storagePath string, name string, value string) {
+	t.Helper()
+	c, err := testAccSecretManagers(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgrs, err := c.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mgr := range mgrs {
+		if secret, ok := mgr.Secrets[name]; ok {
+			mgr.PutSecret(name, value, secret.WriteOnly, time.Now())
+			if err := c.Update(mgr); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("secret %q not found", name)
+}
+
+func TestSecretResource_readLegacyWriteOnly(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stored before the secret managers recorded the write-only secrets
+	if err := backend.CreateWithId(typeSecretManager, "legacy", strings.NewReader(`{"id":"legacy","name":"legacy","secrets":{"db_password":"s3cr3t"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	state := testReadResource(ctx, t, server, schemas, "provs_secret", map[string]tftypes.Value{
+		"secret_manager_id": tftypes.NewValue(tftypes.String, "legacy"),
+		"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+		"has_secret_wo":     tftypes.NewValue(tftypes.Bool, true),
+	})
+	if !state["secret"].IsNull() {
+		t.Fatalf("expected the write-only secret kept out of the state, got %s", state["secret"])
+	}
+	if !state["has_secret_wo"].Equal(tftypes.NewValue(tftypes.Bool, true)) {
+		t.Fatalf("expected has_secret_wo kept, got %s", state["has_secret_wo"])
+	}
+
+	// the refresh writes nothing, the creation time of the legacy version stays unknown
+	mgr, err := client.NewClient[*model.SecretManager](backend, typeSecretManager).GetByID("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur := mgr.Secrets["db_password"].Current(); !cur.CreatedAt.IsZero() {
+		t.Fatalf("expected no creation time for the legacy version, got %s", cur.CreatedAt)
+	}
+}
+
+func TestSecretResource_readWriteOnlyDrift(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", "s3cr3t", true, time.Now())
+	if mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr); err != nil {
+		t.Fatal(err)
+	}
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	read := func(private map[string]string) map[string]string {
+		t.Helper()
+		raw := map[string][]byte{}
+		for key, value := range private {
+			raw[key] = []byte(value)
+		}
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+			TypeName: "provs_secret",
+			CurrentState: testDynamicValue(t, schemas.ResourceSchemas["provs_secret"], map[string]tftypes.Value{
+				"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+				"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+				"has_secret_wo":     tftypes.NewValue(tftypes.Bool, true),
+			}),
+			Private: encoded,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summaries := testDiagnosticSummaries(resp.Diagnostics); summaries != "" && summaries != "Write-only secret changed outside of Terraform" {
+			t.Fatalf("unexpected diagnostics: %s", summaries)
+		}
+		raw = map[string][]byte{}
+		if err := json.Unmarshal(resp.Private, &raw); err != nil {
+			t.Fatal(err)
+		}
+		res := map[string]string{}
+		for key, value := range raw {
+			res[key] = string(value)
+		}
+		return res
+	}
+
+	// the hash kept by the previous versions of the provider is dropped
+	private := read(map[string]string{
+		"secret_wo_fingerprint": `{"sha256":"4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"}`,
+		"secret_wo_write":       `{"version_id":"1"}`,
+	})
+	if len(private) != 1 || private["secret_wo_write"] != `{"version_id":"1"}` {
+		t.Fatalf("expected only the written version kept, got %v", private)
+	}
+
+	testAccPutSecret(t, storagePath, "db_password", "changed")
+	if private = read(private); private["secret_wo_write"] != `{"version_id":"1","drifted":true}` {
+		t.Fatalf("expected the drift recorded, got %v", private)
+	}
+}
+
+func TestSecretResource_auditOutcome(t *testing.T) {
+	ctx := context.Background()
+	storagePath := t.TempDir()
+	backend, err := filesystem.NewFsClient(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", "s3cr3t", false, time.Now())
+	if mgr, err = client.NewClient[*model.SecretManager](backend, typeSecretManager).Create(mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas := testProtocolServer(ctx, t, storagePath, time.Now)
+	testReadResource(ctx, t, server, schemas, "provs_secret", map[string]tftypes.Value{
+		"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+		"secret_name":       tftypes.NewValue(tftypes.String, "db_password"),
+	})
+	// the read of a missing secret is recorded as failed
+	resp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName: "provs_secret",
+		CurrentState: testDynamicValue(t, schemas.ResourceSchemas["provs_secret"], map[string]tftypes.Value{
+			"secret_manager_id": tftypes.NewValue(tftypes.String, mgr.ID),
+			"secret_name":       tftypes.NewValue(tftypes.String, "missing"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testDiagnosticSummaries(resp.Diagnostics); got != "Error Reading secret from secret manager" {
+		t.Fatalf("expected the secret missing, got %s", got)
+	}
+
+	entries, err := audit.Recent(backend, audit.Filter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.SecretName+":"+string(e.Outcome))
+	}
+	if strings.Join(got, ",") != "missing:failed,db_password:" {
+		t.Fatalf("expected the failed and the successful reads, got %s", got)
+	}
+}
+
+// testReadResource refreshes the resource with the given state, the attributes not given being null, and returns
+// the attributes of the new state
+func testReadResource(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, typeName string, attrs map[string]tftypes.Value) map[string]tftypes.Value {
+	t.Helper()
+	schema := schemas.ResourceSchemas[typeName]
+	resp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: testDynamicValue(t, schema, attrs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, resp.Diagnostics)
+	state, err := resp.NewState.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]tftypes.Value
+	if err := state.As(&res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// TestSecretRotation_passwordCharsets maps the charsets of a password generator to the stored rotation and back.
+func TestSecretRotation_passwordCharsets(t *testing.T) {
+	rotation := &secretRotationModel{
+		Interval: types.StringValue("24h"),
+		Generator: secretGeneratorModel{
+			Type:            types.StringValue("password"),
+			Length:          types.Int64Value(24),
+			Prefix:          types.StringValue("db"),
+			Upper:           types.BoolValue(true),
+			Lower:           types.BoolValue(true),
+			Numeric:         types.BoolValue(true),
+			Special:         types.BoolValue(true),
+			MinUpper:        types.Int64Value(0),
+			MinLower:        types.Int64Value(0),
+			MinNumeric:      types.Int64Value(0),
+			MinSpecial:      types.Int64Value(2),
+			Charset:         types.StringValue("abc"),
+			OverrideSpecial: types.StringValue("#"),
+			Exclude:         types.StringValue("c"),
+		},
+	}
+	stored, value, err := rotation.rotate(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^db-[ab#]{24}$`).MatchString(value) || strings.Count(value, "#") < 2 {
+		t.Fatalf("unexpected generated value %q", value)
+	}
+	if got := secretRotationFromModel(stored, rotation); *got != *rotation {
+		t.Fatalf("expected the rotation %+v, got %+v", *rotation, *got)
+	}
+}
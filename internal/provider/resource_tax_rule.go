@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &taxRuleResource{}
+	_ resource.ResourceWithConfigure   = &taxRuleResource{}
+	_ resource.ResourceWithImportState = &taxRuleResource{}
+)
+
+// taxRegionRegex matches the regions, used as storage keys
+var taxRegionRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// taxRuleResourceModel maps the resource schema data.
+type taxRuleResourceModel struct {
+	ID               types.String   `tfsdk:"id"`
+	Region           types.String   `tfsdk:"region"`
+	Rate             types.Float64  `tfsdk:"rate"`
+	Inclusive        types.Bool     `tfsdk:"inclusive"`
+	ExemptCategories []types.String `tfsdk:"exempt_categories"`
+	Store            types.String   `tfsdk:"store"`
+}
+
+// NewResourceTaxRule is a helper function to simplify the provider implementation.
+func NewResourceTaxRule() resource.Resource {
+	return &taxRuleResource{}
+}
+
+// taxRuleResource manages the tax of the orders of a region.
+type taxRuleResource struct {
+	client       client.Client[*model.TaxRule]
+	orders       client.Client[*model.Order]
+	providerData any
+}
+
+// Metadata returns the resource type name.
+func (r *taxRuleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + typeTaxRule
+}
+
+// Schema defines the schema for the resource.
+func (r *taxRuleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The tax of the orders of a region, see the region of provs_order. The orders are taxed when they are " +
+			"placed or updated. It cannot be deleted while orders use it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The region.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"region": schema.StringAttribute{
+				Required:    true,
+				Description: "The region, like \"FR\" or \"US-CA\". A region has at most one tax rule.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(taxRegionRegex, "value must only contain letters, digits, - and _"),
+				},
+			},
+			"rate": schema.Float64Attribute{
+				Required:    true,
+				Description: "The tax rate, between 0 and 1. 0.085 == 8.5%",
+				Validators: []validator.Float64{
+					float64validator.Between(0, 1),
+				},
+			},
+			"inclusive": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Whether the prices of the coffees include the tax. Otherwise the tax is added to the total of the orders. Defaults to false.",
+			},
+			"exempt_categories": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The categories of the coffees that are not taxed.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"store": resourceStoreSchema(),
+		},
+	}
+}
+
+// Create a new resource.
+func (r *taxRuleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan taxRuleResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rule := plan.toModel()
+	// the creation fails when the rule exists, even if created concurrently
+	if _, err := r.client.Create(rule); client.IsExist(err) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("region"),
+			"Tax rule already exists",
+			fmt.Sprintf("The region %s already has a tax rule. Import it instead.", rule.ID),
+		)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating tax rule",
+			"Could not create tax rule, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(rule.ID)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *taxRuleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state taxRuleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rule, err := r.client.GetByID(state.ID.ValueString())
+	if client.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Tax Rule",
+			fmt.Sprintf("Could not read tax rule ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+	state = taxRuleFromModel(rule, state.Store)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update changes the tax rule. The orders already taxed keep their tax until they are updated.
+func (r *taxRuleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan taxRuleResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, plan.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.Update(plan.toModel()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Tax Rule",
+			fmt.Sprintf("Could not update tax rule ID %s: %s", plan.ID.ValueString(), err),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the tax rule, unless orders use it.
+func (r *taxRuleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state taxRuleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, r.providerData, state.Store)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ordersMu.Lock()
+	defer ordersMu.Unlock()
+	orders, err := r.orders.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Unable to Read Orders",
+			err.Error(),
+		)
+		return
+	}
+	var ids []string
+	for _, o := range orders {
+		if o.Region == state.ID.ValueString() {
+			ids = append(ids, o.ID)
+		}
+	}
+	if len(ids) > 0 {
+		resp.Diagnostics.AddError(
+			"Tax rule in use",
+			fmt.Sprintf("The tax rule of the region %s cannot be deleted while orders use it: %s. Delete these orders or change their region first.",
+				state.ID.ValueString(), strings.Join(ids, ", ")),
+		)
+		return
+	}
+
+	if err := r.client.Delete(state.ID.ValueString()); err != nil && !client.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Tax Rule",
+			fmt.Sprintf("Could not delete tax rule ID %s: %s", state.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the tax rule of the region with the given ID.
+func (r *taxRuleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID, and the store of the objects of the named stores, and save to id attribute
+	importStatePassthroughStoreID(ctx, r, r.providerData, path.Root("id"), req, resp)
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *taxRuleResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	c, ok := req.ProviderData.(client.BackendClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected client.BackendClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.providerData = req.ProviderData
+	r.client = client.NewClient[*model.TaxRule](c, typeTaxRule)
+	r.orders = client.NewClient[*model.Order](c, typeOrder)
+}
+
+func (m taxRuleResourceModel) toModel() *model.TaxRule {
+	res := &model.TaxRule{
+		ID:        m.Region.ValueString(),
+		Rate:      m.Rate.ValueFloat64(),
+		Inclusive: m.Inclusive.ValueBool(),
+	}
+	for _, category := range m.ExemptCategories {
+		res.ExemptCategories = append(res.ExemptCategories, category.ValueString())
+	}
+	return res
+}
+
+func taxRuleFromModel(rule *model.TaxRule, store types.String) taxRuleResourceModel {
+	res := taxRuleResourceModel{
+		ID:        types.StringValue(rule.ID),
+		Region:    types.StringValue(rule.ID),
+		Rate:      types.Float64Value(rule.Rate),
+		Inclusive: types.BoolValue(rule.Inclusive),
+		Store:     store,
+	}
+	for _, category := range rule.ExemptCategories {
+		res.ExemptCategories = append(res.ExemptCategories, types.StringValue(category))
+	}
+	return res
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestAccTaxRuleResource(t *testing.T) {
+	storagePath := t.TempDir()
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTaxRuleOrderConfig(storagePath, false, 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_tax_rule.fr", "id", "FR"),
+					resource.TestCheckResourceAttr("provs_tax_rule.fr", "inclusive", "false"),
+					resource.TestCheckResourceAttr("provs_order.test", "subtotal", "10"),
+					resource.TestCheckResourceAttr("provs_order.test", "tax", "1.6"),
+					resource.TestCheckResourceAttr("provs_order.test", "total", "11.6"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("provs_order.test", tfjsonpath.New("tax_breakdown"), knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"coffee_id": knownvalue.NotNull(),
+							"category":  knownvalue.StringExact("espresso"),
+							"amount":    knownvalue.Float64Exact(8),
+							"exempt":    knownvalue.Bool(false),
+							"rate":      knownvalue.Float64Exact(0.2),
+							"tax":       knownvalue.Float64Exact(1.6),
+						}),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"coffee_id": knownvalue.NotNull(),
+							"category":  knownvalue.StringExact("tea"),
+							"amount":    knownvalue.Float64Exact(2),
+							"exempt":    knownvalue.Bool(true),
+							"rate":      knownvalue.Float64Exact(0),
+							"tax":       knownvalue.Float64Exact(0),
+						}),
+					})),
+					statecheck.ExpectKnownOutputValue("fr_price", knownvalue.Float64Exact(4.8)),
+					// exempt, like in the order
+					statecheck.ExpectKnownOutputValue("fr_tea_price", knownvalue.Float64Exact(2)),
+				},
+			},
+			{
+				ResourceName:      "provs_tax_rule.fr",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// the order is taxed again once updated, the prices now including the tax
+				Config: testAccTaxRuleOrderConfig(storagePath, true, 5),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_order.test", "tax", "3.33"),
+					resource.TestCheckResourceAttr("provs_order.test", "total", "22"),
+					resource.TestCheckOutput("fr_price", "4"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(storagePath) + `
+resource "provs_tax_rule" "fr" {
+  region = "FR"
+  rate   = 1.2
+}
+`,
+				ExpectError: regexp.MustCompile(`rate`),
+			},
+		},
+	})
+}
+
+// testAccTaxRuleOrderConfig returns an order of espressos and an exempt tea taxed by the rule of FR
+func testAccTaxRuleOrderConfig(storagePath string, inclusive bool, espressos int) string {
+	return testAccProviderConfig(storagePath) + fmt.Sprintf(`
+resource "provs_coffee" "espresso" {
+  name     = "Espresso"
+  category = "espresso"
+  price    = 4
+}
+
+resource "provs_coffee" "tea" {
+  name     = "Tea"
+  category = "tea"
+  price    = 2
+}
+
+resource "provs_tax_rule" "fr" {
+  region            = "FR"
+  rate              = 0.2
+  inclusive         = %t
+  exempt_categories = ["tea"]
+}
+
+resource "provs_order" "test" {
+  region = provs_tax_rule.fr.region
+  items = [{
+    coffee = {
+      id = provs_coffee.espresso.id
+    }
+    quantity = %d
+  }, {
+    coffee = {
+      id = provs_coffee.tea.id
+    }
+    quantity = 1
+  }]
+}
+
+data "provs_tax_rules" "all" {
+  depends_on = [provs_tax_rule.fr]
+}
+
+output "fr_price" {
+  value = provider::provs::compute_region_tax(4, provs_coffee.espresso.category, "FR", data.provs_tax_rules.all.rules)
+}
+
+output "fr_tea_price" {
+  value = provider::provs::compute_region_tax(2, provs_coffee.tea.category, "FR", data.provs_tax_rules.all.rules)
+}
+`, inclusive, espressos)
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"fmt"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// checkSecretAccess returns the access when the policy of the secret manager allows the principal the given access to
+// the secret, or to the secret manager itself for the manage access. Its outcome is recorded in the audit log once
+// known: done is deferred right away. Otherwise, the denied operation is recorded in the audit log, an error
+// diagnostic is added and nil is returned.
+func checkSecretAccess(diags *diag.Diagnostics, log *audit.Log, mgr *model.SecretManager, principal string, access model.SecretAccess, secretName string, op model.AuditOperation) *secretAccess {
+	if mgr.Policy.Allows(principal, access, secretName) {
+		return newSecretAccess(log, principal, mgr.ID, secretName, op)
+	}
+	if err := log.RecordDenial(principal, mgr.ID, secretName, op); err != nil {
+		diags.AddError(
+			"Error recording the access to secret",
+			fmt.Sprintf("Could not record the denied %s of secret manager %q in the audit log: %s", op, mgr.ID, err),
+		)
+	}
+	who := fmt.Sprintf("The principal %q is", principal)
+	if principal == "" {
+		who = "No principal is configured in the provider, so the access is"
+	}
+	what := fmt.Sprintf("%s the secret %q of secret manager %q", access, secretName, mgr.ID)
+	if access == model.SecretAccessManage {
+		what = fmt.Sprintf("manage the secret manager %q and its policy", mgr.ID)
+	}
+	diags.AddError(
+		"Access denied to secret",
+		fmt.Sprintf(
+			"%s not allowed to %s. "+
+				"Set the principal in the provider configuration or in the PROVS_PRINCIPAL environment variable, "+
+				"and grant it access with a provs_secret_manager_policy.",
+			who, what,
+		),
+	)
+	return nil
+}
+
+// secretAccess is an access to a secret, or to a secret manager without secret name, to be recorded in the audit log
+// with its outcome.
+type secretAccess struct {
+	log             *audit.Log
+	principal       string
+	secretManagerID string
+	secretName      string
+	op              model.AuditOperation
+	recorded        bool
+}
+
+func newSecretAccess(log *audit.Log, principal string, secretManagerID string, secretName string, op model.AuditOperation) *secretAccess {
+	return &secretAccess{log: log, principal: principal, secretManagerID: secretManagerID, secretName: secretName, op: op}
+}
+
+// succeeded records the access as performed. The secret must not be returned when it returns false, since every
+// access has to be recorded.
+func (a *secretAccess) succeeded(diags *diag.Diagnostics) bool {
+	a.recorded = true
+	return a.addRecordError(diags, a.log.Record(a.principal, a.secretManagerID, a.secretName, a.op))
+}
+
+// done records the access as failed, unless it was recorded as performed already.
+func (a *secretAccess) done(diags *diag.Diagnostics) {
+	if a.recorded {
+		return
+	}
+	a.recorded = true
+	a.addRecordError(diags, a.log.RecordFailure(a.principal, a.secretManagerID, a.secretName, a.op))
+}
+
+func (a *secretAccess) addRecordError(diags *diag.Diagnostics, err error) bool {
+	if err != nil {
+		diags.AddError(
+			"Error recording the access to secret",
+			fmt.Sprintf("Could not record the %s of the secret %q of secret manager %q in the audit log: %s", a.op, a.secretName, a.secretManagerID, err),
+		)
+		return false
+	}
+	return true
+}
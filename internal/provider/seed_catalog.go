@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+)
+
+// seedCatalog fills an empty catalog with demo coffees and their ingredients. A catalog with any coffee is left untouched.
+func seedCatalog(backend client.BackendClient) error {
+	// the coffees reference the ingredients seeded with them
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	c := client.NewClient[*model.Coffee](backend, typeCoffees)
+	coffees, err := c.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		return err
+	}
+	if len(coffees) > 0 {
+		return nil
+	}
+	ingredients := client.NewClient[*model.Ingredient](backend, typeIngredient)
+	for j := 0; j < 9; j++ {
+		ingredient := &model.Ingredient{
+			ID:   strconv.Itoa(j),
+			Name: fmt.Sprintf("Ingredient name %d", j),
+		}
+		// the ingredients can be there already, kept from a catalog emptied since
+		_, err := ingredients.GetByID(ingredient.ID)
+		if err == nil {
+			continue
+		}
+		if !client.IsNotFound(err) {
+			return err
+		}
+		if _, err := ingredients.Create(ingredient); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < 10; i++ {
+		var items []model.CoffeeIngredient
+		for j := 0; j < i; j++ {
+			items = append(items, model.CoffeeIngredient{
+				IngredientID: strconv.Itoa(j),
+				Quantity:     i * j,
+			})
+		}
+		if _, err := c.Create(&model.Coffee{
+			ID:          strconv.Itoa(i),
+			Name:        fmt.Sprintf("Name %d", i),
+			Teaser:      fmt.Sprintf("Teaser %d", i),
+			Description: fmt.Sprintf("Description %d", i),
+			Price:       1.1,
+			Ingredient:  items,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
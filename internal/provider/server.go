@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// redactedSecret replaces the secret values in the diagnostics
+const redactedSecret = "***"
+
+// NewProtocol6Server returns the protocol server of the provider. Every request is handled with the secret log
+// fields masked and the log level of the provider configuration, and the values of the sensitive attributes are
+// redacted from the diagnostics of its response.
+func NewProtocol6Server(version string) func() tfprotov6.ProviderServer {
+	return newProtocol6Server(New(version)())
+}
+
+func newProtocol6Server(p provider.Provider) func() tfprotov6.ProviderServer {
+	server := providerserver.NewProtocol6(p)
+	logLevel := func() string { return "" }
+	if l, ok := p.(logLeveler); ok {
+		logLevel = l.configuredLogLevel
+	}
+	return func() tfprotov6.ProviderServer {
+		return &secretsServer{ProviderServer: server(), logLevel: logLevel}
+	}
+}
+
+// logLeveler is implemented by the providers whose configuration sets the level of their logs
+type logLeveler interface {
+	configuredLogLevel() string
+}
+
+// secretsServer masks the secrets of the requests of the server it embeds. The requests carrying no values, like
+// GetProviderSchema, are passed as is.
+type secretsServer struct {
+	tfprotov6.ProviderServer
+	// logLevel returns the level of the logs of the provider code, empty for the level of the root provider logger
+	logLevel func() string
+
+	schemasOnce sync.Once
+	schemas     *tfprotov6.GetProviderSchemaResponse
+}
+
+func (s *secretsServer) ValidateProviderConfig(ctx context.Context, req *tfprotov6.ValidateProviderConfigRequest) (*tfprotov6.ValidateProviderConfigResponse, error) {
+	schema := s.providerSchema(ctx)
+	resp, err := s.ProviderServer.ValidateProviderConfig(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ConfigureProvider(ctx context.Context, req *tfprotov6.ConfigureProviderRequest) (*tfprotov6.ConfigureProviderResponse, error) {
+	schema := s.providerSchema(ctx)
+	resp, err := s.ProviderServer.ConfigureProvider(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ValidateResourceConfig(ctx context.Context, req *tfprotov6.ValidateResourceConfigRequest) (*tfprotov6.ValidateResourceConfigResponse, error) {
+	schema := s.resourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ValidateResourceConfig(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) UpgradeResourceState(ctx context.Context, req *tfprotov6.UpgradeResourceStateRequest) (*tfprotov6.UpgradeResourceStateResponse, error) {
+	schema := s.resourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.UpgradeResourceState(s.requestContext(ctx, schema), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, resp.UpgradedState))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ReadResource(ctx context.Context, req *tfprotov6.ReadResourceRequest) (*tfprotov6.ReadResourceResponse, error) {
+	schema := s.resourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ReadResource(s.requestContext(ctx, schema, req.CurrentState), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.CurrentState, resp.NewState))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) PlanResourceChange(ctx context.Context, req *tfprotov6.PlanResourceChangeRequest) (*tfprotov6.PlanResourceChangeResponse, error) {
+	schema := s.resourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.PlanResourceChange(s.requestContext(ctx, schema, req.Config, req.PriorState, req.ProposedNewState), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config, req.PriorState, req.ProposedNewState, resp.PlannedState))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	schema := s.resourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ApplyResourceChange(s.requestContext(ctx, schema, req.Config, req.PriorState, req.PlannedState), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config, req.PriorState, req.PlannedState, resp.NewState))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ImportResourceState(ctx context.Context, req *tfprotov6.ImportResourceStateRequest) (*tfprotov6.ImportResourceStateResponse, error) {
+	resp, err := s.ProviderServer.ImportResourceState(s.requestContext(ctx, s.resourceSchema(ctx, req.TypeName)), req)
+	if resp != nil {
+		var secrets []string
+		for _, imported := range resp.ImportedResources {
+			secrets = append(secrets, sensitiveValues(s.resourceSchema(ctx, imported.TypeName), imported.State)...)
+		}
+		redactDiagnostics(resp.Diagnostics, secrets)
+	}
+	return resp, err
+}
+
+func (s *secretsServer) MoveResourceState(ctx context.Context, req *tfprotov6.MoveResourceStateRequest) (*tfprotov6.MoveResourceStateResponse, error) {
+	schema := s.resourceSchema(ctx, req.TargetTypeName)
+	resp, err := s.ProviderServer.MoveResourceState(s.requestContext(ctx, schema), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, resp.TargetState))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ValidateDataResourceConfig(ctx context.Context, req *tfprotov6.ValidateDataResourceConfigRequest) (*tfprotov6.ValidateDataResourceConfigResponse, error) {
+	schema := s.dataSourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ValidateDataResourceConfig(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ReadDataSource(ctx context.Context, req *tfprotov6.ReadDataSourceRequest) (*tfprotov6.ReadDataSourceResponse, error) {
+	schema := s.dataSourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ReadDataSource(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config, resp.State))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) ValidateEphemeralResourceConfig(ctx context.Context, req *tfprotov6.ValidateEphemeralResourceConfigRequest) (*tfprotov6.ValidateEphemeralResourceConfigResponse, error) {
+	schema := s.ephemeralResourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.ValidateEphemeralResourceConfig(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) OpenEphemeralResource(ctx context.Context, req *tfprotov6.OpenEphemeralResourceRequest) (*tfprotov6.OpenEphemeralResourceResponse, error) {
+	schema := s.ephemeralResourceSchema(ctx, req.TypeName)
+	resp, err := s.ProviderServer.OpenEphemeralResource(s.requestContext(ctx, schema, req.Config), req)
+	if resp != nil {
+		redactDiagnostics(resp.Diagnostics, sensitiveValues(schema, req.Config, resp.Result))
+	}
+	return resp, err
+}
+
+func (s *secretsServer) RenewEphemeralResource(ctx context.Context, req *tfprotov6.RenewEphemeralResourceRequest) (*tfprotov6.RenewEphemeralResourceResponse, error) {
+	return s.ProviderServer.RenewEphemeralResource(s.requestContext(ctx, s.ephemeralResourceSchema(ctx, req.TypeName)), req)
+}
+
+func (s *secretsServer) CloseEphemeralResource(ctx context.Context, req *tfprotov6.CloseEphemeralResourceRequest) (*tfprotov6.CloseEphemeralResourceResponse, error) {
+	return s.ProviderServer.CloseEphemeralResource(s.requestContext(ctx, s.ephemeralResourceSchema(ctx, req.TypeName)), req)
+}
+
+func (s *secretsServer) CallFunction(ctx context.Context, req *tfprotov6.CallFunctionRequest) (*tfprotov6.CallFunctionResponse, error) {
+	return s.ProviderServer.CallFunction(s.requestContext(ctx, nil), req)
+}
+
+// requestContext returns the context of a request, with the logger of the provider code at the configured level and
+// the secrets masked in the logs: the fields named like the sensitive attributes of the schema, and the values of these
+// attributes known from the request.
+func (s *secretsServer) requestContext(ctx context.Context, schema *tfprotov6.Schema, values ...*tfprotov6.DynamicValue) context.Context {
+	level := ""
+	if s.logLevel != nil {
+		level = s.logLevel()
+	}
+	return withSecretsMasked(withLogLevel(ctx, level), sensitiveNames(schema), sensitiveValues(schema, values...))
+}
+
+// getSchemas returns the schemas of the provider, read once. Nil when they cannot be read.
+func (s *secretsServer) getSchemas(ctx context.Context) *tfprotov6.GetProviderSchemaResponse {
+	s.schemasOnce.Do(func() {
+		resp, err := s.ProviderServer.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+		if err == nil {
+			s.schemas = resp
+		}
+	})
+	return s.schemas
+}
+
+func (s *secretsServer) providerSchema(ctx context.Context) *tfprotov6.Schema {
+	if schemas := s.getSchemas(ctx); schemas != nil {
+		return schemas.Provider
+	}
+	return nil
+}
+
+func (s *secretsServer) resourceSchema(ctx context.Context, typeName string) *tfprotov6.Schema {
+	if schemas := s.getSchemas(ctx); schemas != nil {
+		return schemas.ResourceSchemas[typeName]
+	}
+	return nil
+}
+
+func (s *secretsServer) dataSourceSchema(ctx context.Context, typeName string) *tfprotov6.Schema {
+	if schemas := s.getSchemas(ctx); schemas != nil {
+		return schemas.DataSourceSchemas[typeName]
+	}
+	return nil
+}
+
+func (s *secretsServer) ephemeralResourceSchema(ctx context.Context, typeName string) *tfprotov6.Schema {
+	if schemas := s.getSchemas(ctx); schemas != nil {
+		return schemas.EphemeralResourceSchemas[typeName]
+	}
+	return nil
+}
+
+// sensitiveNames returns the names of the sensitive and write-only attributes of the schema, nested ones included
+func sensitiveNames(schema *tfprotov6.Schema) []string {
+	if schema == nil || schema.Block == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	addSensitiveNames(names, schema.Block)
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// sensitiveValues returns the known string values of the sensitive and write-only attributes of the values of the schema.
+// The nested attributes are matched by name, whatever object they belong to.
+func sensitiveValues(schema *tfprotov6.Schema, values ...*tfprotov6.DynamicValue) []string {
+	names := sensitiveNames(schema)
+	if len(names) == 0 {
+		return nil
+	}
+	var res []string
+	for _, dv := range values {
+		if dv == nil {
+			continue
+		}
+		value, err := dv.Unmarshal(schema.ValueType())
+		if err != nil {
+			continue
+		}
+		_ = tftypes.Walk(value, func(p *tftypes.AttributePath, v tftypes.Value) (bool, error) {
+			steps := p.Steps()
+			if len(steps) == 0 || !v.Type().Is(tftypes.String) || !v.IsFullyKnown() || v.IsNull() {
+				return true, nil
+			}
+			if name, ok := steps[len(steps)-1].(tftypes.AttributeName); ok && slices.Contains(names, string(name)) {
+				var secret string
+				if err := v.As(&secret); err == nil && secret != "" {
+					res = append(res, secret)
+				}
+			}
+			return true, nil
+		})
+	}
+	return res
+}
+
+func addSensitiveNames(names map[string]bool, block *tfprotov6.SchemaBlock) {
+	for _, a := range block.Attributes {
+		addSensitiveAttributeNames(names, a)
+	}
+	for _, b := range block.BlockTypes {
+		if b.Block != nil {
+			addSensitiveNames(names, b.Block)
+		}
+	}
+}
+
+func addSensitiveAttributeNames(names map[string]bool, a *tfprotov6.SchemaAttribute) {
+	if a.Sensitive || a.WriteOnly {
+		names[a.Name] = true
+	}
+	if a.NestedType != nil {
+		for _, nested := range a.NestedType.Attributes {
+			addSensitiveAttributeNames(names, nested)
+		}
+	}
+}
+
+// redactDiagnostics replaces the secrets in the summaries and the details of the diagnostics
+func redactDiagnostics(diags []*tfprotov6.Diagnostic, secrets []string) {
+	for _, d := range diags {
+		for _, secret := range secrets {
+			d.Summary = strings.ReplaceAll(d.Summary, secret, redactedSecret)
+			d.Detail = strings.ReplaceAll(d.Detail, secret, redactedSecret)
+		}
+	}
+}
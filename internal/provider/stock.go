@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/model"
+)
+
+// stockShortage is an ingredient an order needs more of than what is available
+type stockShortage struct {
+	IngredientID string
+	Needed       int
+	Available    int
+}
+
+func (s stockShortage) String() string {
+	return fmt.Sprintf("the ingredient %s is needed %d times, %d available", s.IngredientID, s.Needed, s.Available)
+}
+
+// orderNeeds returns the quantity of every ingredient the coffees of the items are made with
+func orderNeeds(items []model.OrderItem) map[string]int {
+	res := map[string]int{}
+	for _, item := range items {
+		for _, ingredient := range item.Coffee.Ingredient {
+			res[ingredient.IngredientID] += ingredient.Quantity * item.Quantity
+		}
+	}
+	return res
+}
+
+// planStock replaces the reservations of the order by its needs. The order releases its reservations when
+// it needs nothing. It returns the inventories changed, and the shortages sorted by ingredient ID. The
+// ingredients without an inventory are not tracked: they are never short.
+// Lowering the needs of an order is always possible, even when the stock was lowered below what is reserved.
+func planStock(inventories []*model.Inventory, orderID string, needs map[string]int) ([]*model.Inventory, []stockShortage) {
+	var changed []*model.Inventory
+	var shortages []stockShortage
+	for _, inv := range inventories {
+		reserved := inv.Reservations[orderID]
+		needed := needs[inv.ID]
+		if needed == reserved {
+			continue
+		}
+		if needed > reserved && needed > inv.Available()+reserved {
+			shortages = append(shortages, stockShortage{
+				IngredientID: inv.ID,
+				Needed:       needed,
+				Available:    max(inv.Available()+reserved, 0),
+			})
+			continue
+		}
+		if inv.Reservations == nil {
+			inv.Reservations = map[string]int{}
+		}
+		if needed == 0 {
+			delete(inv.Reservations, orderID)
+		} else {
+			inv.Reservations[orderID] = needed
+		}
+		changed = append(changed, inv)
+	}
+	sort.Slice(shortages, func(i, j int) bool {
+		return shortages[i].IngredientID < shortages[j].IngredientID
+	})
+	return changed, shortages
+}
+
+// reserveStock updates the inventories for the needs of the order in the transaction. Nothing is changed when
+// there is a shortage.
+func reserveStock(tx *client.Tx, orderID string, needs map[string]int) ([]stockShortage, error) {
+	inventories := client.NewClient[*model.Inventory](tx, typeInventory)
+	all, err := inventories.GetAll()
+	if err != nil && !client.IsNotFound(err) {
+		return nil, err
+	}
+	changed, shortages := planStock(all, orderID, needs)
+	if len(shortages) > 0 {
+		return shortages, nil
+	}
+	for _, inv := range changed {
+		if err := inventories.Update(inv); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// insufficientStockDetail describes the shortages preventing an order from being placed
+func insufficientStockDetail(shortages []stockShortage) string {
+	res := "There is not enough stock for the order:"
+	for _, s := range shortages {
+		res += "\n  - " + s.String()
+	}
+	return res + "\nRaise the quantity of the provs_inventory of these ingredients, or order less."
+}
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	ephemeralschema "github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The resources, data sources and ephemeral resources kept in a store have a store attribute selecting one of the
+// stores configured in the provider. The framework creates them for every request and configures them with the
+// default store: once the store attribute is read, they are configured again with the data of the selected store.
+// The references between objects, like the coffees of an order, are resolved in the store of the referencing object.
+
+const storeDescription = "The name of the store, among the stores of the provider, keeping the object. Defaults to the default store of the provider."
+
+func resourceStoreSchema() resourceschema.StringAttribute {
+	return resourceschema.StringAttribute{
+		Optional:    true,
+		Description: storeDescription + " Changing it moves the object to the other store by replacing it.",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+func dataSourceStoreSchema() datasourceschema.StringAttribute {
+	return datasourceschema.StringAttribute{
+		Optional:    true,
+		Description: storeDescription,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+func ephemeralStoreSchema() ephemeralschema.StringAttribute {
+	return ephemeralschema.StringAttribute{
+		Optional:    true,
+		Description: storeDescription,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// storeData returns the provider data of the store with the given name, or of the default store when the name is null.
+// The provider data not built by the provider, like a bare client.BackendClient, is a default store without named stores.
+func storeData(data any, name types.String) (any, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	pd, ok := data.(*providerData)
+	if name.IsNull() {
+		if ok && pd.BackendClient == nil {
+			diags.AddAttributeError(
+				path.Root("store"),
+				"Missing store",
+				"The provider has no default store. Set the store, or configure the path or the default_store of the provider.",
+			)
+			return nil, diags
+		}
+		return data, nil
+	}
+
+	var store *providerData
+	if ok {
+		store = pd.stores[name.ValueString()]
+	}
+	if store == nil {
+		var names []string
+		if ok {
+			for n := range pd.stores {
+				names = append(names, n)
+			}
+			slices.Sort(names)
+		}
+		diags.AddAttributeError(
+			path.Root("store"),
+			"Unknown store",
+			fmt.Sprintf("The provider has no store named %s. The stores of the provider are: %s.", name.ValueString(), strings.Join(names, ", ")),
+		)
+		return nil, diags
+	}
+	return store, nil
+}
+
+// configureResourceStore configures the resource again with the store of the given name.
+func configureResourceStore(ctx context.Context, r resource.ResourceWithConfigure, data any, name types.String) diag.Diagnostics {
+	store, diags := storeData(data, name)
+	if diags.HasError() {
+		return diags
+	}
+	var resp resource.ConfigureResponse
+	r.Configure(ctx, resource.ConfigureRequest{ProviderData: store}, &resp)
+	return append(diags, resp.Diagnostics...)
+}
+
+// configureDataSourceStore configures the data source again with the store of the given name.
+func configureDataSourceStore(ctx context.Context, d datasource.DataSourceWithConfigure, data any, name types.String) diag.Diagnostics {
+	store, diags := storeData(data, name)
+	if diags.HasError() {
+		return diags
+	}
+	var resp datasource.ConfigureResponse
+	d.Configure(ctx, datasource.ConfigureRequest{ProviderData: store}, &resp)
+	return append(diags, resp.Diagnostics...)
+}
+
+// configureEphemeralStore configures the ephemeral resource again with the store of the given name.
+func configureEphemeralStore(ctx context.Context, r ephemeral.EphemeralResourceWithConfigure, data any, name types.String) diag.Diagnostics {
+	store, diags := storeData(data, name)
+	if diags.HasError() {
+		return diags
+	}
+	var resp ephemeral.ConfigureResponse
+	r.Configure(ctx, ephemeral.ConfigureRequest{ProviderData: store}, &resp)
+	return append(diags, resp.Diagnostics...)
+}
+
+// cutImportStore splits the import identifier <store>/<id> of an object kept in a named store. The identifiers not
+// starting with the name of a store of the provider are the ones of objects kept in the default store.
+func cutImportStore(data any, id string) (storeName string, rest string) {
+	pd, ok := data.(*providerData)
+	if before, after, found := strings.Cut(id, "/"); found && ok && pd.stores[before] != nil {
+		return before, after
+	}
+	return "", id
+}
+
+// importResourceStore configures the resource again with the named store of the imported object and sets its store
+// attribute. The objects of the default store, without store name, keep a null store.
+func importResourceStore(ctx context.Context, r resource.ResourceWithConfigure, data any, storeName string, resp *resource.ImportStateResponse) bool {
+	if storeName == "" {
+		return true
+	}
+	name := types.StringValue(storeName)
+	resp.Diagnostics.Append(configureResourceStore(ctx, r, data, name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("store"), name)...)
+	return !resp.Diagnostics.HasError()
+}
+
+// importStatePassthroughStoreID imports the object of the identifier <store>/<id>, or <id> for the default store,
+// saving the id to the attribute at attrPath.
+func importStatePassthroughStoreID(ctx context.Context, r resource.ResourceWithConfigure, data any, attrPath path.Path, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	storeName, id := cutImportStore(data, req.ID)
+	if id == "" {
+		resp.Diagnostics.AddError(
+			"Missing Resource Import Identifier",
+			fmt.Sprintf("The import identifier must have the format <id> or <store>/<id>, got %q.", req.ID),
+		)
+		return
+	}
+	if !importResourceStore(ctx, r, data, storeName, resp) {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, attrPath, id)...)
+}
@@ -0,0 +1,372 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"terraform-provider-provs/internal/audit"
+	"terraform-provider-provs/internal/client"
+	"terraform-provider-provs/internal/client/filesystem"
+	"terraform-provider-provs/internal/client/remote"
+	"terraform-provider-provs/internal/model"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// testStoreToken is the token of the http stores of the tests
+const testStoreToken = "t0ken"
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+
+	// the default store is empty, the local store has a coffee and the shared store, encrypted, a tax rule
+	localPath := t.TempDir()
+	local, err := filesystem.NewFsClient(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](local, typeCoffees).Create(&model.Coffee{ID: "espresso", Name: "Espresso"}); err != nil {
+		t.Fatal(err)
+	}
+	sharedPath := t.TempDir()
+	sharedFs, err := filesystem.NewFsClient(sharedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeServer := httptest.NewServer(remote.NewHandler(sharedFs, testStoreToken))
+	defer storeServer.Close()
+	key := bytes.Repeat([]byte{7}, client.EncryptionKeySize)
+	shared, err := remote.NewHttpClient(storeServer.URL, testStoreToken, remote.DefaultTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared, err = client.NewEncryptedClient(shared, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.TaxRule](shared, typeTaxRule).Create(&model.TaxRule{ID: "FR", Rate: 0.2}); err != nil {
+		t.Fatal(err)
+	}
+
+	server, schemas, resp := testConfigureProvider(ctx, t, time.Now, map[string]tftypes.Value{
+		"path": tftypes.NewValue(tftypes.String, t.TempDir()),
+		"stores": testStoresValue(t, map[string]map[string]string{
+			"local": {"type": "fs", "path": localPath},
+			"shared": {
+				"type":           "http",
+				"url":            storeServer.URL,
+				"token":          testStoreToken,
+				"encryption_key": base64.StdEncoding.EncodeToString(key),
+			},
+		}),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+
+	// the references are resolved in the store of the order
+	testNoDiagnostics(t, testPlanOrder(ctx, t, server, schemas, "espresso", 1, map[string]string{"store": "local"}).Diagnostics)
+	if got := testDiagnosticSummaries(testPlanOrder(ctx, t, server, schemas, "espresso", 1, nil).Diagnostics); got != "Unknown coffee" {
+		t.Fatalf("expected the coffee unknown in the default store, got %s", got)
+	}
+	if got := testDiagnosticSummaries(testPlanOrder(ctx, t, server, schemas, "espresso", 1, map[string]string{"store": "missing"}).Diagnostics); got != "Unknown store" {
+		t.Fatalf("expected the store unknown, got %s", got)
+	}
+
+	rules := testReadTaxRules(ctx, t, server, schemas, "shared")
+	if len(rules) != 1 || rules["FR"].IsNull() {
+		t.Fatalf("expected the tax rule of the shared store, got %v", rules)
+	}
+	if rules := testReadTaxRules(ctx, t, server, schemas, ""); len(rules) != 0 {
+		t.Fatalf("expected no tax rule in the default store, got %v", rules)
+	}
+
+	// the shared store only holds encrypted objects
+	r, err := sharedFs.Read(typeTaxRule, "FR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("FR")) {
+		t.Fatalf("expected the tax rule encrypted, got %s", stored)
+	}
+}
+
+func TestAccStores_moveSecret(t *testing.T) {
+	localPath := t.TempDir()
+	sharedPath := t.TempDir()
+	sharedFs, err := filesystem.NewFsClient(sharedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeServer := httptest.NewServer(remote.NewHandler(sharedFs, testStoreToken))
+	defer storeServer.Close()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, client.EncryptionKeySize))
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStoresSecretConfig(localPath, storeServer.URL, key, "local"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "store", "local"),
+					testAccCheckSecretValue(localPath, "db_password", "s3cr3t"),
+				),
+			},
+			{
+				Config: testAccStoresSecretConfig(localPath, storeServer.URL, key, "shared"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("provs_secret_manager.test", plancheck.ResourceActionReplace),
+						plancheck.ExpectResourceAction("provs_secret.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("provs_secret.test", "store", "shared"),
+					func(_ *terraform.State) error {
+						mgrs, err := testAccSecretManagers(localPath)
+						if err != nil {
+							return err
+						}
+						if all, err := mgrs.GetAll(); len(all) != 0 {
+							return fmt.Errorf("expected the local store emptied, got %d secret managers (%v)", len(all), err)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccStoresSecretConfig(localPath, storeServer.URL, key, "shared") + `
+ephemeral "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager.test.id
+  secret_name       = provs_secret.test.secret_name
+  store             = "shared"
+}
+`,
+			},
+		},
+	})
+}
+
+func testAccStoresSecretConfig(localPath string, sharedURL string, key string, store string) string {
+	return fmt.Sprintf(`
+provider "provs" {
+  stores = {
+    local = {
+      path = %q
+    }
+    shared = {
+      type           = "http"
+      url            = %q
+      token          = %q
+      encryption_key = %q
+    }
+  }
+}
+
+resource "provs_secret_manager" "test" {
+  name  = "test"
+  store = %[5]q
+}
+
+resource "provs_secret" "test" {
+  secret_manager_id = provs_secret_manager.test.id
+  secret_name       = "db_password"
+  secret            = "s3cr3t"
+  store             = %[5]q
+}
+`, localPath, sharedURL, testStoreToken, key, store)
+}
+
+func TestStores_import(t *testing.T) {
+	ctx := context.Background()
+
+	// without default store, the objects are imported from the named store of their identifier
+	localPath := t.TempDir()
+	local, err := filesystem.NewFsClient(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient[*model.Coffee](local, typeCoffees).Create(&model.Coffee{ID: "espresso", Name: "Espresso"}); err != nil {
+		t.Fatal(err)
+	}
+	mgr := &model.SecretManager{Name: "test"}
+	mgr.PutSecret("db_password", "s3cr3t", false, time.Now())
+	if mgr, err = client.NewClient[*model.SecretManager](local, typeSecretManager).Create(mgr); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envPath, "")
+	server, schemas, resp := testConfigureProvider(ctx, t, time.Now, map[string]tftypes.Value{
+		"stores": testStoresValue(t, map[string]map[string]string{"local": {"path": localPath}}),
+	})
+	testNoDiagnostics(t, resp.Diagnostics)
+
+	for typeName, id := range map[string]string{
+		"provs_coffee":         "local/espresso",
+		"provs_secret_manager": "local/name:test",
+		"provs_secret":         "local/" + mgr.ID + "/db_password",
+	} {
+		importResp := testImportState(ctx, t, server, typeName, id)
+		testNoDiagnostics(t, importResp.Diagnostics)
+		state, err := importResp.ImportedResources[0].State.Unmarshal(schemas.ResourceSchemas[typeName].ValueType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var attrs map[string]tftypes.Value
+		if err := state.As(&attrs); err != nil {
+			t.Fatal(err)
+		}
+		if got := testStringAttr(t, attrs, "store"); got != "local" {
+			t.Fatalf("%s: expected the local store, got %q", typeName, got)
+		}
+	}
+	entries, err := audit.Recent(local, audit.Filter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Operation != model.AuditOperationImport {
+		t.Fatalf("expected the import recorded in the local store, got %v", entries)
+	}
+
+	// the secrets of the default store cannot be imported without their audit log
+	importResp := testImportState(ctx, t, server, "provs_secret", mgr.ID+"/db_password")
+	if got := testDiagnosticSummaries(importResp.Diagnostics); got != "Error recording the access to secret" {
+		t.Fatalf("expected the import refused without audit log, got %s", got)
+	}
+}
+
+// testImportState imports the resource with the given identifier
+func testImportState(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, typeName string, id string) *tfprotov6.ImportResourceStateResponse {
+	t.Helper()
+	resp, err := server.ImportResourceState(ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestStores_invalid(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		attrs map[string]tftypes.Value
+		want  string
+	}{
+		"no store": {
+			attrs: map[string]tftypes.Value{},
+			want:  "Missing storage path",
+		},
+		"unknown default store": {
+			attrs: map[string]tftypes.Value{
+				"default_store": tftypes.NewValue(tftypes.String, "missing"),
+				"stores":        testStoresValue(t, map[string]map[string]string{"local": {"path": t.TempDir()}}),
+			},
+			want: "Unknown default store",
+		},
+		"http store without url": {
+			attrs: map[string]tftypes.Value{
+				"stores": testStoresValue(t, map[string]map[string]string{"shared": {"type": "http", "path": t.TempDir()}}),
+			},
+			want: "Invalid store configuration",
+		},
+		"http store without token": {
+			attrs: map[string]tftypes.Value{
+				"stores": testStoresValue(t, map[string]map[string]string{"shared": {"type": "http", "url": "http://localhost:8080"}}),
+			},
+			want: "Invalid store configuration",
+		},
+		"short encryption key": {
+			attrs: map[string]tftypes.Value{
+				"stores": testStoresValue(t, map[string]map[string]string{"local": {
+					"path":           t.TempDir(),
+					"encryption_key": base64.StdEncoding.EncodeToString([]byte("short")),
+				}}),
+			},
+			want: "Invalid encryption key",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testResetProviderEnv(t)
+			_, _, resp := testConfigureProvider(ctx, t, time.Now, tc.attrs)
+			if got := testDiagnosticSummaries(resp.Diagnostics); got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// testStoresValue returns the value of the stores attribute of the provider, the attributes not given being null
+func testStoresValue(t *testing.T, stores map[string]map[string]string) tftypes.Value {
+	t.Helper()
+	storeType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"type":                tftypes.String,
+		"path":                tftypes.String,
+		"url":                 tftypes.String,
+		"token":               tftypes.String,
+		"encryption_key":      tftypes.String,
+		"encryption_key_file": tftypes.String,
+	}}
+	vals := map[string]tftypes.Value{}
+	for name, attrs := range stores {
+		store := map[string]tftypes.Value{}
+		for attr := range storeType.AttributeTypes {
+			store[attr] = tftypes.NewValue(tftypes.String, nil)
+			if v, ok := attrs[attr]; ok {
+				store[attr] = tftypes.NewValue(tftypes.String, v)
+			}
+		}
+		vals[name] = tftypes.NewValue(storeType, store)
+	}
+	return tftypes.NewValue(tftypes.Map{ElementType: storeType}, vals)
+}
+
+// testReadTaxRules reads the provs_tax_rules data source of the given store, the default one when empty
+func testReadTaxRules(ctx context.Context, t *testing.T, server tfprotov6.ProviderServer, schemas *tfprotov6.GetProviderSchemaResponse, store string) map[string]tftypes.Value {
+	t.Helper()
+	schema := schemas.DataSourceSchemas["provs_tax_rules"]
+	attrs := map[string]tftypes.Value{}
+	if store != "" {
+		attrs["store"] = tftypes.NewValue(tftypes.String, store)
+	}
+	resp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: "provs_tax_rules",
+		Config:   testDynamicValue(t, schema, attrs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testNoDiagnostics(t, resp.Diagnostics)
+	state, err := resp.State.Unmarshal(schema.ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]tftypes.Value
+	if err := state.As(&res); err != nil {
+		t.Fatal(err)
+	}
+	var rules map[string]tftypes.Value
+	if err := res["rules"].As(&rules); err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func testDiagnosticSummaries(diags []*tfprotov6.Diagnostic) string {
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	return strings.Join(summaries, ", ")
+}
@@ -2,15 +2,35 @@ package provider
 
 const (
 	// data sources
-	typeCoffees = "coffees"
+	typeAuditLog       = "audit_log"
+	typeCoffees        = "coffees"
+	typeCustomerOrders = "customer_orders"
+	typeSecretManagers = "secret_managers"
+	typeStock          = "stock"
+	typeTaxRules       = "tax_rules"
 
 	// ephemerals
-	typeRandom = "random"
-	typeSecret = "secret"
+	typeKeypair  = "keypair"
+	typePassword = "password"
+	typeRandom   = "random"
+	typeSecret   = "secret"
+	typeToken    = "token"
 
 	// resources
-	typeOrder = "order"
+	typeCustomer            = "customer"
+	typeIngredient          = "ingredient"
+	typeInventory           = "inventory"
+	typeOrder               = "order"
+	typePromotion           = "promotion"
+	typeSecretManagerPolicy = "secret_manager_policy"
+	typeTaxRule             = "tax_rule"
 
-	// resources + ephemerals
+	// storage only
+	typeSecretLease = "secret_lease"
+
+	// resources + data sources
+	typeCoffee = "coffee"
+
+	// resources + ephemerals + data sources
 	typeSecretManager = "secret_manager"
 )
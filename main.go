@@ -1,12 +1,13 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"log"
+	"os"
 
-	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 
+	"terraform-provider-provs/internal/cli"
 	"terraform-provider-provs/internal/provider"
 )
 
@@ -20,17 +21,23 @@ var (
 )
 
 func main() {
+	// Terraform starts the provider without any arguments, so anything else is a command meant for the users
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := providerserver.ServeOpts{
-		Address: "registry.hashicorp.io/edu/provs",
-		Debug:   debug,
+	var opts []tf6server.ServeOpt
+	if debug {
+		opts = append(opts, tf6server.WithManagedDebug())
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// the server of the provider masks the secrets of every request, that providerserver.Serve would not
+	err := tf6server.Serve("registry.hashicorp.io/edu/provs", provider.NewProtocol6Server(version), opts...)
 
 	if err != nil {
 		log.Fatal(err.Error())